@@ -0,0 +1,197 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	chttp "github.com/dolanor/caldav-go/http"
+	"github.com/dolanor/caldav-go/icalendar"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	"github.com/dolanor/caldav-go/utils"
+)
+
+// the outcome of an action on a managed attachment, see RFC 8607
+type ManagedAttachment struct {
+	// the identifier the server gave the attachment, empty once removed
+	ManagedId string
+	// the new entity tag of the calendar object resource, if the server returned one
+	ETag string
+	// the updated calendar object resource, if the server returned it
+	Calendar *components.Calendar
+}
+
+// the content of a document to attach to a calendar object resource
+type AttachmentContent struct {
+	// the media type of the document, such as application/pdf
+	ContentType string
+	// the name of the document, suggested to the server
+	Filename string
+	// the document itself
+	Data []byte
+}
+
+// uploads a document and attaches it to a calendar object resource, see RFC 8607 section 3.4.1.
+// the attachment is added to the master component and all overrides, unless some recurrence ids are given,
+// in which case it only applies to the matching instances
+func (c *Client) AddAttachment(path string, content *AttachmentContent, recurrenceIds ...*values.DateTime) (*ManagedAttachment, error) {
+	return c.AddAttachmentContext(context.Background(), path, content, recurrenceIds...)
+}
+
+// same as AddAttachment, using a context to cancel the request
+func (c *Client) AddAttachmentContext(ctx context.Context, path string, content *AttachmentContent, recurrenceIds ...*values.DateTime) (*ManagedAttachment, error) {
+
+	query := url.Values{"action": {"attachment-add"}}
+	if rid, err := encodeRecurrenceIds(recurrenceIds); err != nil {
+		return nil, utils.NewError(c.AddAttachmentContext, "unable to encode recurrence ids", c, err)
+	} else if rid != "" {
+		query.Set("rid", rid)
+	}
+
+	if content == nil {
+		return nil, utils.NewError(c.AddAttachmentContext, "no attachment content provided", c, nil)
+	} else if a, err := c.attachmentAction(ctx, path, query, content, http.StatusCreated); err != nil {
+		return nil, utils.NewError(c.AddAttachmentContext, "unable to add attachment", c, err)
+	} else if a.ManagedId == "" {
+		return nil, utils.NewError(c.AddAttachmentContext, "server did not return a managed id", c, nil)
+	} else {
+		return a, nil
+	}
+
+}
+
+// replaces the document of a managed attachment, see RFC 8607 section 3.4.2.
+// the server gives the updated attachment a new managed id
+func (c *Client) UpdateAttachment(path string, managedId string, content *AttachmentContent) (*ManagedAttachment, error) {
+	return c.UpdateAttachmentContext(context.Background(), path, managedId, content)
+}
+
+// same as UpdateAttachment, using a context to cancel the request
+func (c *Client) UpdateAttachmentContext(ctx context.Context, path string, managedId string, content *AttachmentContent) (*ManagedAttachment, error) {
+
+	query := url.Values{"action": {"attachment-update"}, "managed-id": {managedId}}
+
+	if managedId == "" {
+		return nil, utils.NewError(c.UpdateAttachmentContext, "no managed id provided", c, nil)
+	} else if content == nil {
+		return nil, utils.NewError(c.UpdateAttachmentContext, "no attachment content provided", c, nil)
+	} else if a, err := c.attachmentAction(ctx, path, query, content, http.StatusOK, http.StatusNoContent); err != nil {
+		return nil, utils.NewError(c.UpdateAttachmentContext, "unable to update attachment", c, err)
+	} else if a.ManagedId == "" {
+		return nil, utils.NewError(c.UpdateAttachmentContext, "server did not return a managed id", c, nil)
+	} else {
+		return a, nil
+	}
+
+}
+
+// removes a managed attachment from a calendar object resource, see RFC 8607 section 3.4.3.
+// the attachment is removed from all the components, unless some recurrence ids are given
+func (c *Client) RemoveAttachment(path string, managedId string, recurrenceIds ...*values.DateTime) (*ManagedAttachment, error) {
+	return c.RemoveAttachmentContext(context.Background(), path, managedId, recurrenceIds...)
+}
+
+// same as RemoveAttachment, using a context to cancel the request
+func (c *Client) RemoveAttachmentContext(ctx context.Context, path string, managedId string, recurrenceIds ...*values.DateTime) (*ManagedAttachment, error) {
+
+	query := url.Values{"action": {"attachment-remove"}, "managed-id": {managedId}}
+	if rid, err := encodeRecurrenceIds(recurrenceIds); err != nil {
+		return nil, utils.NewError(c.RemoveAttachmentContext, "unable to encode recurrence ids", c, err)
+	} else if rid != "" {
+		query.Set("rid", rid)
+	}
+
+	if managedId == "" {
+		return nil, utils.NewError(c.RemoveAttachmentContext, "no managed id provided", c, nil)
+	} else if a, err := c.attachmentAction(ctx, path, query, nil, http.StatusOK, http.StatusNoContent); err != nil {
+		return nil, utils.NewError(c.RemoveAttachmentContext, "unable to remove attachment", c, err)
+	} else {
+		return a, nil
+	}
+
+}
+
+// posts a managed attachment action to a calendar object resource, asking the server to return the updated resource
+func (c *Client) attachmentAction(ctx context.Context, path string, query url.Values, content *AttachmentContent, expected ...int) (*ManagedAttachment, error) {
+
+	urlstr := c.Server().WebDAV().Http().AbsUrlStr(path)
+	if strings.Contains(urlstr, "?") {
+		urlstr += "&" + query.Encode()
+	} else {
+		urlstr += "?" + query.Encode()
+	}
+
+	var body []byte
+	if content != nil {
+		body = content.Data
+	}
+
+	r, err := chttp.NewRequestWithContext(ctx, "POST", urlstr, ioutil.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		return nil, utils.NewError(c.attachmentAction, "unable to create request", c, err)
+	}
+
+	header := r.Native().Header
+	header.Set("Prefer", "return=representation")
+	if content != nil {
+		if content.ContentType != "" {
+			header.Set("Content-Type", content.ContentType)
+		} else {
+			header.Set("Content-Type", "application/octet-stream")
+		}
+		if content.Filename != "" {
+			header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": content.Filename}))
+		}
+	}
+
+	resp, err := c.Do((*Request)(r))
+	if err != nil {
+		return nil, utils.NewError(c.attachmentAction, "unable to execute request", c, err)
+	}
+
+	var ok bool
+	for _, code := range expected {
+		ok = ok || resp.StatusCode == code
+	}
+	if !ok {
+		err := resp.WebDAV().DecodeError()
+		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
+		return nil, utils.NewError(c.attachmentAction, msg, c, err)
+	}
+
+	a := &ManagedAttachment{ManagedId: resp.Header.Get("Cal-Managed-ID"), ETag: resp.Header.Get("ETag")}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/calendar" || resp.Body == nil {
+		return a, nil
+	} else if data, err := ioutil.ReadAll(resp.Body); err != nil {
+		return nil, utils.NewError(c.attachmentAction, "unable to read response body", c, err)
+	} else if strings.TrimSpace(string(data)) != "" {
+		a.Calendar = new(components.Calendar)
+		if err := icalendar.Unmarshal(string(data), a.Calendar); err != nil {
+			return nil, utils.NewError(c.attachmentAction, "unable to decode response", c, err)
+		}
+	}
+
+	return a, nil
+
+}
+
+// encodes the recurrence ids of the instances targeted by an action, as the rid query parameter expects them
+func encodeRecurrenceIds(recurrenceIds []*values.DateTime) (string, error) {
+	var rids []string
+	for _, rid := range recurrenceIds {
+		if rid == nil {
+			continue
+		} else if encoded, err := rid.EncodeICalValue(); err != nil {
+			return "", err
+		} else {
+			rids = append(rids, encoded)
+		}
+	}
+	return strings.Join(rids, ","), nil
+}
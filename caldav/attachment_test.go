@@ -0,0 +1,99 @@
+package caldav
+
+import (
+	"fmt"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	. "gopkg.in/check.v1"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type AttachmentSuite struct{}
+
+var _ = Suite(new(AttachmentSuite))
+
+func TestAttachment(t *testing.T) { TestingT(t) }
+
+// the updated resource of the example of RFC 8607 section 3.4.1
+const attachedEvent = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example Corp.//CalDAV Server//EN
+BEGIN:VEVENT
+UID:20010712T182145Z-123401@example.com
+DTSTAMP:20120201T203412Z
+DTSTART:20120701T000000Z
+DTEND:20120702T000000Z
+SUMMARY:One-off meeting
+ATTACH;MANAGED-ID=97S;FMTTYPE=text/plain;SIZE=59;FILENAME=agenda.txt:https://cal.example.com/attach/64fdde3a8
+END:VEVENT
+END:VCALENDAR
+`
+
+func (s *AttachmentSuite) TestManagedAttachments(c *C) {
+
+	var actions, disposition, contentType, data string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method != "POST" || r.URL.Path != "/calendars/cyrus/work/meeting.ics" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		q := r.URL.Query()
+		actions += fmt.Sprintf("%s %s %s;", q.Get("action"), q.Get("managed-id"), q.Get("rid"))
+		switch q.Get("action") {
+		case "attachment-add":
+			disposition, contentType, data = r.Header.Get("Content-Disposition"), r.Header.Get("Content-Type"), string(body)
+			w.Header().Set("Cal-Managed-ID", "97S")
+			w.Header().Set("ETag", `"123456789-000-111"`)
+			w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, attachedEvent)
+		case "attachment-update":
+			w.Header().Set("Cal-Managed-ID", "98S")
+			w.Header().Set("ETag", `"123456789-000-222"`)
+			w.WriteHeader(http.StatusNoContent)
+		case "attachment-remove":
+			if q.Get("managed-id") != "98S" {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+	path := "/calendars/cyrus/work/meeting.ics"
+
+	content := &AttachmentContent{ContentType: "text/plain", Filename: "agenda.txt", Data: []byte("Agenda\n1. Budget\n")}
+	added, err := client.AddAttachment(path, content)
+	c.Assert(err, IsNil)
+	c.Assert(added.ManagedId, Equals, "97S")
+	c.Assert(added.ETag, Equals, `"123456789-000-111"`)
+	c.Assert(contentType, Equals, "text/plain")
+	c.Assert(disposition, Equals, "attachment; filename=agenda.txt")
+	c.Assert(data, Equals, "Agenda\n1. Budget\n")
+	c.Assert(added.Calendar.Events, HasLen, 1)
+	c.Assert(added.Calendar.Events[0].Attachments, HasLen, 1)
+	c.Assert(added.Calendar.Events[0].Attachments[0].ManagedId, Equals, "97S")
+	c.Assert(added.Calendar.Events[0].Attachments[0].Size, Equals, int64(59))
+
+	updated, err := client.UpdateAttachment(path, added.ManagedId, content)
+	c.Assert(err, IsNil)
+	c.Assert(updated.ManagedId, Equals, "98S")
+	c.Assert(updated.Calendar, IsNil)
+
+	rid := values.NewDateTime(time.Date(2012, 7, 8, 0, 0, 0, 0, time.UTC))
+	_, err = client.RemoveAttachment(path, updated.ManagedId, rid)
+	c.Assert(err, IsNil)
+	_, err = client.RemoveAttachment(path, added.ManagedId)
+	c.Assert(err, NotNil)
+
+	c.Assert(actions, Equals, "attachment-add  ;attachment-update 97S ;attachment-remove 98S 20120708T000000Z;attachment-remove 97S ;")
+
+}
@@ -0,0 +1,154 @@
+package caldav
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dolanor/caldav-go/icalendar"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/utils"
+	"github.com/dolanor/caldav-go/webdav/entities"
+)
+
+// sentinel errors returned by backends, along with ErrNotFound, ErrConflict and ErrPreconditionFailed
+var (
+	// a collection cannot be created where a resource already exists
+	ErrExists = errors.New("resource already exists")
+	// the synchronization token was not issued by the backend, or is too old to be remembered
+	ErrInvalidSyncToken = errors.New("invalid sync token")
+)
+
+// the storage of the calendar collections and calendar object resources served by a Handler.
+// collection paths end with a slash, and objects live directly in a collection.
+// backends report missing resources with ErrNotFound, and writes to a missing collection with ErrConflict
+type Backend interface {
+	// returns the calendar collection at a path
+	Collection(ctx context.Context, path string) (*Collection, error)
+	// lists the calendar collections found directly under a path, such as a calendar home
+	ListCollections(ctx context.Context, parent string) ([]*Collection, error)
+	// creates a calendar collection with initial properties, or fails with ErrExists
+	CreateCollection(ctx context.Context, path string, props []*entities.Property) (*Collection, error)
+	// sets and removes properties of a calendar collection
+	UpdateCollection(ctx context.Context, path string, set []*entities.Property, remove []xml.Name) (*Collection, error)
+	// deletes a calendar collection along with its objects
+	DeleteCollection(ctx context.Context, path string) error
+	// returns the calendar object resource at a path
+	Object(ctx context.Context, path string) (*Object, error)
+	// lists the calendar object resources of a collection
+	ListObjects(ctx context.Context, collection string) ([]*Object, error)
+	// creates or replaces a calendar object resource, provided the conditions hold against the current one.
+	// fails with a *UIDConflictError if another object of the collection holds the same UID
+	PutObject(ctx context.Context, path string, data []byte, conditions *Conditions) (*Object, error)
+	// deletes a calendar object resource, provided the conditions hold against it
+	DeleteObject(ctx context.Context, path string, conditions *Conditions) error
+	// lists the objects of a collection changed since a synchronization token, along with the current token.
+	// an empty token lists all the objects of the collection, a token the backend does not know fails
+	// with ErrInvalidSyncToken
+	Changes(ctx context.Context, collection string, token string) ([]*Change, string, error)
+}
+
+// a calendar object resource cannot be stored, as another object of its collection holds the same UID
+type UIDConflictError struct {
+	// the path of the object holding the UID
+	Path string
+}
+
+func (e *UIDConflictError) Error() string {
+	return "UID already used by " + e.Path
+}
+
+// a calendar collection, as stored by a backend
+type Collection struct {
+	// the path of the collection, ending with a slash
+	Path string
+	// the properties set on the collection, such as DAV:displayname or CALDAV:supported-calendar-component-set
+	Properties []*entities.Property
+	// the synchronization token of the collection, which changes along with any of its objects
+	SyncToken string
+}
+
+// returns a property of the collection by name, or nil if it is not set
+func (c *Collection) Property(name xml.Name) *entities.Property {
+	return entities.FindProperty(c.Properties, name)
+}
+
+// a calendar object resource, as stored by a backend
+type Object struct {
+	// the path of the object, within its collection
+	Path string
+	// the quoted entity tag of the object, which changes along with its data
+	ETag string
+	// the time the object was last written
+	ModTime time.Time
+	// the iCalendar data of the object
+	Data []byte
+}
+
+// decodes the iCalendar data of the object
+func (o *Object) Calendar() (*components.Calendar, error) {
+	cal := new(components.Calendar)
+	if err := icalendar.Unmarshal(string(o.Data), cal); err != nil {
+		return nil, utils.NewError(o.Calendar, "unable to decode calendar data", o, err)
+	}
+	return cal, nil
+}
+
+// a change of a calendar object resource since a synchronization token
+type Change struct {
+	// the path of the object
+	Path string
+	// whether the object was deleted, rather than created or modified
+	Deleted bool
+}
+
+// the conditions a write is subject to, taken from the If-Match and If-None-Match headers
+type Conditions struct {
+	IfMatch     string
+	IfNoneMatch string
+}
+
+// checks the conditions against the current object, nil if there is none.
+// returns ErrPreconditionFailed if they do not hold
+func (c *Conditions) Check(current *Object) error {
+	if c == nil {
+		return nil
+	} else if c.IfMatch != "" && (current == nil || !matchesETag(c.IfMatch, current.ETag, false)) {
+		return ErrPreconditionFailed
+	} else if c.IfNoneMatch != "" && current != nil && matchesETag(c.IfNoneMatch, current.ETag, true) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// checks if an entity tag is listed in an If-Match or If-None-Match header, see RFC 7232 section 2.3.2.
+// If-Match uses the strong comparison, in which weak tags never match, and If-None-Match the weak one
+func matchesETag(header string, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		} else if weak && strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		} else if !weak && tag == etag && !strings.HasPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the UID of the components of calendar data, empty if they have none or several
+func objectUID(data []byte) string {
+	if _, uids := scanCalendarObject(string(data)); len(uids) == 1 {
+		return uids[0]
+	}
+	return ""
+}
+
+// returns the path of the collection holding a resource, with a trailing slash
+func parentPath(path string) string {
+	trimmed := strings.TrimSuffix(path, "/")
+	return trimmed[:strings.LastIndex(trimmed, "/")+1]
+}
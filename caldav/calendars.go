@@ -0,0 +1,309 @@
+package caldav
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	cent "github.com/dolanor/caldav-go/caldav/entities"
+	"github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/icalendar"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/utils"
+	"github.com/dolanor/caldav-go/webdav"
+)
+
+// metadata about a calendar collection
+type CalendarInfo struct {
+	// the path of the calendar collection on the server
+	Href string
+	// the human readable name of the calendar
+	DisplayName string
+	// a human readable description of the calendar
+	Description string
+	// the color used by clients to display the calendar, nil if unset
+	Color *color.RGBA
+	// the identifier of the default time zone of the calendar
+	TimeZoneId string
+	// the default time zone of the calendar, nil if unset or unknown to the system
+	TimeZone *time.Location
+	// the component types that may be stored in the calendar, any type is allowed if empty
+	SupportedComponents []values.ComponentName
+	// the maximum size in octets of a single calendar object, no limit if zero
+	MaxResourceSize int64
+	// an opaque token that changes whenever the calendar contents change
+	CTag string
+	// the synchronization token of the collection, see RFC 6578
+	SyncToken string
+}
+
+// checks if the calendar accepts a particular component type
+func (i *CalendarInfo) Supports(name values.ComponentName) bool {
+	if len(i.SupportedComponents) == 0 {
+		return true
+	}
+	for _, supported := range i.SupportedComponents {
+		if supported == name {
+			return true
+		}
+	}
+	return false
+}
+
+// the initial properties of a new calendar collection
+type CalendarOptions struct {
+	// the human readable name of the calendar
+	DisplayName string
+	// a human readable description of the calendar
+	Description string
+	// the color used by clients to display the calendar
+	Color *color.RGBA
+	// the default time zone of the calendar
+	TimeZone *time.Location
+	// the component types that may be stored in the calendar, defaults to any type
+	SupportedComponents []values.ComponentName
+}
+
+// encodes the options as CalDAV properties
+func (o *CalendarOptions) prop() (*cent.Prop, error) {
+
+	prop := new(cent.Prop)
+	prop.DisplayName = o.DisplayName
+	prop.CalendarDescription = o.Description
+
+	if o.Color != nil {
+		prop.CalendarColor = fmt.Sprintf("#%02X%02X%02X%02X", o.Color.R, o.Color.G, o.Color.B, o.Color.A)
+	}
+
+	if o.TimeZone != nil {
+		cal := components.NewCalendar()
+		cal.TimeZones = append(cal.TimeZones, components.NewDynamicTimeZone(o.TimeZone))
+		if encoded, err := icalendar.Marshal(cal); err != nil {
+			return nil, utils.NewError(o.prop, "unable to encode calendar timezone", o, err)
+		} else {
+			prop.CalendarTimeZone = encoded
+		}
+	}
+
+	if len(o.SupportedComponents) > 0 {
+		prop.SupportedCalendarComponentSet = new(cent.SupportedCalendarComponentSet)
+		for _, name := range o.SupportedComponents {
+			comp := &cent.Component{Name: name}
+			prop.SupportedCalendarComponentSet.Components = append(prop.SupportedCalendarComponentSet.Components, comp)
+		}
+	}
+
+	return prop, nil
+
+}
+
+// updates the properties of an existing calendar collection, leaving out empty options.
+// note that servers do not allow the supported components of a calendar to change after its creation.
+// returns a *webdav.ProppatchError if the server rejected any of the properties
+func (c *Client) UpdateCalendar(path string, options *CalendarOptions) error {
+	return c.UpdateCalendarContext(context.Background(), path, options)
+}
+
+// same as UpdateCalendar, using a context to cancel the request
+func (c *Client) UpdateCalendarContext(ctx context.Context, path string, options *CalendarOptions) error {
+	if options == nil {
+		return utils.NewError(c.UpdateCalendarContext, "no calendar options provided", c, nil)
+	} else if prop, err := options.prop(); err != nil {
+		return utils.NewError(c.UpdateCalendarContext, "unable to encode calendar options", c, err)
+	} else {
+		return c.proppatch(ctx, path, prop, nil)
+	}
+}
+
+// changes the display name of a calendar collection
+func (c *Client) RenameCalendar(path string, name string) error {
+	return c.RenameCalendarContext(context.Background(), path, name)
+}
+
+// same as RenameCalendar, using a context to cancel the request
+func (c *Client) RenameCalendarContext(ctx context.Context, path string, name string) error {
+	return c.UpdateCalendarContext(ctx, path, &CalendarOptions{DisplayName: name})
+}
+
+// changes the display color of a calendar collection, removes it if nil
+func (c *Client) SetCalendarColor(path string, rgba *color.RGBA) error {
+	return c.SetCalendarColorContext(context.Background(), path, rgba)
+}
+
+// same as SetCalendarColor, using a context to cancel the request
+func (c *Client) SetCalendarColorContext(ctx context.Context, path string, rgba *color.RGBA) error {
+	if rgba == nil {
+		return c.proppatch(ctx, path, nil, []xml.Name{{Space: "http://apple.com/ns/ical/", Local: "calendar-color"}})
+	} else {
+		return c.UpdateCalendarContext(ctx, path, &CalendarOptions{Color: rgba})
+	}
+}
+
+// changes the description of a calendar collection, removes it if empty
+func (c *Client) SetCalendarDescription(path string, description string) error {
+	return c.SetCalendarDescriptionContext(context.Background(), path, description)
+}
+
+// same as SetCalendarDescription, using a context to cancel the request
+func (c *Client) SetCalendarDescriptionContext(ctx context.Context, path string, description string) error {
+	if description == "" {
+		return c.proppatch(ctx, path, nil, []xml.Name{{Space: "urn:ietf:params:xml:ns:caldav", Local: "calendar-description"}})
+	} else {
+		return c.UpdateCalendarContext(ctx, path, &CalendarOptions{Description: description})
+	}
+}
+
+func (c *Client) proppatch(ctx context.Context, path string, set *cent.Prop, remove []xml.Name) error {
+	var prop interface{}
+	if set != nil {
+		prop = set
+	}
+	if _, err := c.WebDAV().ProppatchContext(ctx, path, prop, remove); err != nil {
+		if perr, ok := err.(*webdav.ProppatchError); ok {
+			return perr // keep the property results available to the caller
+		}
+		return utils.NewError(c.proppatch, "unable to update properties", c, err)
+	}
+	return nil
+}
+
+// the properties requested for each collection when listing calendars
+var calendarInfoProps = []xml.Name{
+	{Space: "DAV:", Local: "resourcetype"},
+	{Space: "DAV:", Local: "displayname"},
+	{Space: "urn:ietf:params:xml:ns:caldav", Local: "calendar-description"},
+	{Space: "urn:ietf:params:xml:ns:caldav", Local: "calendar-timezone"},
+	{Space: "urn:ietf:params:xml:ns:caldav", Local: "supported-calendar-component-set"},
+	{Space: "urn:ietf:params:xml:ns:caldav", Local: "max-resource-size"},
+	{Space: "http://apple.com/ns/ical/", Local: "calendar-color"},
+	{Space: "http://calendarserver.org/ns/", Local: "getctag"},
+	{Space: "DAV:", Local: "sync-token"},
+}
+
+// lists the calendar collections found in a calendar home, along with their metadata
+func (c *Client) ListCalendars(home string) ([]*CalendarInfo, error) {
+	return c.ListCalendarsContext(context.Background(), home)
+}
+
+// same as ListCalendars, using a context to cancel the request
+func (c *Client) ListCalendarsContext(ctx context.Context, home string) ([]*CalendarInfo, error) {
+
+	var calendars []*CalendarInfo
+	ms := new(cent.Multistatus)
+
+	if req, err := c.Server().WebDAV().NewRequestWithContext(ctx, "PROPFIND", home, cent.NewNamedPropFind(calendarInfoProps...)); err != nil {
+		return nil, utils.NewError(c.ListCalendarsContext, "unable to create request", c, err)
+	} else if req.Http().Native().Header.Set("Depth", string(webdav.Depth1)); false {
+	} else if resp, err := c.WebDAV().Do(req); err != nil {
+		return nil, utils.NewError(c.ListCalendarsContext, "unable to execute request", c, err)
+	} else if resp.StatusCode != webdav.StatusMulti {
+		err := resp.DecodeError()
+		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
+		return nil, utils.NewError(c.ListCalendarsContext, msg, c, err)
+	} else if err := resp.Decode(ms); err != nil {
+		return nil, utils.NewError(c.ListCalendarsContext, "unable to decode response", c, err)
+	}
+
+	for _, r := range ms.Responses {
+		if info, err := newCalendarInfo(r); err != nil {
+			msg := fmt.Sprintf("unable to decode properties of %s", r.Href)
+			return nil, utils.NewError(c.ListCalendarsContext, msg, c, err)
+		} else if info != nil {
+			calendars = append(calendars, info)
+		}
+	}
+
+	return calendars, nil
+
+}
+
+// decodes the found properties of a multistatus response, returns nil for anything but calendars
+func newCalendarInfo(r *cent.Response) (*CalendarInfo, error) {
+
+	var isCalendar bool
+	info := &CalendarInfo{Href: r.Href}
+
+	for _, ps := range r.PropStats {
+
+		if ps.Prop == nil || !ps.Status.OK() {
+			continue // skip properties that were not found
+		}
+
+		p := ps.Prop
+		if p.ResourceType != nil && p.ResourceType.Calendar != nil {
+			isCalendar = true
+		}
+
+		if p.DisplayName != "" {
+			info.DisplayName = p.DisplayName
+		}
+
+		if p.CalendarDescription != "" {
+			info.Description = p.CalendarDescription
+		}
+
+		if p.CalendarColor != "" {
+			if rgba, err := parseCalendarColor(p.CalendarColor); err != nil {
+				return nil, utils.NewError(newCalendarInfo, "unable to decode calendar color", r, err)
+			} else {
+				info.Color = rgba
+			}
+		}
+
+		if p.CalendarTimeZone != "" {
+			// a time zone that cannot be decoded is left unset rather than failing the whole listing
+			cal := new(components.Calendar)
+			if err := icalendar.Unmarshal(p.CalendarTimeZone, cal); err == nil && len(cal.TimeZones) > 0 && cal.TimeZones[0] != nil {
+				info.TimeZoneId = cal.TimeZones[0].Id
+				if loc, err := time.LoadLocation(info.TimeZoneId); err == nil {
+					info.TimeZone = loc
+				}
+			}
+		}
+
+		if set := p.SupportedCalendarComponentSet; set != nil {
+			for _, comp := range set.Components {
+				info.SupportedComponents = append(info.SupportedComponents, comp.Name)
+			}
+		}
+
+		if p.MaxResourceSize > 0 {
+			info.MaxResourceSize = p.MaxResourceSize
+		}
+
+		if p.CTag != "" {
+			info.CTag = p.CTag
+		}
+
+		if p.SyncToken != "" {
+			info.SyncToken = p.SyncToken
+		}
+
+	}
+
+	if !isCalendar {
+		return nil, nil
+	}
+
+	return info, nil
+
+}
+
+// decodes the #RRGGBB or #RRGGBBAA notation used by Apple's calendar-color property
+func parseCalendarColor(value string) (*color.RGBA, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "#")
+	if len(value) == 6 {
+		value += "ff"
+	}
+	if b, err := hex.DecodeString(value); err != nil {
+		return nil, utils.NewError(parseCalendarColor, "invalid color "+value, value, err)
+	} else if len(b) != 4 {
+		return nil, utils.NewError(parseCalendarColor, "invalid color length "+value, value, nil)
+	} else {
+		return &color.RGBA{R: b[0], G: b[1], B: b[2], A: b[3]}, nil
+	}
+}
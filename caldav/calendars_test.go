@@ -0,0 +1,308 @@
+package caldav
+
+import (
+	"fmt"
+	"github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/webdav"
+	. "gopkg.in/check.v1"
+	"image/color"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type CalendarsSuite struct{}
+
+var _ = Suite(new(CalendarsSuite))
+
+func TestCalendars(t *testing.T) { TestingT(t) }
+
+const calendarsResponse = `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/"
+ xmlns:ical="http://apple.com/ns/ical/">
+ <d:response>
+  <d:href>/calendars/jon/</d:href>
+  <d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop>
+   <d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+ <d:response>
+  <d:href>/calendars/jon/work/</d:href>
+  <d:propstat>
+   <d:prop>
+    <d:resourcetype><d:collection/><c:calendar/></d:resourcetype>
+    <d:displayname>Work</d:displayname>
+    <c:calendar-description>Meetings and such</c:calendar-description>
+    <c:calendar-timezone>BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VTIMEZONE
+TZID:Europe/Paris
+BEGIN:STANDARD
+DTSTART:19701025T030000
+TZOFFSETFROM:+0200
+TZOFFSETTO:+0100
+END:STANDARD
+END:VTIMEZONE
+END:VCALENDAR
+</c:calendar-timezone>
+    <c:supported-calendar-component-set><c:comp name="VEVENT"/><c:comp name="VTODO"/></c:supported-calendar-component-set>
+    <c:max-resource-size>102400</c:max-resource-size>
+    <ical:calendar-color>#FF2968FF</ical:calendar-color>
+    <cs:getctag>ctag-1</cs:getctag>
+    <d:sync-token>http://example.com/sync/1</d:sync-token>
+   </d:prop>
+   <d:status>HTTP/1.1 200 OK</d:status>
+  </d:propstat>
+ </d:response>
+ <d:response>
+  <d:href>/calendars/jon/home/</d:href>
+  <d:propstat>
+   <d:prop>
+    <d:resourcetype><d:collection/><c:calendar/></d:resourcetype>
+    <d:displayname>Home</d:displayname>
+    <c:calendar-timezone>not a calendar</c:calendar-timezone>
+    <ical:calendar-color>#00ff00</ical:calendar-color>
+   </d:prop>
+   <d:status>HTTP/1.1 200 OK</d:status>
+  </d:propstat>
+  <d:propstat>
+   <d:prop><c:calendar-description/><cs:getctag/></d:prop>
+   <d:status>HTTP/1.1 404 Not Found</d:status>
+  </d:propstat>
+ </d:response>
+</d:multistatus>`
+
+func (s *CalendarsSuite) TestListCalendars(c *C) {
+
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		if r.Method != "PROPFIND" || r.Header.Get("Depth") != "1" || r.URL.Path != "/calendars/jon/" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(207)
+		fmt.Fprint(w, calendarsResponse)
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	calendars, err := client.ListCalendars("/calendars/jon/")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(body, "getctag"), Equals, true)
+	c.Assert(strings.Contains(body, "calendar-color"), Equals, true)
+	c.Assert(calendars, HasLen, 2)
+
+	work := calendars[0]
+	c.Assert(work.Href, Equals, "/calendars/jon/work/")
+	c.Assert(work.DisplayName, Equals, "Work")
+	c.Assert(work.Description, Equals, "Meetings and such")
+	c.Assert(work.Color, DeepEquals, &color.RGBA{R: 0xff, G: 0x29, B: 0x68, A: 0xff})
+	c.Assert(work.TimeZoneId, Equals, "Europe/Paris")
+	c.Assert(work.TimeZone, NotNil)
+	c.Assert(work.TimeZone.String(), Equals, "Europe/Paris")
+	c.Assert(work.SupportedComponents, DeepEquals, []values.ComponentName{values.EventComponentName, values.ToDoComponentName})
+	c.Assert(work.Supports(values.EventComponentName), Equals, true)
+	c.Assert(work.Supports(values.JournalComponentName), Equals, false)
+	c.Assert(work.MaxResourceSize, Equals, int64(102400))
+	c.Assert(work.CTag, Equals, "ctag-1")
+	c.Assert(work.SyncToken, Equals, "http://example.com/sync/1")
+
+	home := calendars[1]
+	c.Assert(home.DisplayName, Equals, "Home")
+	c.Assert(home.Color, DeepEquals, &color.RGBA{G: 0xff, A: 0xff})
+	c.Assert(home.Description, Equals, "")
+	c.Assert(home.TimeZoneId, Equals, "")
+	c.Assert(home.TimeZone, IsNil)
+	c.Assert(home.Supports(values.JournalComponentName), Equals, true)
+
+}
+
+func (s *CalendarsSuite) TestMakeCalendarWithOptions(c *C) {
+
+	var method, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, body = r.Method, string(data)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	loc, err := time.LoadLocation("Europe/Paris")
+	c.Assert(err, IsNil)
+	options := &CalendarOptions{
+		DisplayName:         "Work",
+		Description:         "Meetings and such",
+		Color:               &color.RGBA{R: 0xff, G: 0x29, B: 0x68, A: 0xff},
+		TimeZone:            loc,
+		SupportedComponents: []values.ComponentName{values.EventComponentName},
+	}
+
+	c.Assert(client.MakeCalendar("/calendars/jon/work/", options), IsNil)
+	c.Assert(method, Equals, "MKCALENDAR")
+	c.Assert(body, Matches, `<mkcalendar xmlns="urn:ietf:params:xml:ns:caldav"><set xmlns="DAV:"><prop xmlns="DAV:">.*</prop></set></mkcalendar>`)
+	c.Assert(strings.Contains(body, "<displayname>Work</displayname>"), Equals, true)
+	c.Assert(strings.Contains(body, "Meetings and such</calendar-description>"), Equals, true)
+	c.Assert(strings.Contains(body, "#FF2968FF</calendar-color>"), Equals, true)
+	c.Assert(strings.Contains(body, "TZID:Europe/Paris"), Equals, true)
+	c.Assert(strings.Contains(body, `name="VEVENT"></comp>`), Equals, true)
+
+	c.Assert(client.MakeCalendar("/calendars/jon/empty/"), IsNil)
+	c.Assert(method, Equals, "MKCALENDAR")
+	c.Assert(body, Equals, "")
+
+	c.Assert(client.MakeCalendarCollection("/calendars/jon/work/", options), IsNil)
+	c.Assert(method, Equals, "MKCOL")
+	c.Assert(body, Matches, `<mkcol xmlns="DAV:"><set xmlns="DAV:"><prop xmlns="DAV:">.*</prop></set></mkcol>`)
+	c.Assert(strings.Contains(body, `<resourcetype><collection></collection><calendar xmlns="urn:ietf:params:xml:ns:caldav"></calendar></resourcetype>`), Equals, true)
+
+}
+
+func (s *CalendarsSuite) TestMakeCalendarFailure(c *C) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<C:mkcalendar-response xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+ <D:propstat><D:prop><D:displayname/></D:prop><D:status>HTTP/1.1 424 Failed Dependency</D:status></D:propstat>
+ <D:propstat><D:prop><C:calendar-timezone/></D:prop><D:status>HTTP/1.1 409 Conflict</D:status>
+  <D:responsedescription>unknown timezone</D:responsedescription></D:propstat>
+</C:mkcalendar-response>`)
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	err = client.MakeCalendar("/calendars/jon/work/", &CalendarOptions{DisplayName: "Work"})
+	c.Assert(err, ErrorMatches, "(?s).*403 Forbidden.*displayname: HTTP/1.1 424 Failed Dependency; "+
+		"calendar-timezone: HTTP/1.1 409 Conflict \\(unknown timezone\\).*")
+
+}
+
+func (s *CalendarsSuite) TestUpdateCalendar(c *C) {
+
+	var method, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, body = r.Method, string(data)
+		w.WriteHeader(207)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+ <D:response>
+  <D:href>/calendars/jon/work/</D:href>
+  <D:propstat><D:prop><D:displayname/></D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat>
+ </D:response>
+</D:multistatus>`)
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	c.Assert(client.RenameCalendar("/calendars/jon/work/", "Office"), IsNil)
+	c.Assert(method, Equals, "PROPPATCH")
+	c.Assert(body, Matches, `<propertyupdate xmlns="DAV:"><set xmlns="DAV:"><prop xmlns="DAV:">.*</prop></set></propertyupdate>`)
+	c.Assert(strings.Contains(body, "<displayname>Office</displayname>"), Equals, true)
+
+	c.Assert(client.SetCalendarDescription("/calendars/jon/work/", ""), IsNil)
+	c.Assert(body, Matches, `<propertyupdate xmlns="DAV:"><remove xmlns="DAV:"><prop xmlns="DAV:">`+
+		`<calendar-description xmlns="urn:ietf:params:xml:ns:caldav"></calendar-description></prop></remove></propertyupdate>`)
+
+	method = ""
+	c.Assert(client.UpdateCalendar("/calendars/jon/work/", nil), ErrorMatches, "(?s).*no calendar options provided.*")
+	c.Assert(method, Equals, "")
+
+}
+
+func (s *CalendarsSuite) TestUpdateCalendarFailure(c *C) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(207)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:" xmlns:ical="http://apple.com/ns/ical/">
+ <D:response>
+  <D:href>/calendars/jon/work/</D:href>
+  <D:propstat><D:prop><D:displayname/></D:prop><D:status>HTTP/1.1 424 Failed Dependency</D:status></D:propstat>
+  <D:propstat><D:prop><ical:calendar-color/></D:prop><D:status>HTTP/1.1 403 Forbidden</D:status>
+   <D:responsedescription>read-only</D:responsedescription></D:propstat>
+ </D:response>
+</D:multistatus>`)
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	err = client.UpdateCalendar("/calendars/jon/work/", &CalendarOptions{
+		DisplayName: "Office",
+		Color:       &color.RGBA{R: 0xff, A: 0xff},
+	})
+	perr, ok := err.(*webdav.ProppatchError)
+	c.Assert(ok, Equals, true)
+	c.Assert(perr.Href, Equals, "/calendars/jon/work/")
+	c.Assert(perr.Results, HasLen, 2)
+	c.Assert(perr.Results[0].FailedDependency(), Equals, true)
+
+	rejected := perr.Rejected()
+	c.Assert(rejected, HasLen, 1)
+	c.Assert(rejected[0].Name.Local, Equals, "calendar-color")
+	c.Assert(rejected[0].StatusCode, Equals, http.StatusForbidden)
+	c.Assert(rejected[0].Description, Equals, "read-only")
+
+}
+
+func (s *CalendarsSuite) TestUpdateCalendarRejected(c *C) {
+
+	var response string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(207)
+		fmt.Fprint(w, response)
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	response = `<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:">
+ <D:response>
+  <D:href>/calendars/jon/work/</D:href>
+  <D:status>HTTP/1.1 403 Forbidden</D:status>
+ </D:response>
+</D:multistatus>`
+	err = client.UpdateCalendar("/calendars/jon/work/", &CalendarOptions{
+		DisplayName: "Office",
+		Color:       &color.RGBA{R: 0xff, A: 0xff},
+	})
+	perr, ok := err.(*webdav.ProppatchError)
+	c.Assert(ok, Equals, true)
+	c.Assert(perr.Href, Equals, "/calendars/jon/work/")
+
+	rejected := perr.Rejected()
+	c.Assert(rejected, HasLen, 2)
+	c.Assert(rejected[0].Name.Local, Equals, "displayname")
+	c.Assert(rejected[0].StatusCode, Equals, http.StatusForbidden)
+	c.Assert(rejected[1].Name.Local, Equals, "calendar-color")
+	c.Assert(rejected[1].StatusCode, Equals, http.StatusForbidden)
+
+	response = `<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:"></D:multistatus>`
+	err = client.RenameCalendar("/calendars/jon/work/", "Office")
+	c.Assert(err, ErrorMatches, "(?s).*no property outcome in server response.*")
+
+}
@@ -1,7 +1,12 @@
 package caldav
 
 import (
+	"context"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"strings"
@@ -31,9 +36,14 @@ func (c *Client) Server() *Server {
 // fetches a list of CalDAV features supported by the server
 // returns an error if the server does not support DAV
 func (c *Client) Features(path string) ([]string, error) {
+	return c.FeaturesContext(context.Background(), path)
+}
+
+// same as Features, using a context to cancel the request
+func (c *Client) FeaturesContext(ctx context.Context, path string) ([]string, error) {
 	var cfeatures []string
-	if features, err := c.WebDAV().Features(path); err != nil {
-		return cfeatures, utils.NewError(c.Features, "unable to detect features", c, err)
+	if features, err := c.WebDAV().FeaturesContext(ctx, path); err != nil {
+		return cfeatures, utils.NewError(c.FeaturesContext, "unable to detect features", c, err)
 	} else {
 		for _, feature := range features {
 			if strings.HasPrefix(feature, "calendar-") {
@@ -47,8 +57,13 @@ func (c *Client) Features(path string) ([]string, error) {
 // fetches a list of CalDAV features and checks if a certain one is supported by the server
 // returns an error if the server does not support DAV
 func (c *Client) SupportsFeature(name string, path string) (bool, error) {
-	if features, err := c.Features(path); err != nil {
-		return false, utils.NewError(c.SupportsFeature, "feature detection failed", c, err)
+	return c.SupportsFeatureContext(context.Background(), name, path)
+}
+
+// same as SupportsFeature, using a context to cancel the request
+func (c *Client) SupportsFeatureContext(ctx context.Context, name string, path string) (bool, error) {
+	if features, err := c.FeaturesContext(ctx, path); err != nil {
+		return false, utils.NewError(c.SupportsFeatureContext, "feature detection failed", c, err)
 	} else {
 		var test = fmt.Sprintf("calendar-%s", name)
 		for _, feature := range features {
@@ -63,7 +78,12 @@ func (c *Client) SupportsFeature(name string, path string) (bool, error) {
 // fetches a list of CalDAV features and checks if a certain one is supported by the server
 // returns an error if the server does not support DAV
 func (c *Client) ValidateServer(path string) error {
-	if found, err := c.SupportsFeature("access", path); err != nil {
+	return c.ValidateServerContext(context.Background(), path)
+}
+
+// same as ValidateServer, using a context to cancel the request
+func (c *Client) ValidateServerContext(ctx context.Context, path string) error {
+	if found, err := c.SupportsFeatureContext(ctx, "access", path); err != nil {
 		return utils.NewError(c.SupportsFeature, "feature detection failed", c, err)
 	} else if !found {
 		return utils.NewError(c.SupportsFeature, "calendar access feature missing", c, nil)
@@ -73,16 +93,68 @@ func (c *Client) ValidateServer(path string) error {
 }
 
 // creates a new calendar collection on a given path
-func (c *Client) MakeCalendar(path string) error {
-	if req, err := c.Server().NewRequest("MKCALENDAR", path); err != nil {
-		return utils.NewError(c.MakeCalendar, "unable to create request", c, err)
-	} else if resp, err := c.Do(req); err != nil {
-		return utils.NewError(c.MakeCalendar, "unable to execute request", c, err)
+// optionally sets the initial properties of the calendar
+func (c *Client) MakeCalendar(path string, options ...*CalendarOptions) error {
+	return c.MakeCalendarContext(context.Background(), path, options...)
+}
+
+// same as MakeCalendar, using a context to cancel the request
+func (c *Client) MakeCalendarContext(ctx context.Context, path string, options ...*CalendarOptions) error {
+	var body []interface{}
+	if len(options) > 0 && options[0] != nil {
+		if prop, err := options[0].prop(); err != nil {
+			return utils.NewError(c.MakeCalendarContext, "unable to encode calendar options", c, err)
+		} else {
+			body = append(body, cent.NewMakeCalendar(prop))
+		}
+	}
+	if err := c.makeCollection(ctx, "MKCALENDAR", path, new(cent.MakeCalendarResponse), body...); err != nil {
+		return utils.NewError(c.MakeCalendarContext, "unable to create calendar", c, err)
+	}
+	return nil
+}
+
+// creates a new calendar collection on a given path using an extended MKCOL request, see RFC 5689
+// optionally sets the initial properties of the calendar
+func (c *Client) MakeCalendarCollection(path string, options ...*CalendarOptions) error {
+	return c.MakeCalendarCollectionContext(context.Background(), path, options...)
+}
+
+// same as MakeCalendarCollection, using a context to cancel the request
+func (c *Client) MakeCalendarCollectionContext(ctx context.Context, path string, options ...*CalendarOptions) error {
+	prop := new(cent.Prop)
+	if len(options) > 0 && options[0] != nil {
+		var err error
+		if prop, err = options[0].prop(); err != nil {
+			return utils.NewError(c.MakeCalendarCollectionContext, "unable to encode calendar options", c, err)
+		}
+	}
+	prop.ResourceType = &entities.ResourceType{
+		Collection: new(entities.ResourceTypeCollection),
+		Calendar:   new(entities.ResourceTypeCalendar),
+	}
+	body := cent.NewMakeCollection(prop)
+	if err := c.makeCollection(ctx, "MKCOL", path, new(cent.MakeCollectionResponse), body); err != nil {
+		return utils.NewError(c.MakeCalendarCollectionContext, "unable to create calendar", c, err)
+	}
+	return nil
+}
+
+// executes a collection creation request, decoding either a property failure report or a DAV error on failure
+func (c *Client) makeCollection(ctx context.Context, method string, path string, failure error, body ...interface{}) error {
+	if req, err := c.Server().WebDAV().NewRequestWithContext(ctx, method, path, body...); err != nil {
+		return utils.NewError(c.makeCollection, "unable to create request", c, err)
+	} else if resp, err := c.WebDAV().Do(req); err != nil {
+		return utils.NewError(c.makeCollection, "unable to execute request", c, err)
 	} else if resp.StatusCode != http.StatusCreated {
-		err := new(entities.Error)
-		resp.Decode(err)
 		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
-		return utils.NewError(c.MakeCalendar, msg, c, err)
+		if data, err := ioutil.ReadAll(resp.Body); err != nil {
+			return utils.NewError(c.makeCollection, msg, c, err)
+		} else if err := xml.Unmarshal(data, failure); err == nil {
+			return utils.NewError(c.makeCollection, msg, c, failure)
+		} else {
+			return utils.NewError(c.makeCollection, msg, c, webdav.NewResponseError(resp, data))
+		}
 	} else {
 		return nil
 	}
@@ -90,84 +162,294 @@ func (c *Client) MakeCalendar(path string) error {
 
 // creates or updates one or more events on the remote CalDAV server
 func (c *Client) PutEvents(path string, events ...*components.Event) error {
+	return c.PutEventsContext(context.Background(), path, events...)
+}
+
+// same as PutEvents, using a context to cancel the request
+func (c *Client) PutEventsContext(ctx context.Context, path string, events ...*components.Event) error {
 	if len(events) <= 0 {
-		return utils.NewError(c.PutEvents, "no calendar events provided", c, nil)
+		return utils.NewError(c.PutEventsContext, "no calendar events provided", c, nil)
 	} else if cal := components.NewCalendar(events...); events[0] == nil {
-		return utils.NewError(c.PutEvents, "icalendar event must not be nil", c, nil)
-	} else if err := c.PutCalendars(path, cal); err != nil {
-		return utils.NewError(c.PutEvents, "unable to put calendar", c, err)
+		return utils.NewError(c.PutEventsContext, "icalendar event must not be nil", c, nil)
+	} else if err := c.PutCalendarsContext(ctx, path, cal); err != nil {
+		return utils.NewError(c.PutEventsContext, "unable to put calendar", c, err)
 	}
 	return nil
 }
 
 // creates or updates one or more calendars on the remote CalDAV server
 func (c *Client) PutCalendars(path string, calendars ...*components.Calendar) error {
-	if req, err := c.Server().NewRequest("PUT", path, calendars); err != nil {
-		return utils.NewError(c.PutCalendars, "unable to encode request", c, err)
+	return c.PutCalendarsContext(context.Background(), path, calendars...)
+}
+
+// same as PutCalendars, using a context to cancel the request
+func (c *Client) PutCalendarsContext(ctx context.Context, path string, calendars ...*components.Calendar) error {
+	if req, err := c.Server().NewRequestWithContext(ctx, "PUT", path, calendars); err != nil {
+		return utils.NewError(c.PutCalendarsContext, "unable to encode request", c, err)
 	} else if resp, err := c.Do(req); err != nil {
-		return utils.NewError(c.PutCalendars, "unable to execute request", c, err)
+		return utils.NewError(c.PutCalendarsContext, "unable to execute request", c, err)
 	} else if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
-		err := new(entities.Error)
-		resp.WebDAV().Decode(err)
+		err := resp.WebDAV().DecodeError()
 		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
-		return utils.NewError(c.PutCalendars, msg, c, err)
+		return utils.NewError(c.PutCalendarsContext, msg, c, err)
 	}
 	return nil
 }
 
 // attempts to fetch an event on the remote CalDAV server
 func (c *Client) GetEvents(path string) ([]*components.Event, error) {
+	return c.GetEventsContext(context.Background(), path)
+}
+
+// same as GetEvents, using a context to cancel the request
+func (c *Client) GetEventsContext(ctx context.Context, path string) ([]*components.Event, error) {
 	cal := new(components.Calendar)
-	if req, err := c.Server().NewRequest("GET", path); err != nil {
-		return nil, utils.NewError(c.GetEvents, "unable to create request", c, err)
+	if req, err := c.Server().NewRequestWithContext(ctx, "GET", path); err != nil {
+		return nil, utils.NewError(c.GetEventsContext, "unable to create request", c, err)
 	} else if resp, err := c.Do(req); err != nil {
-		return nil, utils.NewError(c.GetEvents, "unable to execute request", c, err)
+		return nil, utils.NewError(c.GetEventsContext, "unable to execute request", c, err)
 	} else if resp.StatusCode != http.StatusOK {
-		err := new(entities.Error)
-		resp.WebDAV().Decode(err)
+		err := resp.WebDAV().DecodeError()
 		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
-		return nil, utils.NewError(c.GetEvents, msg, c, err)
+		return nil, utils.NewError(c.GetEventsContext, msg, c, err)
 	} else if err := resp.Decode(cal); err != nil {
-		return nil, utils.NewError(c.GetEvents, "unable to decode response", c, err)
+		return nil, utils.NewError(c.GetEventsContext, "unable to decode response", c, err)
 	} else {
 		return cal.Events, nil
 	}
 }
 
 // attempts to fetch an event on the remote CalDAV server
+// if the server fails to return some of the matching resources, the events of the remaining ones
+// are returned along with a *webdav.MultistatusError listing the failures
 func (c *Client) QueryEvents(path string, query *cent.CalendarQuery) (events []*components.Event, oerr error) {
+	return c.QueryEventsContext(context.Background(), path, query)
+}
+
+// same as QueryEvents, using a context to cancel the request
+func (c *Client) QueryEventsContext(ctx context.Context, path string, query *cent.CalendarQuery) (events []*components.Event, oerr error) {
+	oerr = c.QueryEventsFuncContext(ctx, path, query, func(href string, found []*components.Event) error {
+		events = append(events, found...)
+		return nil
+	})
+	if _, ok := oerr.(*webdav.MultistatusError); oerr != nil && !ok {
+		oerr = utils.NewError(c.QueryEventsContext, "unable to query events", c, oerr)
+	}
+	return
+}
+
+// streams the events matching a query to a callback, one calendar object at a time as the response arrives.
+// the callback may return webdav.ErrStop to stop early, any other error aborts the query and is returned as is.
+// failures reported by the server for some of the resources are returned as a *webdav.MultistatusError
+func (c *Client) QueryEventsFunc(path string, query *cent.CalendarQuery, fn func(href string, events []*components.Event) error) error {
+	return c.QueryEventsFuncContext(context.Background(), path, query, fn)
+}
+
+// same as QueryEventsFunc, using a context to cancel the request
+func (c *Client) QueryEventsFuncContext(ctx context.Context, path string, query *cent.CalendarQuery, fn func(href string, events []*components.Event) error) error {
+	if err := c.reportEvents(ctx, path, webdav.Depth1, query, fn); errors.Is(err, ErrNotFound) {
+		return nil // no events if not found
+	} else {
+		return err
+	}
+}
+
+// a calendar object resource returned by a query, keeping its events together
+type CalendarObject struct {
+	// the path of the calendar object
+	Href string
+	// the entity tag of the calendar object, if the query asked for it
+	ETag string
+	// the calendar data of the object, as limited by the query
+	Calendar *components.Calendar
+}
+
+// groups the events of the calendar object into series, keeping overrides attached to their master event.
+// with a query limiting recurrence sets, the master event holds the recurrence rules and may be edited as a whole
+func (o *CalendarObject) Series() []*components.EventSeries {
+	return o.Calendar.EventSeries()
+}
+
+// fetches the calendar objects matching a query, as opposed to QueryEvents which flattens their events.
+// failures reported by the server for some of the resources are returned as a *webdav.MultistatusError,
+// along with the objects that were found
+func (c *Client) QueryObjects(path string, query *cent.CalendarQuery) (objects []*CalendarObject, oerr error) {
+	return c.QueryObjectsContext(context.Background(), path, query)
+}
+
+// same as QueryObjects, using a context to cancel the request
+func (c *Client) QueryObjectsContext(ctx context.Context, path string, query *cent.CalendarQuery) (objects []*CalendarObject, oerr error) {
+	oerr = c.reportObjects(ctx, path, webdav.Depth1, query, func(o *CalendarObject) error {
+		objects = append(objects, o)
+		return nil
+	})
+	if errors.Is(oerr, ErrNotFound) {
+		oerr = nil // no objects if not found
+	} else if _, ok := oerr.(*webdav.MultistatusError); oerr != nil && !ok {
+		oerr = utils.NewError(c.QueryObjectsContext, "unable to query calendar objects", c, oerr)
+	}
+	return
+}
+
+// fetches the events of a set of calendar objects in a single request, see RFC 4791 section 7.9
+// if the server fails to return some of the resources, the events of the remaining ones
+// are returned along with a *webdav.MultistatusError listing the failures
+func (c *Client) MultigetEvents(path string, hrefs ...string) (events []*components.Event, oerr error) {
+	return c.MultigetEventsContext(context.Background(), path, hrefs...)
+}
+
+// same as MultigetEvents, using a context to cancel the request
+func (c *Client) MultigetEventsContext(ctx context.Context, path string, hrefs ...string) (events []*components.Event, oerr error) {
+	oerr = c.MultigetEventsFuncContext(ctx, path, hrefs, func(href string, found []*components.Event) error {
+		events = append(events, found...)
+		return nil
+	})
+	if _, ok := oerr.(*webdav.MultistatusError); oerr != nil && !ok {
+		oerr = utils.NewError(c.MultigetEventsContext, "unable to fetch events", c, oerr)
+	}
+	return
+}
+
+// streams the events of a set of calendar objects to a callback, one calendar object at a time as the response arrives.
+// the callback may return webdav.ErrStop to stop early, any other error aborts the request and is returned as is.
+// failures reported by the server for some of the resources are returned as a *webdav.MultistatusError
+func (c *Client) MultigetEventsFunc(path string, hrefs []string, fn func(href string, events []*components.Event) error) error {
+	return c.MultigetEventsFuncContext(context.Background(), path, hrefs, fn)
+}
+
+// same as MultigetEventsFunc, using a context to cancel the request
+func (c *Client) MultigetEventsFuncContext(ctx context.Context, path string, hrefs []string, fn func(href string, events []*components.Event) error) error {
+	if len(hrefs) <= 0 {
+		return utils.NewError(c.MultigetEventsFuncContext, "no calendar object hrefs provided", c, nil)
+	} else {
+		return c.reportEvents(ctx, path, webdav.Depth1, cent.NewCalendarMultiget(hrefs...), fn)
+	}
+}
+
+// streams the events of a calendar REPORT to a callback, collecting the failures reported by the server
+func (c *Client) reportEvents(ctx context.Context, path string, depth webdav.Depth, body interface{}, fn func(href string, events []*components.Event) error) error {
+	return c.reportObjects(ctx, path, depth, body, func(o *CalendarObject) error {
+		return fn(o.Href, o.Calendar.Events)
+	})
+}
+
+// streams the calendar objects of a calendar REPORT to a callback, collecting the failures reported by the server
+func (c *Client) reportObjects(ctx context.Context, path string, depth webdav.Depth, body interface{}, fn func(*CalendarObject) error) error {
+
+	var failures []*webdav.ResponseFailure
+
+	err := c.report(ctx, path, depth, body, func(r *cent.Response) error {
+		if r.Failed() {
+			failures = append(failures, &webdav.ResponseFailure{
+				Href:        r.Href,
+				Status:      *r.Status,
+				Description: r.ResponseDescription,
+				Error:       r.Error,
+			})
+			return nil
+		}
+		for _, p := range r.PropStats {
+			if p.Prop == nil || p.Prop.CalendarData == nil {
+				continue
+			} else if !p.Status.OK() {
+				failures = append(failures, &webdav.ResponseFailure{
+					Href:        r.Href,
+					Status:      p.Status,
+					Description: p.ResponseDescription,
+					Error:       p.Error,
+				})
+			} else if cal, err := p.Prop.CalendarData.CalendarComponent(); err != nil {
+				msg := fmt.Sprintf("unable to decode calendar data of %s", r.Href)
+				return utils.NewError(c.reportObjects, msg, c, err)
+			} else if err := fn(&CalendarObject{Href: r.Href, ETag: p.Prop.GetETag, Calendar: cal}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	} else if len(failures) > 0 {
+		return &webdav.MultistatusError{Failures: failures}
+	}
+
+	return nil
+
+}
+
+// executes a REPORT request, handing each response of the multistatus body to a callback as it is decoded.
+// stops without an error when the callback returns webdav.ErrStop
+func (c *Client) report(ctx context.Context, path string, depth webdav.Depth, body interface{}, fn func(*cent.Response) error) error {
+
+	req, err := c.Server().WebDAV().NewRequestWithContext(ctx, "REPORT", path, body)
+	if err != nil {
+		return utils.NewError(c.report, "unable to create request", c, err)
+	}
+
+	req.Http().Native().Header.Set("Depth", string(depth))
+	resp, err := c.WebDAV().Do(req)
+	if err != nil {
+		return utils.NewError(c.report, "unable to execute request", c, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != webdav.StatusMulti {
+		err := resp.DecodeError()
+		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
+		return utils.NewError(c.report, msg, c, err)
+	}
+
+	for ms := resp.Multistatus(); ; {
+		r := new(cent.Response)
+		if err := ms.Next(r); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return utils.NewError(c.report, "unable to decode response", c, err)
+		} else if err := fn(r); err == webdav.ErrStop {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+}
+
+// executes a PROPFIND request for CalDAV properties against the server
+// returns a multistatus XML entity
+func (c *Client) Propfind(path string, depth webdav.Depth, pf *cent.Propfind) (*cent.Multistatus, error) {
+	return c.PropfindContext(context.Background(), path, depth, pf)
+}
+
+// same as Propfind, using a context to cancel the request
+func (c *Client) PropfindContext(ctx context.Context, path string, depth webdav.Depth, pf *cent.Propfind) (*cent.Multistatus, error) {
+	if ms, err := c.propfind(ctx, c.Server().WebDAV().Http().AbsUrlStr(path), depth, pf); err != nil {
+		return nil, utils.NewError(c.PropfindContext, "unable to find properties", c, err)
+	} else {
+		return ms, nil
+	}
+}
+
+func (c *Client) propfind(ctx context.Context, urlstr string, depth webdav.Depth, pf *cent.Propfind) (*cent.Multistatus, error) {
+
 	ms := new(cent.Multistatus)
-	if req, err := c.Server().WebDAV().NewRequest("REPORT", path, query); err != nil {
-		oerr = utils.NewError(c.QueryEvents, "unable to create request", c, err)
-	} else if req.Http().Native().Header.Set("Depth", string(webdav.Depth1)); false {
+
+	if req, err := webdav.NewRequestWithContext(ctx, "PROPFIND", urlstr, pf); err != nil {
+		return nil, utils.NewError(c.propfind, "unable to create request", c, err)
+	} else if req.Http().Native().Header.Set("Depth", string(depth)); depth == "" {
+		return nil, utils.NewError(c.propfind, "search depth must be defined", c, nil)
 	} else if resp, err := c.WebDAV().Do(req); err != nil {
-		oerr = utils.NewError(c.QueryEvents, "unable to execute request", c, err)
-	} else if resp.StatusCode == http.StatusNotFound {
-		return // no events if not found
+		return nil, utils.NewError(c.propfind, "unable to execute request", c, err)
 	} else if resp.StatusCode != webdav.StatusMulti {
-		err := new(entities.Error)
+		err := resp.DecodeError()
 		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
-		resp.Decode(err)
-		oerr = utils.NewError(c.QueryEvents, msg, c, err)
+		return nil, utils.NewError(c.propfind, msg, c, err)
 	} else if err := resp.Decode(ms); err != nil {
-		msg := "unable to decode response"
-		oerr = utils.NewError(c.QueryEvents, msg, c, err)
-	} else {
-		for i, r := range ms.Responses {
-			for j, p := range r.PropStats {
-				if p.Prop == nil || p.Prop.CalendarData == nil {
-					continue
-				} else if cal, err := p.Prop.CalendarData.CalendarComponent(); err != nil {
-					msg := fmt.Sprintf("unable to decode property %d of response %d", j, i)
-					oerr = utils.NewError(c.QueryEvents, msg, c, err)
-					return
-				} else {
-					events = append(events, cal.Events...)
-				}
-			}
-		}
+		return nil, utils.NewError(c.propfind, "unable to decode response", c, err)
 	}
-	return
+
+	return ms, nil
+
 }
 
 // executes a CalDAV request
@@ -112,7 +112,8 @@ func (s *ClientSuite) TestRecurringEventQuery(c *C) {
 
 	// add in a filter for UID so that we don't get back unwanted results
 	pf := calentities.NewPropertyMatcher(properties.UIDPropertyName, uid)
-	query.Filter.ComponentFilter.ComponentFilter.PropertyFilter = pf
+	events := query.Filter.ComponentFilter.ComponentFilters[0]
+	events.PropertyFilters = append(events.PropertyFilters, pf)
 
 	// send the query to the server
 	if events, err := s.client.QueryEvents("/", query); err != nil {
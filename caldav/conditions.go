@@ -0,0 +1,164 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"errors"
+	cent "github.com/dolanor/caldav-go/caldav/entities"
+	"github.com/dolanor/caldav-go/webdav"
+	"github.com/dolanor/caldav-go/webdav/entities"
+)
+
+// a precondition or postcondition that a server names in a DAV:error body when it refuses a request
+type Condition xml.Name
+
+// returns the name of the condition element
+func (c Condition) Name() xml.Name {
+	return xml.Name(c)
+}
+
+func (c Condition) String() string {
+	return c.Local
+}
+
+const (
+	davNamespace    = "DAV:"
+	caldavNamespace = "urn:ietf:params:xml:ns:caldav"
+)
+
+// the conditions of calendar access, see RFC 4791 sections 5.3.2.1, 5.3.4, 7.5 to 7.10 and 9.6
+var (
+	CalendarCollectionLocationOK = Condition{Space: caldavNamespace, Local: "calendar-collection-location-ok"}
+	InitializeCalendarCollection = Condition{Space: caldavNamespace, Local: "initialize-calendar-collection"}
+	ResourceMustBeNull           = Condition{Space: davNamespace, Local: "resource-must-be-null"}
+	NeedsPrivilege               = Condition{Space: davNamespace, Local: "needs-privilege"}
+	SupportedCalendarData        = Condition{Space: caldavNamespace, Local: "supported-calendar-data"}
+	ValidCalendarData            = Condition{Space: caldavNamespace, Local: "valid-calendar-data"}
+	ValidCalendarObjectResource  = Condition{Space: caldavNamespace, Local: "valid-calendar-object-resource"}
+	SupportedCalendarComponent   = Condition{Space: caldavNamespace, Local: "supported-calendar-component"}
+	NoUIDConflict                = Condition{Space: caldavNamespace, Local: "no-uid-conflict"}
+	MaxResourceSize              = Condition{Space: caldavNamespace, Local: "max-resource-size"}
+	MinDateTime                  = Condition{Space: caldavNamespace, Local: "min-date-time"}
+	MaxDateTime                  = Condition{Space: caldavNamespace, Local: "max-date-time"}
+	MaxInstances                 = Condition{Space: caldavNamespace, Local: "max-instances"}
+	MaxAttendeesPerInstance      = Condition{Space: caldavNamespace, Local: "max-attendees-per-instance"}
+	ValidFilter                  = Condition{Space: caldavNamespace, Local: "valid-filter"}
+	SupportedFilter              = Condition{Space: caldavNamespace, Local: "supported-filter"}
+	SupportedCollation           = Condition{Space: caldavNamespace, Local: "supported-collation"}
+	NumberOfMatchesWithinLimits  = Condition{Space: davNamespace, Local: "number-of-matches-within-limits"}
+)
+
+// the conditions of collection synchronization, see RFC 6578 sections 3.2 and 3.3.
+// a truncated synchronization report names NumberOfMatchesWithinLimits
+var (
+	ValidSyncToken         = Condition{Space: davNamespace, Local: "valid-sync-token"}
+	SyncTraversalSupported = Condition{Space: davNamespace, Local: "sync-traversal-supported"}
+	SupportedReport        = Condition{Space: davNamespace, Local: "supported-report"}
+)
+
+// the conditions of calendar scheduling, see RFC 6638 sections 3.2.4, 3.2.10 and 9
+var (
+	ValidSchedulingMessage                 = Condition{Space: caldavNamespace, Local: "valid-scheduling-message"}
+	ValidOrganizer                         = Condition{Space: caldavNamespace, Local: "valid-organizer"}
+	UniqueSchedulingObjectResource         = Condition{Space: caldavNamespace, Local: "unique-scheduling-object-resource"}
+	SameOrganizerInAllComponents           = Condition{Space: caldavNamespace, Local: "same-organizer-in-all-components"}
+	AllowedOrganizerSchedulingObjectChange = Condition{Space: caldavNamespace, Local: "allowed-organizer-scheduling-object-change"}
+	AllowedAttendeeSchedulingObjectChange  = Condition{Space: caldavNamespace, Local: "allowed-attendee-scheduling-object-change"}
+	DefaultCalendarNeeded                  = Condition{Space: caldavNamespace, Local: "default-calendar-needed"}
+	ValidScheduleDefaultCalendarURL        = Condition{Space: caldavNamespace, Local: "valid-schedule-default-calendar-URL"}
+	DefaultCalendarDeleteAllowed           = Condition{Space: caldavNamespace, Local: "default-calendar-delete-allowed"}
+)
+
+// returns the conditions named by the server anywhere in an error returned by the client,
+// including the failed resources of a multistatus response and the rejected properties of an update
+func ErrorConditions(err error) []Condition {
+	var conditions []Condition
+	for _, body := range errorBodies(err) {
+		for _, p := range body.Conditions {
+			conditions = append(conditions, Condition(p.XMLName))
+		}
+	}
+	return conditions
+}
+
+// checks if the server named any of the conditions in an error returned by the client
+func HasCondition(err error, conditions ...Condition) bool {
+	for _, found := range ErrorConditions(err) {
+		for _, c := range conditions {
+			if found == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checks if a calendar object was refused because another object of the collection already uses its UID
+func IsUIDConflict(err error) bool {
+	return HasCondition(err, NoUIDConflict)
+}
+
+// returns the href of the calendar object already using the UID of a refused object, if the server named it
+func ConflictingResource(err error) (string, bool) {
+	for _, body := range errorBodies(err) {
+		if p := body.Condition(NoUIDConflict.Name()); p != nil {
+			refs := new(struct {
+				Hrefs []string `xml:"DAV: href"`
+			})
+			if p.Decode(refs) == nil && len(refs.Hrefs) > 0 {
+				return refs.Hrefs[0], true
+			}
+		}
+	}
+	return "", false
+}
+
+// checks if calendar data was refused as malformed, unsupported or breaking the rules of a calendar object resource
+func IsInvalidCalendarData(err error) bool {
+	return HasCondition(err, SupportedCalendarData, ValidCalendarData, ValidCalendarObjectResource, SupportedCalendarComponent)
+}
+
+// checks if calendar data was refused for exceeding a limit of the server, such as its size, dates or attendees
+func IsLimitExceeded(err error) bool {
+	return HasCondition(err, MaxResourceSize, MinDateTime, MaxDateTime, MaxInstances, MaxAttendeesPerInstance)
+}
+
+// checks if a query was refused for using a filter or collation the server does not support
+func IsUnsupportedQuery(err error) bool {
+	return HasCondition(err, ValidFilter, SupportedFilter, SupportedCollation, SupportedCalendarData, SupportedReport)
+}
+
+// checks if the server truncated the results of a report
+func IsTruncated(err error) bool {
+	return HasCondition(err, NumberOfMatchesWithinLimits)
+}
+
+// checks if a sync token is no longer valid, in which case a full synchronization is needed
+func IsInvalidSyncToken(err error) bool {
+	return HasCondition(err, ValidSyncToken)
+}
+
+// checks if a scheduling object resource was refused for breaking the rules of RFC 6638
+func IsSchedulingRejected(err error) bool {
+	return HasCondition(err, ValidSchedulingMessage, ValidOrganizer, UniqueSchedulingObjectResource,
+		SameOrganizerInAllComponents, AllowedOrganizerSchedulingObjectChange, AllowedAttendeeSchedulingObjectChange)
+}
+
+// collects the DAV:error bodies of an error, including those of the properties a collection creation refused
+func errorBodies(err error) []*entities.Error {
+	bodies := webdav.ErrorBodies(err)
+	var propstats []*entities.NamedPropStat
+	var mkcalendar *cent.MakeCalendarResponse
+	var mkcol *cent.MakeCollectionResponse
+	if errors.As(err, &mkcalendar) {
+		propstats = append(propstats, mkcalendar.PropStats...)
+	}
+	if errors.As(err, &mkcol) {
+		propstats = append(propstats, mkcol.PropStats...)
+	}
+	for _, ps := range propstats {
+		if ps.Error != nil {
+			bodies = append(bodies, ps.Error)
+		}
+	}
+	return bodies
+}
@@ -0,0 +1,118 @@
+package caldav
+
+import (
+	"errors"
+	"fmt"
+	. "gopkg.in/check.v1"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+type DiscoverySuite struct {
+	server *httptest.Server
+	host   string
+	port   uint16
+}
+
+var _ = Suite(new(DiscoverySuite))
+
+func TestDiscovery(t *testing.T) { TestingT(t) }
+
+// a resolver that publishes a single SRV and TXT record for a domain
+type stubResolver struct {
+	domain, service, target, path string
+	port                          uint16
+}
+
+func (r *stubResolver) LookupSRV(service, proto, name string) (string, []*net.SRV, error) {
+	if name != r.domain || service != r.service || proto != "tcp" {
+		return "", nil, errors.New("no such host")
+	}
+	return "", []*net.SRV{{Target: r.target + ".", Port: r.port}}, nil
+}
+
+func (r *stubResolver) LookupTXT(name string) ([]string, error) {
+	if name != fmt.Sprintf("_%s._tcp.%s", r.service, r.domain) || r.path == "" {
+		return nil, errors.New("no such host")
+	}
+	return []string{"path=" + r.path}, nil
+}
+
+func (s *DiscoverySuite) SetUpSuite(c *C) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(WellKnownPath, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/dav/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/dav/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PROPFIND" || r.Header.Get("Depth") != "0" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		} else if user, pass, ok := r.BasicAuth(); !ok || user != "jon" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(207)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:"><d:response><d:href>/dav/</d:href><d:propstat><d:prop>
+<d:current-user-principal><d:href>/principals/jon/</d:href></d:current-user-principal>
+</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response></d:multistatus>`)
+	})
+	mux.HandleFunc("/principals/jon/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(207)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:response>
+<d:href>/principals/jon/</d:href><d:propstat><d:prop>
+<c:calendar-home-set><d:href>/calendars/jon/</d:href></c:calendar-home-set>
+</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response></d:multistatus>`)
+	})
+	s.server = httptest.NewServer(mux)
+	uri, err := url.Parse(s.server.URL)
+	c.Assert(err, IsNil)
+	port, err := strconv.Atoi(uri.Port())
+	c.Assert(err, IsNil)
+	s.host, s.port = uri.Hostname(), uint16(port)
+}
+
+func (s *DiscoverySuite) TearDownSuite(c *C) {
+	s.server.Close()
+}
+
+func (s *DiscoverySuite) TestDiscoverFromUrl(c *C) {
+	uri, _ := url.Parse(s.server.URL)
+	uri.User = url.UserPassword("jon", "secret")
+	d, err := Discover(uri.String(), nil, new(stubResolver))
+	c.Assert(err, IsNil)
+	c.Assert(d.ContextUrl.Path, Equals, "/dav/")
+	c.Assert(d.PrincipalUrl.Path, Equals, "/principals/jon/")
+	c.Assert(d.CalendarHomeUrl.Path, Equals, "/calendars/jon/")
+	c.Assert(d.CalendarHomeUrl.Host, Equals, uri.Host)
+	server, err := d.Server()
+	c.Assert(err, IsNil)
+	c.Assert(server.WebDAV().Http().AbsUrlStr(d.CalendarHomeUrl.Path), Equals, d.CalendarHomeUrl.String())
+}
+
+func (s *DiscoverySuite) TestDiscoverFromDomain(c *C) {
+	resolver := &stubResolver{domain: "example.com", service: "caldav", target: s.host, port: s.port}
+	d, err := Discover("jon:secret@example.com", nil, resolver)
+	c.Assert(err, IsNil)
+	c.Assert(d.ContextUrl.Scheme, Equals, "http")
+	c.Assert(d.PrincipalUrl.Path, Equals, "/principals/jon/")
+	c.Assert(d.CalendarHomeUrl.Path, Equals, "/calendars/jon/")
+}
+
+func (s *DiscoverySuite) TestDiscoverFromTxtPath(c *C) {
+	resolver := &stubResolver{domain: "example.com", service: "caldav", target: s.host, port: s.port, path: "/dav/"}
+	d, err := Discover("jon:secret@example.com", nil, resolver)
+	c.Assert(err, IsNil)
+	c.Assert(d.ContextUrl.Path, Equals, "/dav/")
+	c.Assert(d.CalendarHomeUrl.Path, Equals, "/calendars/jon/")
+}
+
+func (s *DiscoverySuite) TestDiscoverUnauthorized(c *C) {
+	_, err := Discover(s.server.URL, nil, new(stubResolver))
+	c.Assert(err, ErrorMatches, "(?s).*401 Unauthorized.*")
+}
@@ -5,8 +5,10 @@ import (
 	"github.com/dolanor/caldav-go/caldav/values"
 	"github.com/dolanor/caldav-go/icalendar"
 	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/icalendar/properties"
 	"github.com/dolanor/caldav-go/utils"
 	"strings"
+	"time"
 )
 
 // a CalDAV calendar data object
@@ -15,6 +17,7 @@ type CalendarData struct {
 	Component           *Component           `xml:",omitempty"`
 	RecurrenceSetLimit  *RecurrenceSetLimit  `xml:",omitempty"`
 	ExpandRecurrenceSet *ExpandRecurrenceSet `xml:",omitempty"`
+	LimitFreeBusySet    *LimitFreeBusySet    `xml:",omitempty"`
 	Content             string               `xml:",chardata"`
 }
 
@@ -29,11 +32,97 @@ func (c *CalendarData) CalendarComponent() (*components.Calendar, error) {
 	}
 }
 
-// an iCalendar specifier for returned calendar data
+// how calendar data returns the recurring components overlapping a time range
+type RecurrenceMode string
+
+const (
+	// returns one component per instance within the range, each identified by a RECURRENCE-ID and without
+	// recurrence rules. the series cannot be edited from such data
+	ExpandRecurrences RecurrenceMode = "expand"
+	// returns the master component along with the overrides of the instances within the range
+	LimitRecurrences RecurrenceMode = "limit-recurrence-set"
+	// returns the recurring components as they are stored
+	RecurrencesAsIs RecurrenceMode = "as-is"
+)
+
+// sets how the calendar data returns the recurring components overlapping a time range.
+// as CalDAV requires both bounds, zero times stand for the earliest and latest times that can be expressed
+func (c *CalendarData) SetRecurrenceMode(mode RecurrenceMode, start, end time.Time) error {
+	c.ExpandRecurrenceSet, c.RecurrenceSetLimit = nil, nil
+	start, end = closedRange(start, end)
+	switch mode {
+	case ExpandRecurrences:
+		c.ExpandRecurrenceSet = &ExpandRecurrenceSet{StartTime: newDateTime("start", start), EndTime: newDateTime("end", end)}
+	case LimitRecurrences:
+		c.RecurrenceSetLimit = &RecurrenceSetLimit{StartTime: newDateTime("start", start), EndTime: newDateTime("end", end)}
+	case RecurrencesAsIs:
+	default:
+		return utils.NewError(c.SetRecurrenceMode, "unknown recurrence mode "+string(mode), c, nil)
+	}
+	return nil
+}
+
+// returns the recurrence mode of the calendar data
+func (c *CalendarData) RecurrenceMode() RecurrenceMode {
+	if c.ExpandRecurrenceSet != nil {
+		return ExpandRecurrences
+	} else if c.RecurrenceSetLimit != nil {
+		return LimitRecurrences
+	} else {
+		return RecurrencesAsIs
+	}
+}
+
+// restricts the free/busy time returned in the calendar data to a time range.
+// zero times stand for the earliest and latest times that can be expressed
+func (c *CalendarData) SetFreeBusyLimit(start, end time.Time) {
+	start, end = closedRange(start, end)
+	c.LimitFreeBusySet = &LimitFreeBusySet{StartTime: newDateTime("start", start), EndTime: newDateTime("end", end)}
+}
+
+// replaces the zero bounds of a time range with the earliest and latest times that can be expressed
+func closedRange(start, end time.Time) (time.Time, time.Time) {
+	if start.IsZero() {
+		start = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if end.IsZero() {
+		end = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+	}
+	return start, end
+}
+
+// an iCalendar specifier for returned calendar data, listing the properties and nested components to return.
+// a component lists either some properties or all of them, and either some nested components or all of them
 type Component struct {
-	XMLName    xml.Name        `xml:"urn:ietf:params:xml:ns:caldav comp"`
-	Properties []*PropertyName `xml:",omitempty"`
-	Components []*Component    `xml:",omitempty"`
+	XMLName       xml.Name             `xml:"urn:ietf:params:xml:ns:caldav comp"`
+	Name          values.ComponentName `xml:"name,attr,omitempty"`
+	AllProperties *AllProperties       `xml:",omitempty"`
+	Properties    []*PropertyName      `xml:"urn:ietf:params:xml:ns:caldav prop,omitempty"`
+	AllComponents *AllComponents       `xml:",omitempty"`
+	Components    []*Component         `xml:"urn:ietf:params:xml:ns:caldav comp,omitempty"`
+}
+
+// asks for all the properties of a component
+type AllProperties struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav allprop"`
+}
+
+// asks for all the nested components of a component
+type AllComponents struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav allcomp"`
+}
+
+// creates a specifier returning some properties of a component, or all of them if none is given.
+// nested components may be added to the Components list
+func NewComponent(name values.ComponentName, props ...properties.PropertyName) *Component {
+	c := &Component{Name: name}
+	if len(props) <= 0 {
+		c.AllProperties = new(AllProperties)
+	}
+	for _, p := range props {
+		c.Properties = append(c.Properties, &PropertyName{Name: string(p)})
+	}
+	return c
 }
 
 // used to restrict recurring event data to a particular time range
@@ -43,6 +132,13 @@ type RecurrenceSetLimit struct {
 	EndTime   *values.DateTime `xml:"end,attr"`
 }
 
+// used to restrict free/busy time data to a particular time range
+type LimitFreeBusySet struct {
+	XMLName   xml.Name         `xml:"urn:ietf:params:xml:ns:caldav limit-freebusy-set"`
+	StartTime *values.DateTime `xml:"start,attr"`
+	EndTime   *values.DateTime `xml:"end,attr"`
+}
+
 // used to expand recurring events into individual calendar event data
 type ExpandRecurrenceSet struct {
 	XMLName   xml.Name         `xml:"urn:ietf:params:xml:ns:caldav expand"`
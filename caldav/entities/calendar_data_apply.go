@@ -0,0 +1,240 @@
+package entities
+
+import (
+	"github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/icalendar"
+	"github.com/dolanor/caldav-go/icalendar/properties"
+	ivalues "github.com/dolanor/caldav-go/icalendar/values"
+	"github.com/dolanor/caldav-go/utils"
+	"strings"
+	"time"
+)
+
+// applies the calendar data specifier to a stored calendar object, as a server answering a query does, see
+// RFC 4791 section 9.6. recurring events are expanded or limited to the requested time range, free/busy time is
+// limited to its range, then the components and properties that were not asked for are left out.
+// only the events and free/busy components are rewritten, the other content lines being copied as they are
+func (c *CalendarData) Apply(data string) (string, error) {
+
+	if c.ExpandRecurrenceSet != nil || c.RecurrenceSetLimit != nil || c.LimitFreeBusySet != nil {
+		root, err := parseContentLines(data)
+		if err != nil {
+			return "", utils.NewError(c.Apply, "unable to parse calendar data", c, err)
+		}
+		lines, err := root.rewrite(nil, func(child *contentNode) ([]string, error) {
+			if values.ComponentName(child.name) == values.EventComponentName {
+				if r := c.ExpandRecurrenceSet; r != nil {
+					return child.expandEvent(r.StartTime.NativeTime(), r.EndTime.NativeTime())
+				} else if r := c.RecurrenceSetLimit; r != nil {
+					return child.limitEvent(r.StartTime.NativeTime(), r.EndTime.NativeTime())
+				}
+			} else if r := c.LimitFreeBusySet; r != nil && values.ComponentName(child.name) == values.FreeBusyComponentName {
+				return child.limitFreeBusy(r.StartTime.NativeTime(), r.EndTime.NativeTime())
+			}
+			return child.lines, nil
+		})
+		if err != nil {
+			return "", utils.NewError(c.Apply, "unable to apply calendar data limits", c, err)
+		}
+		data = strings.Join(lines, icalendar.Newline) + icalendar.Newline
+	}
+
+	if c.Component != nil {
+		data = c.Component.prune(data)
+	}
+
+	return data, nil
+
+}
+
+// replaces a recurring event with one event per instance within a time range, each identified by its
+// RECURRENCE-ID and starting at a UTC time, as RFC 4791 section 9.6.5 requires. overrides stand for the instance
+// they replace, events that do not recur are kept as they are, and so are the properties and components not related
+// to the recurrence
+func (n *contentNode) expandEvent(start, end time.Time) ([]string, error) {
+
+	override := n.property("RECURRENCE-ID") != nil
+	if !override && !n.recurs() {
+		return n.lines, nil
+	}
+
+	instances, err := n.eventInstances(start, end, false)
+	if err != nil {
+		return nil, err
+	}
+
+	utc := func(t time.Time) string {
+		return t.UTC().Format(ivalues.UTCDateTimeFormatString)
+	}
+
+	var lines []string
+	for _, i := range instances {
+		recurrenceId := "RECURRENCE-ID:" + utc(i.RecurrenceId)
+		instance, err := n.rewrite(func(line string) []string {
+			switch contentLineName(line) {
+			case "RRULE", "RDATE", "EXDATE":
+				return nil
+			case "DTSTART":
+				if override {
+					return []string{"DTSTART:" + utc(i.Start)}
+				}
+				return []string{"DTSTART:" + utc(i.Start), recurrenceId}
+			case "DTEND":
+				return []string{"DTEND:" + utc(i.End)}
+			case "RECURRENCE-ID":
+				return []string{recurrenceId}
+			default:
+				return []string{line}
+			}
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, instance...)
+	}
+
+	return lines, nil
+
+}
+
+// keeps the events that do not override an instance, along with the overrides of the instances within
+// a time range, see RFC 4791 section 9.6.6
+func (n *contentNode) limitEvent(start, end time.Time) ([]string, error) {
+	if n.property("RECURRENCE-ID") == nil {
+		return n.lines, nil
+	} else if instances, err := n.eventInstances(start, end, false); err != nil || len(instances) == 0 {
+		return nil, err
+	}
+	return n.lines, nil
+}
+
+// keeps the free/busy periods within a time range, see RFC 4791 section 9.6.7
+func (n *contentNode) limitFreeBusy(start, end time.Time) ([]string, error) {
+	return n.rewrite(func(line string) []string {
+		if contentLineName(line) != string(properties.FreeBusyPropertyName) {
+			return []string{line}
+		}
+		prop := properties.UnmarshalProperty(line)
+		t := new(ivalues.FreeBusyTime)
+		if err := t.DecodeICalValue(prop.Value); err != nil {
+			return []string{line}
+		}
+		var kept []string
+		for _, p := range t.Periods {
+			if p.Start().Before(end) && p.End().After(start) {
+				kept = append(kept, p.String())
+			}
+		}
+		if len(kept) == 0 {
+			return nil
+		}
+		return []string{line[:strings.LastIndex(line, prop.Value)] + strings.Join(kept, ",")}
+	}, nil)
+}
+
+// rewrites the content lines of a component, its own properties through one function and its nested components
+// through another, a nil function keeping the lines as they are. the BEGIN and END lines are always kept
+func (n *contentNode) rewrite(property func(line string) []string, component func(child *contentNode) ([]string, error)) ([]string, error) {
+
+	var lines []string
+	next := 0
+	for i := 0; i < len(n.lines); i++ {
+		line := n.lines[i]
+		if i == 0 || i == len(n.lines)-1 {
+			lines = append(lines, line)
+		} else if contentLineName(line) == "BEGIN" && next < len(n.children) {
+			child := n.children[next]
+			if component == nil {
+				lines = append(lines, child.lines...)
+			} else if replaced, err := component(child); err != nil {
+				return nil, err
+			} else {
+				lines = append(lines, replaced...)
+			}
+			i += len(child.lines) - 1
+			next++
+		} else if property == nil {
+			lines = append(lines, line)
+		} else {
+			lines = append(lines, property(line)...)
+		}
+	}
+
+	return lines, nil
+
+}
+
+// returns the name of the property held by an unfolded content line, in upper case
+func contentLineName(line string) string {
+	return strings.ToUpper(line[:strings.IndexAny(line+":", ":;")])
+}
+
+// leaves out the components and properties of calendar data that the specifier does not ask for
+func (c *Component) prune(data string) string {
+
+	type level struct {
+		// the specifier of the component, nil when all its properties and components are kept
+		spec *Component
+		keep bool
+	}
+
+	var kept []string
+	var stack []level
+
+	for _, line := range unfoldLines(data) {
+		name := contentLineName(line)
+		value := line[strings.Index(line+":", ":")+1:]
+		if name == "BEGIN" {
+			next := level{spec: c, keep: strings.EqualFold(value, string(c.Name))}
+			if len(stack) > 0 {
+				if parent := stack[len(stack)-1]; parent.keep {
+					next.spec, next.keep = parent.spec.child(value)
+				} else {
+					next = level{}
+				}
+			}
+			stack = append(stack, next)
+			if next.keep {
+				kept = append(kept, line)
+			}
+		} else if name == "END" {
+			if len(stack) > 0 && stack[len(stack)-1].keep {
+				kept = append(kept, line)
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		} else if len(stack) > 0 && stack[len(stack)-1].keep && stack[len(stack)-1].spec.includes(name) {
+			kept = append(kept, line)
+		}
+	}
+
+	return strings.Join(kept, icalendar.Newline) + icalendar.Newline
+
+}
+
+// finds the specifier of a nested component, nil when all of it is kept, reporting whether it is kept at all
+func (c *Component) child(name string) (*Component, bool) {
+	if c == nil || c.AllComponents != nil {
+		return nil, true
+	}
+	for _, comp := range c.Components {
+		if strings.EqualFold(string(comp.Name), name) {
+			return comp, true
+		}
+	}
+	return nil, false
+}
+
+// checks if the specifier keeps a property, a nil specifier keeping all of them
+func (c *Component) includes(name string) bool {
+	if c == nil || c.AllProperties != nil {
+		return true
+	}
+	for _, p := range c.Properties {
+		if strings.EqualFold(p.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package entities
+
+import (
+	"encoding/xml"
+)
+
+// a CalDAV request for a set of calendar objects by their hrefs, see RFC 4791 section 7.9
+type CalendarMultiget struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav calendar-multiget"`
+	Prop    *Prop    `xml:",omitempty"`
+	Hrefs   []string `xml:"DAV: href"`
+}
+
+// creates a new CalDAV multiget request for the entity tags and calendar data of the provided hrefs
+func NewCalendarMultiget(hrefs ...string) *CalendarMultiget {
+	multiget := &CalendarMultiget{Hrefs: hrefs}
+	multiget.Prop = NewPropNames(xml.Name{Space: "DAV:", Local: "getetag"})
+	multiget.Prop.CalendarData = new(CalendarData)
+	return multiget
+}
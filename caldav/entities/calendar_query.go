@@ -16,8 +16,9 @@ type CalendarQuery struct {
 	Filter  *Filter           `xml:",omitempty"`
 }
 
-// creates a new CalDAV query for iCalendar events from a particular time range
-func NewEventRangeQuery(start, end time.Time) (*CalendarQuery, error) {
+// creates a new CalDAV query for iCalendar events from a particular time range.
+// recurring events are expanded into their instances within the range, unless another recurrence mode is given
+func NewEventRangeQuery(start, end time.Time, mode ...RecurrenceMode) (*CalendarQuery, error) {
 
 	var err error
 	var dtstart, dtend *values.DateTime
@@ -34,10 +35,14 @@ func NewEventRangeQuery(start, end time.Time) (*CalendarQuery, error) {
 	query.Prop = new(Prop)
 	query.Prop.CalendarData = new(CalendarData)
 
-	// expand recurring events
-	query.Prop.CalendarData.ExpandRecurrenceSet = new(ExpandRecurrenceSet)
-	query.Prop.CalendarData.ExpandRecurrenceSet.StartTime = dtstart
-	query.Prop.CalendarData.ExpandRecurrenceSet.EndTime = dtend
+	// expand recurring events, or return them as asked
+	recurrences := ExpandRecurrences
+	if len(mode) > 0 {
+		recurrences = mode[0]
+	}
+	if err := query.Prop.CalendarData.SetRecurrenceMode(recurrences, start, end); err != nil {
+		return nil, utils.NewError(NewEventRangeQuery, "unable to set recurrence mode", mode, err)
+	}
 
 	// filter down calendar data to only iCalendar data
 	query.Filter = new(Filter)
@@ -45,13 +50,14 @@ func NewEventRangeQuery(start, end time.Time) (*CalendarQuery, error) {
 	query.Filter.ComponentFilter.Name = values.CalendarComponentName
 
 	// filter down iCalendar data to only events
-	query.Filter.ComponentFilter.ComponentFilter = new(ComponentFilter)
-	query.Filter.ComponentFilter.ComponentFilter.Name = values.EventComponentName
+	events := new(ComponentFilter)
+	events.Name = values.EventComponentName
+	query.Filter.ComponentFilter.ComponentFilters = []*ComponentFilter{events}
 
 	// filter down the events to only those that fall within the time range
-	query.Filter.ComponentFilter.ComponentFilter.TimeRange = new(TimeRange)
-	query.Filter.ComponentFilter.ComponentFilter.TimeRange.StartTime = dtstart
-	query.Filter.ComponentFilter.ComponentFilter.TimeRange.EndTime = dtend
+	events.TimeRange = new(TimeRange)
+	events.TimeRange.StartTime = dtstart
+	events.TimeRange.EndTime = dtend
 
 	// return the event query
 	return query, nil
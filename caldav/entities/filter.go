@@ -6,57 +6,78 @@ import (
 	"github.com/dolanor/caldav-go/icalendar/properties"
 )
 
-// a CalDAV query filter entity
+// a CalDAV query filter entity, holding a single VCALENDAR component filter
 type Filter struct {
 	XMLName         xml.Name         `xml:"urn:ietf:params:xml:ns:caldav filter"`
-	ComponentFilter *ComponentFilter `xml:",omitempty"`
+	ComponentFilter *ComponentFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter,omitempty"`
 }
 
-// used to filter down calendar components, such as VCALENDAR > VEVENT
+// used to filter down calendar components, such as VCALENDAR > VEVENT.
+// a component matches when it is defined and all of the conditions match, or when it is not defined and
+// IsNotDefined is set, in which case no other condition may be given
 type ComponentFilter struct {
-	XMLName         xml.Name             `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
-	Name            values.ComponentName `xml:"name,attr"`
-	ComponentFilter *ComponentFilter     `xml:",omitempty"`
-	TimeRange       *TimeRange           `xml:",omitempty"`
-	PropertyFilter  *PropertyFilter      `xml:",omitempty"`
-	ParameterFilter *ParameterFilter     `xml:",omitempty"`
+	XMLName          xml.Name             `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	Name             values.ComponentName `xml:"name,attr"`
+	IsNotDefined     *IsNotDefined        `xml:"urn:ietf:params:xml:ns:caldav is-not-defined,omitempty"`
+	TimeRange        *TimeRange           `xml:"urn:ietf:params:xml:ns:caldav time-range,omitempty"`
+	PropertyFilters  []*PropertyFilter    `xml:"urn:ietf:params:xml:ns:caldav prop-filter,omitempty"`
+	ComponentFilters []*ComponentFilter   `xml:"urn:ietf:params:xml:ns:caldav comp-filter,omitempty"`
 }
 
-// used to restrict component filters to a particular time range
+// used to restrict component or property filters to a particular time range. either bound may be omitted
 type TimeRange struct {
 	XMLName   xml.Name         `xml:"urn:ietf:params:xml:ns:caldav time-range"`
-	StartTime *values.DateTime `xml:"start,attr"`
-	EndTime   *values.DateTime `xml:"end,attr"`
+	StartTime *values.DateTime `xml:"start,attr,omitempty"`
+	EndTime   *values.DateTime `xml:"end,attr,omitempty"`
 }
 
-// used to restrict component filters to a property value
+// used to restrict component filters to a property value.
+// a property filter without any condition matches components defining the property
 type PropertyFilter struct {
-	XMLName         xml.Name                `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
-	Name            properties.PropertyName `xml:"name,attr"`
-	TextMatch       *TextMatch              `xml:",omitempty"`
-	ParameterFilter *ParameterFilter        `xml:",omitempty"`
+	XMLName          xml.Name                `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+	Name             properties.PropertyName `xml:"name,attr"`
+	IsNotDefined     *IsNotDefined           `xml:"urn:ietf:params:xml:ns:caldav is-not-defined,omitempty"`
+	TimeRange        *TimeRange              `xml:"urn:ietf:params:xml:ns:caldav time-range,omitempty"`
+	TextMatch        *TextMatch              `xml:"urn:ietf:params:xml:ns:caldav text-match,omitempty"`
+	ParameterFilters []*ParameterFilter      `xml:"urn:ietf:params:xml:ns:caldav param-filter,omitempty"`
 }
 
-// used to restrict component filters to a parameter value
+// used to restrict property filters to a parameter value
 type ParameterFilter struct {
-	XMLName   xml.Name                 `xml:"urn:ietf:params:xml:ns:caldav param-filter"`
-	Name      properties.ParameterName `xml:"name,attr"`
-	TextMatch *TextMatch               `xml:",omitempty"`
+	XMLName      xml.Name                 `xml:"urn:ietf:params:xml:ns:caldav param-filter"`
+	Name         properties.ParameterName `xml:"name,attr"`
+	IsNotDefined *IsNotDefined            `xml:"urn:ietf:params:xml:ns:caldav is-not-defined,omitempty"`
+	TextMatch    *TextMatch               `xml:"urn:ietf:params:xml:ns:caldav text-match,omitempty"`
 }
 
-// used to match properties by text value
+// used to match properties or parameters whose value contains a substring.
+// the collation defaults to i;ascii-casemap when omitted
 type TextMatch struct {
 	XMLName         xml.Name             `xml:"urn:ietf:params:xml:ns:caldav text-match"`
 	Collation       values.TextCollation `xml:"collation,attr,omitempty"`
-	NegateCondition values.HumanBoolean  `xml:"attr,negate-condition,omitempty"`
-	Content         string               `xml:",innerxml"`
+	NegateCondition values.HumanBoolean  `xml:"negate-condition,attr,omitempty"`
+	Content         string               `xml:",chardata"`
+}
+
+// used to match components, properties or parameters that are not defined
+type IsNotDefined struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+}
+
+// checks if the match is negated
+func (t *TextMatch) Negated() bool {
+	return t.NegateCondition == values.YesHumanBoolean
+}
+
+// returns the collation of the match, defaulting to i;ascii-casemap
+func (t *TextMatch) EffectiveCollation() values.TextCollation {
+	if t.Collation == "" {
+		return values.ASCIICaseMapCollation
+	}
+	return t.Collation
 }
 
 // creates a new CalDAV property value matcher
 func NewPropertyMatcher(name properties.PropertyName, content string) *PropertyFilter {
-	pf := new(PropertyFilter)
-	pf.Name = name
-	pf.TextMatch = new(TextMatch)
-	pf.TextMatch.Content = content
-	return pf
+	return NewPropertyFilter(name).Contains(content)
 }
@@ -0,0 +1,150 @@
+package entities
+
+import (
+	"github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/icalendar/properties"
+	"time"
+)
+
+// a condition placed on a component, either a property filter or a nested component filter
+type ComponentCondition interface {
+	addTo(cf *ComponentFilter)
+}
+
+// creates a filter matching components of a type, that may be nested in a component filter with Where
+func NewComponentFilter(name values.ComponentName) *ComponentFilter {
+	return &ComponentFilter{Name: name}
+}
+
+// restricts the filter to components overlapping a time range, zero times leaving the range open ended
+func (cf *ComponentFilter) InRange(start, end time.Time) *ComponentFilter {
+	cf.IsNotDefined = nil
+	cf.TimeRange = newTimeRange(start, end)
+	return cf
+}
+
+// restricts the filter to components matching all of the conditions
+func (cf *ComponentFilter) Where(conditions ...ComponentCondition) *ComponentFilter {
+	cf.IsNotDefined = nil
+	for _, c := range conditions {
+		if c != nil {
+			c.addTo(cf)
+		}
+	}
+	return cf
+}
+
+// turns the filter into one matching components that are not defined, dropping any other condition
+func (cf *ComponentFilter) NotDefined() *ComponentFilter {
+	cf.IsNotDefined = new(IsNotDefined)
+	cf.TimeRange = nil
+	cf.PropertyFilters = nil
+	cf.ComponentFilters = nil
+	return cf
+}
+
+func (cf *ComponentFilter) addTo(parent *ComponentFilter) {
+	parent.ComponentFilters = append(parent.ComponentFilters, cf)
+}
+
+// creates a filter matching components defining a property, that may be further restricted to some values
+func NewPropertyFilter(name properties.PropertyName) *PropertyFilter {
+	return &PropertyFilter{Name: name}
+}
+
+// restricts the filter to properties whose value contains a substring, ignoring ASCII case by default
+func (pf *PropertyFilter) Contains(text string) *PropertyFilter {
+	pf.IsNotDefined, pf.TimeRange = nil, nil
+	pf.TextMatch = &TextMatch{Content: text}
+	return pf
+}
+
+// restricts the filter to properties whose value does not contain a substring
+func (pf *PropertyFilter) NotContains(text string) *PropertyFilter {
+	pf.Contains(text)
+	pf.TextMatch.NegateCondition = values.YesHumanBoolean
+	return pf
+}
+
+// sets the collation used to match the text of the property, such as i;octet for a case sensitive match
+func (pf *PropertyFilter) Collation(collation values.TextCollation) *PropertyFilter {
+	if pf.TextMatch != nil {
+		pf.TextMatch.Collation = collation
+	}
+	return pf
+}
+
+// restricts the filter to date and time properties within a time range, zero times leaving the range open ended
+func (pf *PropertyFilter) InRange(start, end time.Time) *PropertyFilter {
+	pf.IsNotDefined, pf.TextMatch = nil, nil
+	pf.TimeRange = newTimeRange(start, end)
+	return pf
+}
+
+// restricts the filter to properties whose parameters match all of the filters
+func (pf *PropertyFilter) WithParams(filters ...*ParameterFilter) *PropertyFilter {
+	pf.IsNotDefined = nil
+	pf.ParameterFilters = append(pf.ParameterFilters, filters...)
+	return pf
+}
+
+// turns the filter into one matching components that do not define the property, dropping any other condition
+func (pf *PropertyFilter) NotDefined() *PropertyFilter {
+	pf.IsNotDefined = new(IsNotDefined)
+	pf.TimeRange = nil
+	pf.TextMatch = nil
+	pf.ParameterFilters = nil
+	return pf
+}
+
+func (pf *PropertyFilter) addTo(parent *ComponentFilter) {
+	parent.PropertyFilters = append(parent.PropertyFilters, pf)
+}
+
+// creates a filter matching properties defining a parameter, that may be further restricted to some values
+func NewParameterFilter(name properties.ParameterName) *ParameterFilter {
+	return &ParameterFilter{Name: name}
+}
+
+// restricts the filter to parameters whose value contains a substring, ignoring ASCII case by default
+func (pf *ParameterFilter) Contains(text string) *ParameterFilter {
+	pf.IsNotDefined = nil
+	pf.TextMatch = &TextMatch{Content: text}
+	return pf
+}
+
+// restricts the filter to parameters whose value does not contain a substring
+func (pf *ParameterFilter) NotContains(text string) *ParameterFilter {
+	pf.Contains(text)
+	pf.TextMatch.NegateCondition = values.YesHumanBoolean
+	return pf
+}
+
+// sets the collation used to match the text of the parameter, such as i;octet for a case sensitive match
+func (pf *ParameterFilter) Collation(collation values.TextCollation) *ParameterFilter {
+	if pf.TextMatch != nil {
+		pf.TextMatch.Collation = collation
+	}
+	return pf
+}
+
+// turns the filter into one matching properties that do not define the parameter
+func (pf *ParameterFilter) NotDefined() *ParameterFilter {
+	pf.IsNotDefined = new(IsNotDefined)
+	pf.TextMatch = nil
+	return pf
+}
+
+// creates a time range from native times, zero times leaving the range open ended
+func newTimeRange(start, end time.Time) *TimeRange {
+	return &TimeRange{StartTime: newDateTime("start", start), EndTime: newDateTime("end", end)}
+}
+
+// creates a CalDAV date-time attribute from any time, converted to UTC, or nil for zero times
+func newDateTime(name string, t time.Time) *values.DateTime {
+	if t.IsZero() {
+		return nil
+	}
+	dt, _ := values.NewDateTime(name, t.UTC())
+	return dt
+}
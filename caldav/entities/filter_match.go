@@ -0,0 +1,624 @@
+package entities
+
+import (
+	"fmt"
+	"github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/icalendar"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/icalendar/properties"
+	ivalues "github.com/dolanor/caldav-go/icalendar/values"
+	"github.com/dolanor/caldav-go/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// how far ahead of its start a time range without end looks for the instances of recurring events
+const openTimeRangeHorizon = 10
+
+// a component of raw calendar data as seen by filters, nested components included
+type contentNode struct {
+	name       string
+	properties []*properties.Property
+	children   []*contentNode
+	parent     *contentNode
+	// the unfolded content lines of the component, from its BEGIN line to its END line
+	lines []string
+	// the typed value of events and free/busy components, decoded when a time range needs it
+	event    *components.Event
+	freeBusy *components.FreeBusy
+}
+
+// checks if a calendar matches the filter, once encoded. see MatchesData for the rules of the match.
+// components the typed calendar does not hold, such as to-dos, cannot be matched through this method
+func (f *Filter) Matches(cal *components.Calendar) (bool, error) {
+
+	if f.ComponentFilter == nil {
+		return true, nil
+	} else if cal == nil {
+		return false, nil
+	}
+
+	if encoded, err := icalendar.Marshal(cal); err != nil {
+		return false, utils.NewError(f.Matches, "unable to encode calendar", cal, err)
+	} else {
+		return f.MatchesData(encoded)
+	}
+
+}
+
+// checks if raw calendar data matches the filter, following the rules of RFC 4791 section 9.7.
+// recurring events match a time range when any of their instances overlaps it, instances replaced by an override
+// being matched against the override. dates and floating times are interpreted in UTC.
+// returns an error if the data cannot be parsed, or if the filter asks for an unsupported collation or for a
+// time range on recurring to-dos or journals
+func (f *Filter) MatchesData(data string) (bool, error) {
+
+	if f.ComponentFilter == nil {
+		return true, nil
+	}
+
+	root, err := parseContentLines(data)
+	if err != nil {
+		return false, utils.NewError(f.MatchesData, "unable to parse calendar data", data, err)
+	}
+
+	if matched, err := f.ComponentFilter.matches([]*contentNode{root}); err != nil {
+		return false, utils.NewError(f.MatchesData, "unable to evaluate filter", f, err)
+	} else {
+		return matched, nil
+	}
+
+}
+
+// checks if any of the components the filter applies to matches it, or if none exists for is-not-defined filters
+func (cf *ComponentFilter) matches(nodes []*contentNode) (bool, error) {
+
+	var candidates []*contentNode
+	for _, n := range nodes {
+		if strings.EqualFold(n.name, string(cf.Name)) {
+			candidates = append(candidates, n)
+		}
+	}
+
+	if cf.IsNotDefined != nil {
+		return len(candidates) == 0, nil
+	}
+
+	for _, n := range candidates {
+		if matched, err := cf.matchesNode(n); err != nil || matched {
+			return matched, err
+		}
+	}
+
+	return false, nil
+
+}
+
+// checks if a component matches all the conditions of the filter
+func (cf *ComponentFilter) matchesNode(n *contentNode) (bool, error) {
+
+	if cf.TimeRange != nil {
+		if overlaps, err := n.overlaps(cf.TimeRange); err != nil || !overlaps {
+			return false, err
+		}
+	}
+
+	for _, pf := range cf.PropertyFilters {
+		if matched, err := pf.matches(n.properties); err != nil || !matched {
+			return false, err
+		}
+	}
+
+	for _, child := range cf.ComponentFilters {
+		if matched, err := child.matches(n.children); err != nil || !matched {
+			return false, err
+		}
+	}
+
+	return true, nil
+
+}
+
+// checks if any of the properties the filter applies to matches it, or if none exists for is-not-defined filters
+func (pf *PropertyFilter) matches(props []*properties.Property) (bool, error) {
+
+	var candidates []*properties.Property
+	for _, p := range props {
+		if normalizePropertyName(string(p.Name)) == normalizePropertyName(string(pf.Name)) {
+			candidates = append(candidates, p)
+		}
+	}
+
+	if pf.IsNotDefined != nil {
+		return len(candidates) == 0, nil
+	}
+
+	for _, p := range candidates {
+		if matched, err := pf.matchesProperty(p); err != nil || matched {
+			return matched, err
+		}
+	}
+
+	return false, nil
+
+}
+
+// checks if a property matches all the conditions of the filter
+func (pf *PropertyFilter) matchesProperty(p *properties.Property) (bool, error) {
+
+	if pf.TimeRange != nil && !propertyOverlaps(p, pf.TimeRange) {
+		return false, nil
+	}
+
+	if pf.TextMatch != nil {
+		if matched, err := pf.TextMatch.matches(p.Value); err != nil || !matched {
+			return false, err
+		}
+	}
+
+	for _, param := range pf.ParameterFilters {
+		if matched, err := param.matches(p.Params); err != nil || !matched {
+			return false, err
+		}
+	}
+
+	return true, nil
+
+}
+
+// checks if the parameter the filter applies to matches it, or if it is not defined for is-not-defined filters
+func (pf *ParameterFilter) matches(params properties.Params) (bool, error) {
+
+	value, found := lookupParam(params, pf.Name)
+	if pf.IsNotDefined != nil {
+		return !found, nil
+	} else if !found {
+		return false, nil
+	} else if pf.TextMatch != nil {
+		return pf.TextMatch.matches(value)
+	}
+
+	return true, nil
+
+}
+
+// checks if a value contains the text of the match, according to its collation and negation
+func (t *TextMatch) matches(value string) (bool, error) {
+
+	var contained bool
+	switch t.EffectiveCollation() {
+	case values.OctetTextCollation:
+		contained = strings.Contains(value, t.Content)
+	case values.ASCIICaseMapCollation:
+		contained = strings.Contains(asciiLower(value), asciiLower(t.Content))
+	default:
+		msg := fmt.Sprintf("unsupported collation %s", t.Collation)
+		return false, utils.NewError(t.matches, msg, t, nil)
+	}
+
+	return contained != t.Negated(), nil
+
+}
+
+// checks if a component overlaps a time range, following the rules of RFC 4791 section 9.9
+func (n *contentNode) overlaps(tr *TimeRange) (bool, error) {
+
+	start, end := tr.bounds()
+
+	switch values.ComponentName(n.name) {
+	case values.EventComponentName:
+		instances, err := n.eventInstances(start, end, tr.EndTime == nil)
+		return len(instances) > 0, err
+	case values.ToDoComponentName:
+		return n.todoOverlaps(start, end)
+	case values.JournalComponentName:
+		return n.journalOverlaps(start, end)
+	case values.FreeBusyComponentName:
+		return n.freeBusyOverlaps(start, end)
+	case values.AlarmComponentName:
+		return n.alarmOverlaps(start, end, tr.EndTime == nil)
+	default:
+		msg := fmt.Sprintf("unsupported time range on %s components", n.name)
+		return false, utils.NewError(n.overlaps, msg, n, nil)
+	}
+
+}
+
+// computes the instances of an event that overlap a range of time, the overrides of its recurrence set being taken
+// into account. ranges without end look for instances up to a few years after the start of the range or event
+func (n *contentNode) eventInstances(start, end time.Time, open bool) ([]*components.EventInstance, error) {
+
+	e, err := n.decodeEvent()
+	if err != nil || e.DateStart == nil {
+		return nil, err
+	} else if open {
+		end = laterOf(start, e.DateStart.In(time.UTC)).AddDate(openTimeRangeHorizon, 0, 0)
+	}
+
+	siblings := []*contentNode{n}
+	if n.parent != nil {
+		siblings = n.parent.children
+	}
+
+	var series []*components.Event
+	for _, sibling := range siblings {
+		if values.ComponentName(sibling.name) != values.EventComponentName {
+			continue
+		} else if se, err := sibling.decodeEvent(); err != nil {
+			return nil, err
+		} else if se.UID == e.UID {
+			series = append(series, se)
+		}
+	}
+
+	var instances []*components.EventInstance
+	for _, i := range components.ExpandEvents(series, start, end) {
+		if i.Event == e {
+			instances = append(instances, i)
+		}
+	}
+	return instances, nil
+
+}
+
+// checks if a to-do overlaps a range of time, using the table of RFC 4791 section 9.9
+func (n *contentNode) todoOverlaps(start, end time.Time) (bool, error) {
+
+	if n.recurs() {
+		return false, utils.NewError(n.todoOverlaps, "unsupported time range on recurring to-dos", n, nil)
+	}
+
+	dtstart, due := n.timeValue("DTSTART"), n.timeValue("DUE")
+	completed, created := n.timeValue("COMPLETED"), n.timeValue("CREATED")
+	if d := n.durationValue("DURATION"); dtstart != nil && d != nil {
+		due = timePointer(dtstart.Add(*d))
+	}
+
+	if dtstart != nil && due != nil {
+		return (start.Before(*due) || !start.After(*dtstart)) && (end.After(*dtstart) || !end.Before(*due)), nil
+	} else if dtstart != nil {
+		return !start.After(*dtstart) && end.After(*dtstart), nil
+	} else if due != nil {
+		return start.Before(*due) && !end.Before(*due), nil
+	} else if completed != nil && created != nil {
+		return (!start.After(*created) || !start.After(*completed)) && (!end.Before(*created) || !end.Before(*completed)), nil
+	} else if completed != nil {
+		return !start.After(*completed) && !end.Before(*completed), nil
+	} else if created != nil {
+		return end.After(*created), nil
+	}
+
+	return true, nil
+
+}
+
+// checks if a journal overlaps a range of time, journals starting on a date lasting the whole day
+func (n *contentNode) journalOverlaps(start, end time.Time) (bool, error) {
+
+	if n.recurs() {
+		return false, utils.NewError(n.journalOverlaps, "unsupported time range on recurring journals", n, nil)
+	}
+
+	dtstart := n.dateTimeValue("DTSTART")
+	if dtstart == nil {
+		return false, nil
+	}
+
+	t := dtstart.In(time.UTC)
+	if dtstart.IsDate() {
+		return start.Before(t.AddDate(0, 0, 1)) && end.After(t), nil
+	}
+	return !start.After(t) && end.After(t), nil
+
+}
+
+// checks if a free/busy component overlaps a range of time, through its bounds or else through its periods
+func (n *contentNode) freeBusyOverlaps(start, end time.Time) (bool, error) {
+
+	if n.freeBusy == nil {
+		n.freeBusy = new(components.FreeBusy)
+		if err := icalendar.Unmarshal(strings.Join(n.lines, icalendar.Newline), n.freeBusy); err != nil {
+			return false, utils.NewError(n.freeBusyOverlaps, "unable to decode free/busy component", n, err)
+		}
+	}
+
+	fb := n.freeBusy
+	if fb.DateStart != nil && fb.DateEnd != nil {
+		return !start.After(fb.DateEnd.In(time.UTC)) && end.After(fb.DateStart.In(time.UTC)), nil
+	}
+	for _, t := range fb.FreeBusyTimes {
+		for _, p := range t.Periods {
+			if p.Start().Before(end) && p.End().After(start) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+
+}
+
+// checks if an alarm triggers within a range of time, repetitions included. alarms of recurring events trigger for
+// each of their instances, while alarms of recurring to-dos are not supported
+func (n *contentNode) alarmOverlaps(start, end time.Time, open bool) (bool, error) {
+
+	trigger := n.property("TRIGGER")
+	if trigger == nil || n.parent == nil {
+		return false, nil
+	}
+
+	// the offsets of the repetitions from the first trigger
+	offsets := []time.Duration{0}
+	if repeat, err := strconv.Atoi(n.propertyValue("REPEAT")); err == nil {
+		if interval := n.durationValue("DURATION"); interval != nil {
+			for i := 1; i <= repeat; i++ {
+				offsets = append(offsets, time.Duration(i)*(*interval))
+			}
+		}
+	}
+	triggers := func(first time.Time) bool {
+		for _, offset := range offsets {
+			if t := first.Add(offset); !start.After(t) && end.After(t) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if value, _ := lookupParam(trigger.Params, "VALUE"); strings.EqualFold(value, "DATE-TIME") {
+		dt := new(ivalues.DateTime)
+		if err := dt.DecodeICalValue(trigger.Value); err != nil {
+			return false, nil
+		}
+		return triggers(dt.In(time.UTC)), nil
+	}
+
+	d := new(ivalues.Duration)
+	if err := d.DecodeICalValue(trigger.Value); err != nil {
+		return false, nil
+	}
+	related, _ := lookupParam(trigger.Params, "RELATED")
+	fromEnd := strings.EqualFold(related, "END")
+
+	switch values.ComponentName(n.parent.name) {
+	case values.EventComponentName:
+		// instances whose first trigger may fall within the range, the repetitions coming after it
+		last := offsets[len(offsets)-1]
+		from, to := start.Add(-d.NativeDuration()-last-time.Second), end.Add(-d.NativeDuration())
+		instances, err := n.parent.eventInstances(from, to, open)
+		if err != nil {
+			return false, err
+		}
+		for _, i := range instances {
+			if fromEnd && triggers(i.End.Add(d.NativeDuration())) {
+				return true, nil
+			} else if !fromEnd && triggers(i.Start.Add(d.NativeDuration())) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case values.ToDoComponentName:
+		if n.parent.recurs() {
+			return false, utils.NewError(n.alarmOverlaps, "unsupported time range on alarms of recurring to-dos", n, nil)
+		}
+		anchor := n.parent.timeValue("DTSTART")
+		if fromEnd {
+			anchor = n.parent.timeValue("DUE")
+			if dtstart, duration := n.parent.timeValue("DTSTART"), n.parent.durationValue("DURATION"); dtstart != nil && duration != nil {
+				anchor = timePointer(dtstart.Add(*duration))
+			}
+		}
+		return anchor != nil && triggers(anchor.Add(d.NativeDuration())), nil
+	default:
+		return false, nil
+	}
+
+}
+
+// decodes the typed event of a VEVENT component, once
+func (n *contentNode) decodeEvent() (*components.Event, error) {
+	if n.event == nil {
+		e := new(components.Event)
+		if err := icalendar.Unmarshal(strings.Join(n.lines, icalendar.Newline), e); err != nil {
+			return nil, utils.NewError(n.decodeEvent, "unable to decode event", n, err)
+		}
+		n.event = e
+	}
+	return n.event, nil
+}
+
+// checks if the component defines a recurrence set
+func (n *contentNode) recurs() bool {
+	return n.property("RRULE") != nil || n.property("RDATE") != nil
+}
+
+// returns the first property of the component with a given name, if any
+func (n *contentNode) property(name string) *properties.Property {
+	for _, p := range n.properties {
+		if normalizePropertyName(string(p.Name)) == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// returns the value of the first property of the component with a given name, or an empty string
+func (n *contentNode) propertyValue(name string) string {
+	if p := n.property(name); p != nil {
+		return p.Value
+	}
+	return ""
+}
+
+// decodes the date or date-time value of a property, ignoring missing or invalid values
+func (n *contentNode) dateTimeValue(name string) *ivalues.DateTime {
+	p := n.property(name)
+	if p == nil {
+		return nil
+	}
+	dt := new(ivalues.DateTime)
+	if err := dt.DecodeICalValue(p.Value); err != nil {
+		return nil
+	} else if err := dt.DecodeICalParams(p.Params); err != nil {
+		return nil
+	}
+	return dt
+}
+
+// decodes the value of a date or date-time property as a time in UTC, ignoring missing or invalid values
+func (n *contentNode) timeValue(name string) *time.Time {
+	if dt := n.dateTimeValue(name); dt != nil {
+		return timePointer(dt.In(time.UTC))
+	}
+	return nil
+}
+
+// decodes the value of a duration property, ignoring missing or invalid values
+func (n *contentNode) durationValue(name string) *time.Duration {
+	p := n.property(name)
+	if p == nil {
+		return nil
+	}
+	d := new(ivalues.Duration)
+	if err := d.DecodeICalValue(p.Value); err != nil {
+		return nil
+	}
+	native := d.NativeDuration()
+	return &native
+}
+
+// checks if any of the date or date-time values of a property overlaps a time range.
+// dates last a whole day, while date-times are instants
+func propertyOverlaps(p *properties.Property, tr *TimeRange) bool {
+
+	start, end := tr.bounds()
+
+	for _, value := range strings.Split(p.Value, ",") {
+		// periods are matched by their start
+		value = strings.SplitN(value, "/", 2)[0]
+		dt := new(ivalues.DateTime)
+		if err := dt.DecodeICalValue(value); err != nil {
+			continue
+		} else if err := dt.DecodeICalParams(p.Params); err != nil {
+			continue
+		}
+		t := dt.In(time.UTC)
+		if dt.IsDate() && start.Before(t.AddDate(0, 0, 1)) && end.After(t) {
+			return true
+		} else if !dt.IsDate() && !start.After(t) && end.After(t) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// returns the bounds of the time range, missing bounds being left open
+func (tr *TimeRange) bounds() (start, end time.Time) {
+	if tr.StartTime != nil {
+		start = tr.StartTime.NativeTime()
+	}
+	if tr.EndTime != nil {
+		end = tr.EndTime.NativeTime()
+	} else {
+		end = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+	}
+	return
+}
+
+// parses raw calendar data into its tree of components, unfolding its content lines
+func parseContentLines(data string) (*contentNode, error) {
+
+	lines := unfoldLines(data)
+
+	var root *contentNode
+	var stack []*contentNode
+	var begins []int
+	for i, line := range lines {
+		prop := properties.UnmarshalProperty(line)
+		if prop.Name.Equals("begin") {
+			n := &contentNode{name: strings.ToUpper(prop.Value)}
+			if len(stack) > 0 {
+				n.parent = stack[len(stack)-1]
+				n.parent.children = append(n.parent.children, n)
+			} else if root == nil {
+				root = n
+			} else {
+				return nil, utils.NewError(parseContentLines, "data holds more than one component", data, nil)
+			}
+			stack, begins = append(stack, n), append(begins, i)
+		} else if prop.Name.Equals("end") {
+			if len(stack) == 0 || !strings.EqualFold(stack[len(stack)-1].name, prop.Value) {
+				msg := fmt.Sprintf("unexpected end of %s component", prop.Value)
+				return nil, utils.NewError(parseContentLines, msg, data, nil)
+			}
+			stack[len(stack)-1].lines = lines[begins[len(begins)-1] : i+1]
+			stack, begins = stack[:len(stack)-1], begins[:len(begins)-1]
+		} else if len(stack) > 0 {
+			n := stack[len(stack)-1]
+			n.properties = append(n.properties, prop)
+		}
+	}
+
+	if root == nil {
+		return nil, utils.NewError(parseContentLines, "data holds no component", data, nil)
+	} else if len(stack) > 0 {
+		msg := fmt.Sprintf("%s component is not ended", stack[len(stack)-1].name)
+		return nil, utils.NewError(parseContentLines, msg, data, nil)
+	}
+
+	return root, nil
+
+}
+
+// splits raw data into its content lines, joining the lines folded with a leading space or tab
+func unfoldLines(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.Replace(data, "\r\n", "\n", -1), "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+		} else if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// returns the value of a parameter, looked up regardless of the case of its name
+func lookupParam(params properties.Params, name properties.ParameterName) (string, bool) {
+	for n, v := range params {
+		if strings.EqualFold(string(n), string(name)) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// normalizes a property name, as decoded property names use underscores instead of dashes
+func normalizePropertyName(name string) string {
+	return strings.ToUpper(strings.Replace(name, "_", "-", -1))
+}
+
+// lowers the case of ASCII letters only, as the i;ascii-casemap collation does
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + ('a' - 'A')
+		}
+	}
+	return string(b)
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func timePointer(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"encoding/xml"
+	"github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/utils"
+	"time"
+)
+
+// a CalDAV free/busy query object, see RFC 4791 section 7.10
+type FreeBusyQuery struct {
+	XMLName   xml.Name   `xml:"urn:ietf:params:xml:ns:caldav free-busy-query"`
+	TimeRange *TimeRange `xml:",omitempty"`
+}
+
+// creates a new CalDAV query for the free/busy time of a particular time range
+func NewFreeBusyQuery(start, end time.Time) (*FreeBusyQuery, error) {
+
+	var err error
+	var dtstart, dtend *values.DateTime
+	if dtstart, err = values.NewDateTime("start", start); err != nil {
+		return nil, utils.NewError(NewFreeBusyQuery, "unable to encode start time", start, err)
+	} else if dtend, err = values.NewDateTime("end", end); err != nil {
+		return nil, utils.NewError(NewFreeBusyQuery, "unable to encode end time", end, err)
+	}
+
+	query := new(FreeBusyQuery)
+	query.TimeRange = new(TimeRange)
+	query.TimeRange.StartTime = dtstart
+	query.TimeRange.EndTime = dtend
+
+	return query, nil
+
+}
@@ -0,0 +1,62 @@
+package entities
+
+import (
+	"encoding/xml"
+	"github.com/dolanor/caldav-go/webdav/entities"
+	"strings"
+)
+
+// a set of properties to apply to a resource
+type Set = entities.Set
+
+// a request to create a calendar collection with initial properties, see RFC 4791 section 5.3.1
+type MakeCalendar struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav mkcalendar"`
+	Set     *Set     `xml:",omitempty"`
+}
+
+// a request to create a collection of a given resource type with initial properties, see RFC 5689
+type MakeCollection struct {
+	XMLName xml.Name `xml:"DAV: mkcol"`
+	Set     *Set     `xml:",omitempty"`
+}
+
+// the outcome of setting the initial properties of a new calendar collection
+type MakeCalendarResponse struct {
+	XMLName   xml.Name                  `xml:"urn:ietf:params:xml:ns:caldav mkcalendar-response"`
+	PropStats []*entities.NamedPropStat `xml:"DAV: propstat,omitempty"`
+}
+
+// the outcome of setting the initial properties of a new collection
+type MakeCollectionResponse struct {
+	XMLName   xml.Name                  `xml:"DAV: mkcol-response"`
+	PropStats []*entities.NamedPropStat `xml:"DAV: propstat,omitempty"`
+}
+
+// creates a new MKCALENDAR request body that sets the provided properties
+func NewMakeCalendar(prop *Prop) *MakeCalendar {
+	return &MakeCalendar{Set: &Set{Prop: prop}}
+}
+
+// creates a new extended MKCOL request body that sets the provided properties
+func NewMakeCollection(prop *Prop) *MakeCollection {
+	return &MakeCollection{Set: &Set{Prop: prop}}
+}
+
+func (r *MakeCalendarResponse) Error() string {
+	return failedPropStats(r.PropStats)
+}
+
+func (r *MakeCollectionResponse) Error() string {
+	return failedPropStats(r.PropStats)
+}
+
+func failedPropStats(propstats []*entities.NamedPropStat) string {
+	var failed []string
+	for _, ps := range propstats {
+		if !ps.Status.OK() {
+			failed = append(failed, ps.String())
+		}
+	}
+	return strings.Join(failed, "; ")
+}
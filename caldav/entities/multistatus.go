@@ -1,23 +1,39 @@
 package entities
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"github.com/dolanor/caldav-go/webdav/entities"
+)
 
 // metadata about a property
 type PropStat struct {
-	XMLName xml.Name `xml:"propstat"`
-	Status  string   `xml:"status"`
-	Prop    *Prop    `xml:",omitempty"`
+	XMLName             xml.Name        `xml:"propstat"`
+	Status              entities.Status `xml:"status"`
+	Prop                *Prop           `xml:",omitempty"`
+	Error               *entities.Error `xml:",omitempty"`
+	ResponseDescription string          `xml:"responsedescription,omitempty"`
 }
 
 // a multistatus response entity
 type Response struct {
-	XMLName   xml.Name    `xml:"response"`
-	Href      string      `xml:"href"`
-	PropStats []*PropStat `xml:"propstat,omitempty"`
+	XMLName             xml.Name           `xml:"response"`
+	Href                string             `xml:"href"`
+	Status              *entities.Status   `xml:"status,omitempty"`
+	PropStats           []*PropStat        `xml:"propstat,omitempty"`
+	Error               *entities.Error    `xml:",omitempty"`
+	ResponseDescription string             `xml:"responsedescription,omitempty"`
+	Location            *entities.Location `xml:"location,omitempty"`
+}
+
+// checks if the server reported a failure for the resource as a whole
+func (r *Response) Failed() bool {
+	return r.Status != nil && !r.Status.OK()
 }
 
 // a request to find properties on an an entity or collection
 type Multistatus struct {
-	XMLName   xml.Name    `xml:"DAV: multistatus"`
-	Responses []*Response `xml:"response,omitempty"`
+	XMLName             xml.Name    `xml:"DAV: multistatus"`
+	Responses           []*Response `xml:"response,omitempty"`
+	ResponseDescription string      `xml:"responsedescription,omitempty"`
+	SyncToken           string      `xml:"DAV: sync-token,omitempty"`
 }
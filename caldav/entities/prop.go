@@ -7,13 +7,40 @@ import (
 
 // a CalDAV Property resource
 type Prop struct {
-	XMLName        xml.Name               `xml:"DAV: prop"`
-	GetContentType string                 `xml:"getcontenttype,omitempty"`
-	DisplayName    string                 `xml:"displayname,omitempty"`
-	CalendarData   *CalendarData          `xml:",omitempty"`
-	ResourceType   *entities.ResourceType `xml:",omitempty"`
-	CTag           string                 `xml:"http://calendarserver.org/ns/ getctag,omitempty"`
-	ETag           string                 `xml:"http://calendarserver.org/ns/ getetag,omitempty"`
+	XMLName                       xml.Name                       `xml:"DAV: prop"`
+	GetContentType                string                         `xml:"getcontenttype,omitempty"`
+	DisplayName                   string                         `xml:"displayname,omitempty"`
+	CalendarData                  *CalendarData                  `xml:",omitempty"`
+	ResourceType                  *entities.ResourceType         `xml:",omitempty"`
+	CurrentUserPrincipal          *entities.CurrentUserPrincipal `xml:",omitempty"`
+	CalendarHomeSet               *CalendarHomeSet               `xml:",omitempty"`
+	ScheduleInboxUrl              *ScheduleInboxUrl              `xml:",omitempty"`
+	ScheduleOutboxUrl             *ScheduleOutboxUrl             `xml:",omitempty"`
+	CalendarUserAddressSet        *CalendarUserAddressSet        `xml:",omitempty"`
+	CalendarDescription           string                         `xml:"urn:ietf:params:xml:ns:caldav calendar-description,omitempty"`
+	CalendarTimeZone              string                         `xml:"urn:ietf:params:xml:ns:caldav calendar-timezone,omitempty"`
+	SupportedCalendarComponentSet *SupportedCalendarComponentSet `xml:",omitempty"`
+	MaxResourceSize               int64                          `xml:"urn:ietf:params:xml:ns:caldav max-resource-size,omitempty"`
+	CalendarColor                 string                         `xml:"http://apple.com/ns/ical/ calendar-color,omitempty"`
+	SyncToken                     string                         `xml:"sync-token,omitempty"`
+	CTag                          string                         `xml:"http://calendarserver.org/ns/ getctag,omitempty"`
+	ETag                          string                         `xml:"http://calendarserver.org/ns/ getetag,omitempty"`
+	GetETag                       string                         `xml:"DAV: getetag,omitempty"`
+	Extra                         []*entities.Property           `xml:",any"`
+}
+
+// creates a property list with empty properties of the given names, as used to request them
+func NewPropNames(names ...xml.Name) *Prop {
+	p := new(Prop)
+	for _, name := range names {
+		p.Extra = append(p.Extra, entities.NewProperty(name))
+	}
+	return p
+}
+
+// returns a property without a dedicated field by name, or nil if it is not present
+func (p *Prop) Property(name xml.Name) *entities.Property {
+	return entities.FindProperty(p.Extra, name)
 }
 
 // used to restrict properties returned in calendar data
@@ -21,3 +48,15 @@ type PropertyName struct {
 	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav prop"`
 	Name    string   `xml:"name,attr"`
 }
+
+// identifies the collections that contain the calendars owned by a principal
+type CalendarHomeSet struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav calendar-home-set"`
+	Hrefs   []string `xml:"DAV: href,omitempty"`
+}
+
+// the calendar component types that a calendar collection accepts
+type SupportedCalendarComponentSet struct {
+	XMLName    xml.Name     `xml:"urn:ietf:params:xml:ns:caldav supported-calendar-component-set"`
+	Components []*Component `xml:"urn:ietf:params:xml:ns:caldav comp,omitempty"`
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"encoding/xml"
+	"github.com/dolanor/caldav-go/webdav/entities"
+)
+
+// a request to find CalDAV properties on an an entity or collection
+type Propfind struct {
+	XMLName  xml.Name           `xml:"DAV: propfind"`
+	PropName *entities.PropName `xml:",omitempty"`
+	AllProp  *entities.AllProp  `xml:",omitempty"`
+	Include  *entities.Include  `xml:",omitempty"`
+	Props    []*Prop            `xml:"prop,omitempty"`
+}
+
+// a convenience method for searching a set of CalDAV properties
+func NewPropFind(prop *Prop) *Propfind {
+	return &Propfind{Props: []*Prop{prop}}
+}
+
+// a convenience method for searching a set of properties by name
+func NewNamedPropFind(names ...xml.Name) *Propfind {
+	return NewPropFind(NewPropNames(names...))
+}
+
+// a convenience method for searching all properties
+// optionally includes properties that are not returned by default
+func NewAllPropsFind(include ...xml.Name) *Propfind {
+	pf := &Propfind{AllProp: new(entities.AllProp)}
+	if len(include) > 0 {
+		pf.Include = entities.NewInclude(include...)
+	}
+	return pf
+}
+
+// a convenience method for listing the names of all properties
+func NewPropNameFind() *Propfind {
+	return &Propfind{PropName: new(entities.PropName)}
+}
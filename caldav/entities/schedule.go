@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"encoding/xml"
+	"github.com/dolanor/caldav-go/webdav/entities"
+	"strings"
+)
+
+// identifies the collection that receives the scheduling messages addressed to a principal, see RFC 6638
+type ScheduleInboxUrl struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav schedule-inbox-URL"`
+	Href    string   `xml:"DAV: href,omitempty"`
+}
+
+// identifies the collection scheduling messages of a principal are submitted to, see RFC 6638
+type ScheduleOutboxUrl struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav schedule-outbox-URL"`
+	Href    string   `xml:"DAV: href,omitempty"`
+}
+
+// identifies the calendar user addresses of a principal, such as mailto: URIs, see RFC 6638
+type CalendarUserAddressSet struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav calendar-user-address-set"`
+	Hrefs   []string `xml:"DAV: href,omitempty"`
+}
+
+// the response to a scheduling message submitted to an outbox, see RFC 6638 section 10.1
+type ScheduleResponse struct {
+	XMLName   xml.Name                     `xml:"urn:ietf:params:xml:ns:caldav schedule-response"`
+	Responses []*ScheduleRecipientResponse `xml:"urn:ietf:params:xml:ns:caldav response,omitempty"`
+}
+
+// the outcome of a scheduling message for one of its recipients
+type ScheduleRecipientResponse struct {
+	XMLName             xml.Name        `xml:"urn:ietf:params:xml:ns:caldav response"`
+	Recipient           *Recipient      `xml:",omitempty"`
+	RequestStatus       string          `xml:"urn:ietf:params:xml:ns:caldav request-status"`
+	CalendarData        *CalendarData   `xml:",omitempty"`
+	Error               *entities.Error `xml:",omitempty"`
+	ResponseDescription string          `xml:"DAV: responsedescription,omitempty"`
+}
+
+// the calendar user address a scheduling message was delivered to
+type Recipient struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav recipient"`
+	Href    string   `xml:"DAV: href"`
+}
+
+// returns the status code of the request status, such as "2.0"
+func (r *ScheduleRecipientResponse) StatusCode() string {
+	return strings.TrimSpace(strings.SplitN(r.RequestStatus, ";", 2)[0])
+}
+
+// checks if the message was delivered or is pending delivery, that is if the status code is of class 1 or 2
+func (r *ScheduleRecipientResponse) Delivered() bool {
+	code := r.StatusCode()
+	return strings.HasPrefix(code, "1.") || strings.HasPrefix(code, "2.")
+}
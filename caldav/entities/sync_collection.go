@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"encoding/xml"
+)
+
+// a request for the changes made to a collection since a synchronization token, see RFC 6578 section 6.1.
+// an empty token asks for all the members of the collection
+type SyncCollection struct {
+	XMLName   xml.Name `xml:"DAV: sync-collection"`
+	SyncToken string   `xml:"DAV: sync-token"`
+	SyncLevel string   `xml:"DAV: sync-level"`
+	Limit     *Limit   `xml:",omitempty"`
+	Prop      *Prop    `xml:",omitempty"`
+}
+
+// the maximum number of results a client accepts, see RFC 5323 section 5.17
+type Limit struct {
+	XMLName  xml.Name `xml:"DAV: limit"`
+	NResults int      `xml:"DAV: nresults"`
+}
+
+// creates a new synchronization request for the entity tags and calendar data of the members of a collection
+func NewSyncCollection(token string) *SyncCollection {
+	sync := &SyncCollection{SyncToken: token, SyncLevel: "1"}
+	sync.Prop = NewPropNames(xml.Name{Space: "DAV:", Local: "getetag"})
+	sync.Prop.CalendarData = new(CalendarData)
+	return sync
+}
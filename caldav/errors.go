@@ -0,0 +1,12 @@
+package caldav
+
+import "github.com/dolanor/caldav-go/webdav"
+
+// sentinel errors matched by errors.Is against the status of a failed response, see the http package
+var (
+	ErrUnauthorized       = webdav.ErrUnauthorized
+	ErrForbidden          = webdav.ErrForbidden
+	ErrNotFound           = webdav.ErrNotFound
+	ErrConflict           = webdav.ErrConflict
+	ErrPreconditionFailed = webdav.ErrPreconditionFailed
+)
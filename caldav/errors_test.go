@@ -0,0 +1,134 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	chttp "github.com/dolanor/caldav-go/http"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/webdav"
+	. "gopkg.in/check.v1"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ErrorsSuite struct{}
+
+var _ = Suite(new(ErrorsSuite))
+
+func TestErrors(t *testing.T) { TestingT(t) }
+
+const uidConflictResponse = `<?xml version="1.0" encoding="utf-8"?>
+<d:error xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+ <c:no-uid-conflict><d:href>/calendars/jon/work/other.ics</d:href></c:no-uid-conflict>
+ <c:max-resource-size/>
+</d:error>`
+
+func (s *ErrorsSuite) client(c *C, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	ts := httptest.NewServer(handler)
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	return NewDefaultClient(server), ts
+}
+
+func (s *ErrorsSuite) TestDAVError(c *C) {
+
+	client, ts := s.client(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, uidConflictResponse)
+	})
+	defer ts.Close()
+
+	event := components.NewEventWithDuration("abc", time.Now().UTC(), time.Hour)
+	err := client.PutEvents("/calendars/jon/work/abc.ics", event)
+	c.Assert(err, NotNil)
+	c.Assert(errors.Is(err, ErrForbidden), Equals, true)
+	c.Assert(errors.Is(err, ErrNotFound), Equals, false)
+
+	var davErr *webdav.DAVError
+	c.Assert(errors.As(err, &davErr), Equals, true)
+	c.Assert(davErr.HTTP.StatusCode, Equals, http.StatusForbidden)
+	c.Assert(davErr.HTTP.Method, Equals, "PUT")
+
+	noUIDConflict := xml.Name{Space: "urn:ietf:params:xml:ns:caldav", Local: "no-uid-conflict"}
+	c.Assert(davErr.Has(noUIDConflict), Equals, true)
+	c.Assert(davErr.Condition(noUIDConflict).Hrefs, DeepEquals, []string{"/calendars/jon/work/other.ics"})
+	c.Assert(davErr.Conditions(), HasLen, 2)
+	c.Assert(davErr.Conditions()[1].Name.Local, Equals, "max-resource-size")
+
+	var httpErr *chttp.HTTPError
+	c.Assert(errors.As(err, &httpErr), Equals, true)
+	c.Assert(httpErr.Body, Matches, "(?s).*no-uid-conflict.*")
+
+	c.Assert(ErrorConditions(err), DeepEquals, []Condition{NoUIDConflict, MaxResourceSize})
+	c.Assert(IsUIDConflict(err), Equals, true)
+	c.Assert(IsLimitExceeded(err), Equals, true)
+	c.Assert(IsInvalidCalendarData(err), Equals, false)
+	href, ok := ConflictingResource(err)
+	c.Assert(ok, Equals, true)
+	c.Assert(href, Equals, "/calendars/jon/work/other.ics")
+
+}
+
+func (s *ErrorsSuite) TestMultistatusConditions(c *C) {
+
+	client, ts := s.client(c, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(webdav.StatusMulti)
+		if r.Method == "PROPPATCH" {
+			fmt.Fprint(w, `<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:response>
+ <d:href>/calendars/jon/work/</d:href>
+ <d:propstat><d:prop><c:calendar-timezone/></d:prop><d:status>HTTP/1.1 403 Forbidden</d:status>
+  <d:error><c:valid-calendar-data/></d:error></d:propstat>
+</d:response></d:multistatus>`)
+		} else {
+			fmt.Fprint(w, `<d:multistatus xmlns:d="DAV:"><d:response>
+ <d:href>/calendars/jon/work/</d:href>
+ <d:status>HTTP/1.1 507 Insufficient Storage</d:status>
+ <d:error><d:number-of-matches-within-limits/></d:error>
+</d:response></d:multistatus>`)
+		}
+	})
+	defer ts.Close()
+
+	_, err := client.MultigetEvents("/calendars/jon/work/", "/calendars/jon/work/a.ics")
+	c.Assert(IsTruncated(err), Equals, true)
+	c.Assert(IsUIDConflict(err), Equals, false)
+
+	err = client.SetCalendarDescription("/calendars/jon/work/", "Meetings")
+	c.Assert(IsInvalidCalendarData(err), Equals, true)
+	c.Assert(HasCondition(err, ValidCalendarData, MaxResourceSize), Equals, true)
+	c.Assert(IsTruncated(err), Equals, false)
+
+}
+
+func (s *ErrorsSuite) TestHTTPError(c *C) {
+
+	client, ts := s.client(c, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.ics" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "no such resource")
+		} else {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	defer ts.Close()
+
+	_, err := client.GetEvents("/missing.ics")
+	c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+	var httpErr *chttp.HTTPError
+	c.Assert(errors.As(err, &httpErr), Equals, true)
+	c.Assert(httpErr.StatusCode, Equals, http.StatusNotFound)
+	c.Assert(httpErr.Body, Equals, "no such resource")
+	var davErr *webdav.DAVError
+	c.Assert(errors.As(err, &davErr), Equals, false)
+
+	_, err = client.ListCalendars("/calendars/jon/")
+	c.Assert(errors.Is(err, ErrUnauthorized), Equals, true)
+
+	_, err = client.MultigetEvents("/missing/", "/missing/a.ics")
+	c.Assert(errors.Is(err, ErrUnauthorized), Equals, true)
+
+}
@@ -0,0 +1,672 @@
+package caldav
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dolanor/caldav-go/utils"
+	"github.com/dolanor/caldav-go/webdav/entities"
+)
+
+// the prefix of the synchronization tokens issued by the filesystem backend
+const fileSyncTokenPrefix = "urn:x-caldav-go:file-sync:"
+
+// the number of entries past which the change log of a collection is cut in half, the tokens naming the entries
+// that were dropped becoming invalid
+const fileChangeLogLimit = 1000
+
+// the filesystem backend needs file locks, which are not supported on every platform
+var ErrLockUnsupported = errors.New("file locks are not supported on this platform")
+
+// the files kept alongside the objects of a collection directory, hidden by their leading dot
+const (
+	// the properties of the collection, whose presence makes the directory a calendar collection
+	fileCollectionSidecar = ".collection.xml"
+	// the log of the changes made to the objects of the collection
+	fileChangeLog = ".changes"
+	// the names of the objects of the collection by UID, rebuilt from the objects when missing
+	fileUIDIndex = ".uids"
+	// the lock taken by the writers and readers of the backend, kept in its root directory
+	fileLockName = ".lock"
+)
+
+// a Backend keeping calendars on disk under a root directory. each calendar collection is a directory holding
+// its objects as files, along with a sidecar file of its properties, a log of the changes of its objects and an
+// index of their UIDs. writes replace files atomically, and are serialized through a lock file so that several
+// processes may share the same directory
+type FileBackend struct {
+	root string
+	// serializes the goroutines of the process, on top of the lock file shared with other processes
+	mu sync.RWMutex
+}
+
+// the sidecar file of a collection directory
+type fileCollection struct {
+	XMLName xml.Name `xml:"collection"`
+	// identifies the collection in its synchronization tokens, so that those of a deleted collection are not
+	// taken for those of one created in its place
+	Id    string               `xml:"id,attr"`
+	Props []*entities.Property `xml:",any"`
+}
+
+// an entry of the change log of a collection
+type fileChange struct {
+	seq int64
+	Change
+}
+
+// creates a backend storing calendars under a root directory, which is created if missing.
+// fails with ErrLockUnsupported on platforms without file locks
+func NewFileBackend(root string) (*FileBackend, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, utils.NewError(NewFileBackend, "unable to create root directory", root, err)
+	}
+	b := &FileBackend{root: root}
+	if unlock, err := b.lock(false); err != nil {
+		return nil, utils.NewError(NewFileBackend, "unable to lock root directory", root, err)
+	} else {
+		unlock()
+	}
+	return b, nil
+}
+
+func (b *FileBackend) Collection(ctx context.Context, path string) (*Collection, error) {
+	unlock, err := b.lock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return b.collection(path)
+}
+
+func (b *FileBackend) ListCollections(ctx context.Context, parent string) ([]*Collection, error) {
+
+	unlock, err := b.lock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	infos, err := ioutil.ReadDir(b.dir(parent))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, utils.NewError(b.ListCollections, "unable to list directory", b, err)
+	}
+
+	var collections []*Collection
+	for _, info := range infos {
+		if !info.IsDir() || !validName(info.Name()) {
+			continue
+		} else if c, err := b.collection(withSlash(parent) + info.Name() + "/"); err == ErrNotFound {
+			continue // a directory holding calendar collections of its own
+		} else if err != nil {
+			return nil, err
+		} else {
+			collections = append(collections, c)
+		}
+	}
+	return collections, nil
+
+}
+
+func (b *FileBackend) CreateCollection(ctx context.Context, path string, props []*entities.Property) (*Collection, error) {
+
+	unlock, err := b.lock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	dir := b.dir(path)
+	if _, err := b.collection(path); err == nil {
+		return nil, ErrExists
+	} else if err != ErrNotFound {
+		return nil, err
+	} else if infos, _ := ioutil.ReadDir(dir); len(infos) > 0 {
+		return nil, ErrConflict // the directory already holds calendar collections
+	}
+	for parent := parentPath(path); parent != ""; parent = parentPath(parent) {
+		if _, err := b.readSidecar(b.dir(parent)); err == nil {
+			return nil, ErrConflict // calendar collections do not nest
+		} else if err != ErrNotFound {
+			return nil, err
+		}
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, utils.NewError(b.CreateCollection, "unable to generate collection id", b, err)
+	} else if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, utils.NewError(b.CreateCollection, "unable to create directory", b, err)
+	}
+
+	fc := &fileCollection{Id: hex.EncodeToString(id), Props: setProperties(nil, props, nil)}
+	if err := b.writeSidecar(dir, fc); err != nil {
+		return nil, err
+	}
+	return b.collection(path)
+
+}
+
+func (b *FileBackend) UpdateCollection(ctx context.Context, path string, set []*entities.Property, remove []xml.Name) (*Collection, error) {
+
+	unlock, err := b.lock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	dir := b.dir(path)
+	fc, err := b.readSidecar(dir)
+	if err != nil {
+		return nil, err
+	}
+	fc.Props = setProperties(fc.Props, set, remove)
+	if err := b.writeSidecar(dir, fc); err != nil {
+		return nil, err
+	}
+	return b.collection(path)
+
+}
+
+func (b *FileBackend) DeleteCollection(ctx context.Context, path string) error {
+
+	unlock, err := b.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	dir := b.dir(path)
+	if _, err := b.readSidecar(dir); err != nil {
+		return err
+	}
+	// the sidecar goes first, so that a partial removal does not leave a calendar collection behind
+	if err := os.Remove(filepath.Join(dir, fileCollectionSidecar)); err != nil {
+		return utils.NewError(b.DeleteCollection, "unable to remove collection properties", b, err)
+	} else if err := os.RemoveAll(dir); err != nil {
+		return utils.NewError(b.DeleteCollection, "unable to remove directory", b, err)
+	}
+	return nil
+
+}
+
+func (b *FileBackend) Object(ctx context.Context, path string) (*Object, error) {
+	unlock, err := b.lock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if _, err := b.readSidecar(b.dir(parentPath(path))); err != nil {
+		return nil, err
+	}
+	return b.object(path)
+}
+
+func (b *FileBackend) ListObjects(ctx context.Context, collection string) ([]*Object, error) {
+
+	unlock, err := b.lock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	dir := b.dir(collection)
+	if _, err := b.readSidecar(dir); err != nil {
+		return nil, err
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, utils.NewError(b.ListObjects, "unable to list directory", b, err)
+	}
+
+	var objects []*Object
+	for _, info := range infos {
+		if info.IsDir() || !validName(info.Name()) {
+			continue
+		} else if o, err := b.object(collection + info.Name()); err == ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		} else {
+			objects = append(objects, o)
+		}
+	}
+	return objects, nil
+
+}
+
+func (b *FileBackend) PutObject(ctx context.Context, path string, data []byte, conditions *Conditions) (*Object, error) {
+
+	unlock, err := b.lock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	dir, name := b.dir(parentPath(path)), path[len(parentPath(path)):]
+	if _, err := b.readSidecar(dir); err == ErrNotFound || strings.HasSuffix(path, "/") {
+		return nil, ErrConflict
+	} else if err != nil {
+		return nil, err
+	} else if !validName(name) {
+		return nil, ErrForbidden
+	}
+
+	current, err := b.object(path)
+	if err == ErrNotFound {
+		current = nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := conditions.Check(current); err != nil {
+		return nil, err
+	}
+
+	// the index may still name objects that were given another UID, so conflicts are checked against the object
+	uid := objectUID(data)
+	uids, err := b.readUIDs(dir)
+	if err != nil {
+		return nil, err
+	} else if other, found := uids[uid]; uid != "" && found && other != name {
+		if o, err := b.object(parentPath(path) + other); err == nil && objectUID(o.Data) == uid {
+			return nil, &UIDConflictError{Path: o.Path}
+		}
+	}
+	// the UID of the current object stays indexed until the object is replaced
+	for u, n := range uids {
+		if n == name && (current == nil || u != objectUID(current.Data)) {
+			delete(uids, u)
+		}
+	}
+	if uid != "" {
+		uids[uid] = name
+	}
+	if err := b.writeUIDs(dir, uids); err != nil {
+		return nil, err
+	}
+
+	// the change is logged first, as a change logged for a write that did not happen is harmless
+	if err := b.logChange(dir, Change{Path: path}); err != nil {
+		return nil, err
+	} else if err := writeFileAtomic(filepath.Join(dir, name), data); err != nil {
+		return nil, utils.NewError(b.PutObject, "unable to write object", b, err)
+	}
+	return b.object(path)
+
+}
+
+func (b *FileBackend) DeleteObject(ctx context.Context, path string, conditions *Conditions) error {
+
+	unlock, err := b.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	dir := b.dir(parentPath(path))
+	if _, err := b.readSidecar(dir); err != nil {
+		return err
+	}
+	current, err := b.object(path)
+	if err != nil {
+		return err
+	} else if err := conditions.Check(current); err != nil {
+		return err
+	}
+
+	if err := b.logChange(dir, Change{Path: path, Deleted: true}); err != nil {
+		return err
+	} else if err := os.Remove(b.file(path)); err != nil {
+		return utils.NewError(b.DeleteObject, "unable to remove object", b, err)
+	}
+
+	uids, err := b.readUIDs(dir)
+	if err != nil {
+		return err
+	}
+	for u, n := range uids {
+		if n == path[len(parentPath(path)):] {
+			delete(uids, u)
+		}
+	}
+	return b.writeUIDs(dir, uids)
+
+}
+
+func (b *FileBackend) Changes(ctx context.Context, collection string, token string) ([]*Change, string, error) {
+
+	unlock, err := b.lock(false)
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlock()
+
+	dir := b.dir(collection)
+	fc, err := b.readSidecar(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	log, floor, err := b.readChanges(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	current := fileSyncToken(fc, log)
+
+	if token == "" {
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, "", utils.NewError(b.Changes, "unable to list directory", b, err)
+		}
+		var changes []*Change
+		for _, info := range infos {
+			if !info.IsDir() && validName(info.Name()) {
+				changes = append(changes, &Change{Path: collection + info.Name()})
+			}
+		}
+		return changes, current, nil
+	}
+
+	prefix := fileSyncTokenPrefix + fc.Id + ":"
+	since, err := strconv.ParseInt(strings.TrimPrefix(token, prefix), 10, 64)
+	if err != nil || !strings.HasPrefix(token, prefix) || since < floor || since > lastSeq(log) {
+		return nil, "", ErrInvalidSyncToken
+	}
+
+	latest := make(map[string]*Change)
+	for _, change := range log {
+		if change.seq > since {
+			latest[change.Path] = &Change{Path: change.Path, Deleted: change.Deleted}
+		}
+	}
+	var changes []*Change
+	for _, change := range latest {
+		changes = append(changes, change)
+	}
+	sortChanges(changes)
+	return changes, current, nil
+
+}
+
+// returns the calendar collection at a path, the lock being held
+func (b *FileBackend) collection(path string) (*Collection, error) {
+	dir := b.dir(path)
+	if fc, err := b.readSidecar(dir); err != nil {
+		return nil, err
+	} else if log, _, err := b.readChanges(dir); err != nil {
+		return nil, err
+	} else {
+		return &Collection{Path: withSlash(path), Properties: fc.Props, SyncToken: fileSyncToken(fc, log)}, nil
+	}
+}
+
+// returns the calendar object at a path, the lock being held
+func (b *FileBackend) object(p string) (*Object, error) {
+	name := b.file(p)
+	if !validName(p[len(parentPath(p)):]) {
+		return nil, ErrNotFound
+	} else if info, err := os.Stat(name); os.IsNotExist(err) || (err == nil && info.IsDir()) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, utils.NewError(b.object, "unable to stat object", b, err)
+	} else if data, err := ioutil.ReadFile(name); os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, utils.NewError(b.object, "unable to read object", b, err)
+	} else {
+		return &Object{Path: p, ETag: contentETag(data), ModTime: info.ModTime().UTC(), Data: data}, nil
+	}
+}
+
+// reads the sidecar file of a collection directory, failing with ErrNotFound if it is not a calendar collection
+func (b *FileBackend) readSidecar(dir string) (*fileCollection, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, fileCollectionSidecar))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, utils.NewError(b.readSidecar, "unable to read collection properties", b, err)
+	}
+	fc := new(fileCollection)
+	if err := xml.Unmarshal(data, fc); err != nil {
+		return nil, utils.NewError(b.readSidecar, "unable to decode collection properties", b, err)
+	}
+	return fc, nil
+}
+
+// replaces the sidecar file of a collection directory
+func (b *FileBackend) writeSidecar(dir string, fc *fileCollection) error {
+	if data, err := xml.Marshal(fc); err != nil {
+		return utils.NewError(b.writeSidecar, "unable to encode collection properties", b, err)
+	} else if err := writeFileAtomic(filepath.Join(dir, fileCollectionSidecar), data); err != nil {
+		return utils.NewError(b.writeSidecar, "unable to write collection properties", b, err)
+	}
+	return nil
+}
+
+// reads the UID index of a collection directory, building it from the objects if it is missing or damaged
+func (b *FileBackend) readUIDs(dir string) (map[string]string, error) {
+
+	uids := make(map[string]string)
+	data, err := ioutil.ReadFile(filepath.Join(dir, fileUIDIndex))
+	if err == nil && json.Unmarshal(data, &uids) == nil {
+		return uids, nil
+	} else if err != nil && !os.IsNotExist(err) {
+		return nil, utils.NewError(b.readUIDs, "unable to read UID index", b, err)
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, utils.NewError(b.readUIDs, "unable to list directory", b, err)
+	}
+	uids = make(map[string]string)
+	for _, info := range infos {
+		if info.IsDir() || !validName(info.Name()) {
+			continue
+		} else if data, err := ioutil.ReadFile(filepath.Join(dir, info.Name())); err != nil {
+			return nil, utils.NewError(b.readUIDs, "unable to read object", b, err)
+		} else if uid := objectUID(data); uid != "" {
+			uids[uid] = info.Name()
+		}
+	}
+	return uids, nil
+
+}
+
+// replaces the UID index of a collection directory
+func (b *FileBackend) writeUIDs(dir string, uids map[string]string) error {
+	if data, err := json.Marshal(uids); err != nil {
+		return utils.NewError(b.writeUIDs, "unable to encode UID index", b, err)
+	} else if err := writeFileAtomic(filepath.Join(dir, fileUIDIndex), data); err != nil {
+		return utils.NewError(b.writeUIDs, "unable to write UID index", b, err)
+	}
+	return nil
+}
+
+// reads the change log of a collection directory, whose lines hold a sequence number, an operation and a name.
+// returns the entries of the log along with the sequence number of the latest entry dropped from it, if any
+func (b *FileBackend) readChanges(dir string) ([]fileChange, int64, error) {
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, fileChangeLog))
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	} else if err != nil {
+		return nil, 0, utils.NewError(b.readChanges, "unable to read change log", b, err)
+	}
+
+	collection := b.collectionPath(dir)
+	var log []fileChange
+	var floor int64
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 3)
+		if len(fields) != 3 {
+			continue // a line cut short by a crash
+		} else if seq, err := strconv.ParseInt(fields[0], 10, 64); err != nil {
+			continue
+		} else if fields[1] == "truncate" {
+			floor = seq
+		} else {
+			change := Change{Path: collection + fields[2], Deleted: fields[1] == "delete"}
+			log = append(log, fileChange{seq: seq, Change: change})
+		}
+	}
+	return log, floor, nil
+
+}
+
+// appends a change to the log of a collection directory, the exclusive lock being held.
+// a log grown past fileChangeLogLimit entries is replaced by its latest half, headed by the sequence number of
+// the latest entry dropped
+func (b *FileBackend) logChange(dir string, change Change) error {
+
+	log, _, err := b.readChanges(dir)
+	if err != nil {
+		return err
+	}
+	entry := fileChange{seq: lastSeq(log) + 1, Change: change}
+
+	if len(log) >= fileChangeLogLimit {
+		kept := append(log[len(log)-fileChangeLogLimit/2:], entry)
+		lines := []string{fmt.Sprintf("%d truncate .\n", kept[0].seq-1)}
+		for _, c := range kept {
+			lines = append(lines, c.line())
+		}
+		if err := writeFileAtomic(filepath.Join(dir, fileChangeLog), []byte(strings.Join(lines, ""))); err != nil {
+			return utils.NewError(b.logChange, "unable to truncate change log", b, err)
+		}
+		return nil
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, fileChangeLog), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return utils.NewError(b.logChange, "unable to open change log", b, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(entry.line()); err != nil {
+		return utils.NewError(b.logChange, "unable to append to change log", b, err)
+	} else if err := f.Sync(); err != nil {
+		return utils.NewError(b.logChange, "unable to sync change log", b, err)
+	}
+	return nil
+
+}
+
+// encodes an entry of the change log as a line
+func (c fileChange) line() string {
+	op := "put"
+	if c.Deleted {
+		op = "delete"
+	}
+	return fmt.Sprintf("%d %s %s\n", c.seq, op, path.Base(c.Path))
+}
+
+// takes the lock of the backend, shared by readers or exclusive to a writer, returning the function releasing it
+func (b *FileBackend) lock(exclusive bool) (func(), error) {
+
+	if exclusive {
+		b.mu.Lock()
+	} else {
+		b.mu.RLock()
+	}
+	release := func() {
+		if exclusive {
+			b.mu.Unlock()
+		} else {
+			b.mu.RUnlock()
+		}
+	}
+
+	f, err := os.OpenFile(filepath.Join(b.root, fileLockName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		release()
+		return nil, utils.NewError(b.lock, "unable to open lock file", b, err)
+	} else if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		release()
+		return nil, utils.NewError(b.lock, "unable to lock", b, err)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+		release()
+	}, nil
+
+}
+
+// returns the directory of a collection path
+func (b *FileBackend) dir(path string) string {
+	return b.file(withSlash(path))
+}
+
+// returns the file of a resource path, which cannot escape the root directory
+func (b *FileBackend) file(p string) string {
+	return filepath.Join(b.root, filepath.FromSlash(path.Clean("/"+p)))
+}
+
+// returns the collection path of a directory under the root
+func (b *FileBackend) collectionPath(dir string) string {
+	rel, _ := filepath.Rel(b.root, dir)
+	return withSlash(path.Clean("/" + filepath.ToSlash(rel)))
+}
+
+// returns the synchronization token of a collection, naming the latest entry of its change log
+func fileSyncToken(fc *fileCollection, log []fileChange) string {
+	return fmt.Sprintf("%s%s:%d", fileSyncTokenPrefix, fc.Id, lastSeq(log))
+}
+
+// returns the sequence number of the latest entry of a change log, zero if it is empty
+func lastSeq(log []fileChange) int64 {
+	var seq int64
+	for _, change := range log {
+		if change.seq > seq {
+			seq = change.seq
+		}
+	}
+	return seq
+}
+
+// checks that a name may be stored as an object or collection, hidden files being kept by the backend
+func validName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, "/\\\n")
+}
+
+// replaces a file by writing its new content to a temporary file of the same directory, then renaming it
+func writeFileAtomic(name string, data []byte) error {
+
+	f, err := ioutil.TempFile(filepath.Dir(name), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // left behind only if the rename failed
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Chmod(0644); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+
+}
@@ -0,0 +1,208 @@
+package caldav
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	. "gopkg.in/check.v1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dolanor/caldav-go/webdav/entities"
+)
+
+type FileBackendSuite struct{}
+
+var _ = Suite(new(FileBackendSuite))
+
+func TestFileBackend(t *testing.T) { TestingT(t) }
+
+func (s *FileBackendSuite) SetUpSuite(c *C) {
+	if _, err := NewFileBackend(c.MkDir()); errors.Is(err, ErrLockUnsupported) {
+		c.Skip(err.Error())
+	}
+}
+
+func (s *FileBackendSuite) TestHandler(c *C) {
+	backend, err := NewFileBackend(c.MkDir())
+	c.Assert(err, IsNil)
+	exerciseHandler(c, backend)
+}
+
+func (s *FileBackendSuite) TestPersistence(c *C) {
+
+	ctx, root := context.Background(), c.MkDir()
+	backend, err := NewFileBackend(root)
+	c.Assert(err, IsNil)
+
+	name := entities.NewTextProperty(xml.Name{Space: davNamespace, Local: "displayname"}, "Work")
+	_, err = backend.CreateCollection(ctx, "/calendars/jon/work/", []*entities.Property{name})
+	c.Assert(err, IsNil)
+	_, err = backend.CreateCollection(ctx, "/calendars/jon/work/", nil)
+	c.Assert(err, Equals, ErrExists)
+	_, err = backend.CreateCollection(ctx, "/calendars/jon/work/nested/", nil)
+	c.Assert(err, Equals, ErrConflict)
+
+	put, err := backend.PutObject(ctx, "/calendars/jon/work/standup.ics", []byte(standupCalendar), &Conditions{IfNoneMatch: "*"})
+	c.Assert(err, IsNil)
+	_, token, err := backend.Changes(ctx, "/calendars/jon/work/", "")
+	c.Assert(err, IsNil)
+	_, err = backend.PutObject(ctx, "/calendars/jon/work/.changes", []byte(standupCalendar), nil)
+	c.Assert(err, Equals, ErrForbidden)
+	_, err = backend.Object(ctx, "/calendars/jon/work/.collection.xml")
+	c.Assert(err, Equals, ErrNotFound)
+
+	// files are left in place of the collection, with its properties in a sidecar
+	data, err := ioutil.ReadFile(filepath.Join(root, "calendars", "jon", "work", "standup.ics"))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, standupCalendar)
+	data, err = ioutil.ReadFile(filepath.Join(root, "calendars", "jon", "work", fileCollectionSidecar))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Matches, ".*<displayname xmlns=\"DAV:\">Work</displayname>.*")
+
+	reopened, err := NewFileBackend(root)
+	c.Assert(err, IsNil)
+	collections, err := reopened.ListCollections(ctx, "/calendars/jon/")
+	c.Assert(err, IsNil)
+	c.Assert(collections, HasLen, 1)
+	c.Assert(collections[0].Property(name.XMLName).InnerXML, Equals, "Work")
+	c.Assert(collections[0].SyncToken, Equals, token)
+	got, err := reopened.Object(ctx, "/calendars/jon/work/standup.ics")
+	c.Assert(err, IsNil)
+	c.Assert(got.ETag, Equals, put.ETag)
+
+	c.Assert(reopened.DeleteObject(ctx, got.Path, &Conditions{IfMatch: put.ETag}), IsNil)
+	changes, _, err := backend.Changes(ctx, "/calendars/jon/work/", token)
+	c.Assert(err, IsNil)
+	c.Assert(changes, DeepEquals, []*Change{{Path: got.Path, Deleted: true}})
+
+	// tokens of a deleted collection are not valid for the one created in its place
+	c.Assert(backend.DeleteCollection(ctx, "/calendars/jon/work/"), IsNil)
+	_, err = backend.CreateCollection(ctx, "/calendars/jon/work/", nil)
+	c.Assert(err, IsNil)
+	_, _, err = backend.Changes(ctx, "/calendars/jon/work/", token)
+	c.Assert(err, Equals, ErrInvalidSyncToken)
+
+}
+
+func (s *FileBackendSuite) TestConcurrentWriters(c *C) {
+
+	ctx, root := context.Background(), c.MkDir()
+	first, err := NewFileBackend(root)
+	c.Assert(err, IsNil)
+	_, err = first.CreateCollection(ctx, "/work/", nil)
+	c.Assert(err, IsNil)
+	_, initial, err := first.Changes(ctx, "/work/", "")
+	c.Assert(err, IsNil)
+
+	// backends sharing a directory only coordinate through its lock file, as separate processes would
+	var wg sync.WaitGroup
+	errs := make(chan error, 200)
+	for i := 0; i < 8; i++ {
+		backend, err := NewFileBackend(root)
+		c.Assert(err, IsNil)
+		wg.Add(1)
+		go func(writer int) {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				data := strings.Replace(standupCalendar, "UID:", fmt.Sprintf("UID:%d-%d-", writer, j), 1)
+				_, err := backend.PutObject(ctx, fmt.Sprintf("/work/%d.ics", j%10), []byte(data), nil)
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		c.Assert(err, IsNil)
+	}
+
+	objects, err := first.ListObjects(ctx, "/work/")
+	c.Assert(err, IsNil)
+	c.Assert(objects, HasLen, 10)
+
+	// every write was logged under its own sequence number
+	log, _, err := first.readChanges(filepath.Join(root, "work"))
+	c.Assert(err, IsNil)
+	c.Assert(log, HasLen, 200)
+	for i, change := range log {
+		c.Assert(change.seq, Equals, int64(i+1))
+	}
+	changes, _, err := first.Changes(ctx, "/work/", initial)
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 10)
+
+}
+
+func (s *FileBackendSuite) TestChangeLogTruncation(c *C) {
+
+	ctx, root := context.Background(), c.MkDir()
+	backend, err := NewFileBackend(root)
+	c.Assert(err, IsNil)
+	_, err = backend.CreateCollection(ctx, "/work/", nil)
+	c.Assert(err, IsNil)
+
+	// a log about to outgrow its limit
+	var lines []string
+	for i := 1; i <= fileChangeLogLimit; i++ {
+		lines = append(lines, fmt.Sprintf("%d put %d.ics\n", i, i%10))
+	}
+	dir := filepath.Join(root, "work")
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, fileChangeLog), []byte(strings.Join(lines, "")), 0644), IsNil)
+	collection, err := backend.Collection(ctx, "/work/")
+	c.Assert(err, IsNil)
+	prefix := strings.TrimSuffix(collection.SyncToken, fmt.Sprint(fileChangeLogLimit))
+	old, recent := prefix+"10", prefix+"900"
+
+	_, err = backend.PutObject(ctx, "/work/standup.ics", []byte(standupCalendar), nil)
+	c.Assert(err, IsNil)
+	log, floor, err := backend.readChanges(dir)
+	c.Assert(err, IsNil)
+	c.Assert(log, HasLen, fileChangeLogLimit/2+1)
+	c.Assert(floor, Equals, int64(fileChangeLogLimit/2))
+	c.Assert(log[len(log)-1].seq, Equals, int64(fileChangeLogLimit+1))
+
+	// tokens naming dropped entries are no longer valid, while the others still list the changes since them
+	_, _, err = backend.Changes(ctx, "/work/", old)
+	c.Assert(err, Equals, ErrInvalidSyncToken)
+	changes, _, err := backend.Changes(ctx, "/work/", recent)
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 11)
+
+}
+
+func (s *FileBackendSuite) TestUIDIndex(c *C) {
+
+	ctx, root := context.Background(), c.MkDir()
+	backend, err := NewFileBackend(root)
+	c.Assert(err, IsNil)
+	_, err = backend.CreateCollection(ctx, "/work/", nil)
+	c.Assert(err, IsNil)
+	_, err = backend.PutObject(ctx, "/work/standup.ics", []byte(standupCalendar), nil)
+	c.Assert(err, IsNil)
+
+	// the index is rebuilt from the objects when it is missing or damaged
+	index := filepath.Join(root, "work", fileUIDIndex)
+	for _, data := range []string{"", "{"} {
+		if data == "" {
+			c.Assert(os.Remove(index), IsNil)
+		} else {
+			c.Assert(ioutil.WriteFile(index, []byte(data), 0644), IsNil)
+		}
+		_, err = backend.PutObject(ctx, "/work/copy.ics", []byte(standupCalendar), nil)
+		conflict, ok := err.(*UIDConflictError)
+		c.Assert(ok, Equals, true)
+		c.Assert(conflict.Path, Equals, "/work/standup.ics")
+	}
+
+	// the UID is freed along with the object
+	c.Assert(backend.DeleteObject(ctx, "/work/standup.ics", nil), IsNil)
+	_, err = backend.PutObject(ctx, "/work/copy.ics", []byte(standupCalendar), nil)
+	c.Assert(err, IsNil)
+
+}
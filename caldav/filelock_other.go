@@ -0,0 +1,16 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package caldav
+
+import "os"
+
+// file locks are not supported on this platform, so that the backend cannot be used
+func lockFile(f *os.File, exclusive bool) error {
+	return ErrLockUnsupported
+}
+
+// releases the lock taken on a file
+func unlockFile(f *os.File) error {
+	return ErrLockUnsupported
+}
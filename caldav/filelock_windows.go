@@ -0,0 +1,45 @@
+//go:build windows
+// +build windows
+
+package caldav
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	// asks LockFileEx for an exclusive lock rather than a shared one
+	lockfileExclusiveLock = 0x2
+	// the length of the locked range, covering the whole file
+	lockRangeLow, lockRangeHigh = 0xffffffff, 0xffffffff
+)
+
+// takes a lock on a file, shared or exclusive, waiting for other processes to release theirs
+func lockFile(f *os.File, exclusive bool) error {
+	var flags uintptr
+	if exclusive {
+		flags = lockfileExclusiveLock
+	}
+	ol := new(syscall.Overlapped)
+	if r, _, err := procLockFileEx.Call(f.Fd(), flags, 0, lockRangeLow, lockRangeHigh, uintptr(unsafe.Pointer(ol))); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// releases the lock taken on a file
+func unlockFile(f *os.File) error {
+	ol := new(syscall.Overlapped)
+	if r, _, err := procUnlockFileEx.Call(f.Fd(), 0, lockRangeLow, lockRangeHigh, uintptr(unsafe.Pointer(ol))); r == 0 {
+		return err
+	}
+	return nil
+}
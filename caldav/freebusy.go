@@ -0,0 +1,63 @@
+package caldav
+
+import (
+	"context"
+	"fmt"
+	cent "github.com/dolanor/caldav-go/caldav/entities"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	"github.com/dolanor/caldav-go/utils"
+	"github.com/dolanor/caldav-go/webdav"
+	"net/http"
+	"time"
+)
+
+// fetches the busy time of a calendar collection between two times, see RFC 4791 section 7.10.
+// returns the busy periods, whether tentative or not, merged into sorted intervals and clipped to the range
+func (c *Client) FreeBusy(path string, start, end time.Time) ([]*values.Period, error) {
+	return c.FreeBusyContext(context.Background(), path, start, end)
+}
+
+// same as FreeBusy, using a context to cancel the request
+func (c *Client) FreeBusyContext(ctx context.Context, path string, start, end time.Time) ([]*values.Period, error) {
+
+	cal := new(components.Calendar)
+	start, end = start.UTC(), end.UTC()
+
+	if query, err := cent.NewFreeBusyQuery(start, end); err != nil {
+		return nil, utils.NewError(c.FreeBusyContext, "unable to create query", c, err)
+	} else if req, err := c.Server().WebDAV().NewRequestWithContext(ctx, "REPORT", path, query); err != nil {
+		return nil, utils.NewError(c.FreeBusyContext, "unable to create request", c, err)
+	} else if req.Http().Native().Header.Set("Depth", string(webdav.Depth1)); false {
+	} else if resp, err := c.WebDAV().Do(req); err != nil {
+		return nil, utils.NewError(c.FreeBusyContext, "unable to execute request", c, err)
+	} else if resp.StatusCode != http.StatusOK {
+		err := resp.DecodeError()
+		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
+		return nil, utils.NewError(c.FreeBusyContext, msg, c, err)
+	} else if err := NewResponse(resp).Decode(cal); err != nil {
+		return nil, utils.NewError(c.FreeBusyContext, "unable to decode response", c, err)
+	}
+
+	var busy []*values.Period
+	for _, fb := range cal.FreeBusy {
+		busy = append(busy, fb.BusyPeriods()...)
+	}
+
+	var clipped []*values.Period
+	for _, p := range values.MergePeriods(busy...) {
+		pstart, pend := p.Start(), p.End()
+		if pstart.Before(start) {
+			pstart = start
+		}
+		if pend.After(end) {
+			pend = end
+		}
+		if pend.After(pstart) {
+			clipped = append(clipped, values.NewPeriod(pstart, pend))
+		}
+	}
+
+	return clipped, nil
+
+}
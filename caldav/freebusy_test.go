@@ -0,0 +1,63 @@
+package caldav
+
+import (
+	"fmt"
+	. "gopkg.in/check.v1"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type FreeBusySuite struct{}
+
+var _ = Suite(new(FreeBusySuite))
+
+func TestFreeBusy(t *testing.T) { TestingT(t) }
+
+// the response of the example of RFC 4791 section 7.10.1
+const freeBusyResponse = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Example Corp.//CalDAV Server//EN\r\n" +
+	"BEGIN:VFREEBUSY\r\nDTSTAMP:20050125T090000Z\r\nDTSTART:20060104T140000Z\r\nDTEND:20060105T220000Z\r\n" +
+	"FREEBUSY;FBTYPE=BUSY-TENTATIVE:20060104T150000Z/PT1H\r\n" +
+	"FREEBUSY:20060104T190000Z/PT1H,20060104T133000Z/PT1H\r\n" +
+	"FREEBUSY;FBTYPE=FREE:20060104T200000Z/PT1H\r\n" +
+	"FREEBUSY;FBTYPE=BUSY-UNAVAILABLE:20060105T170000Z/PT1H,20060104T195000Z/PT30M\r\n" +
+	"END:VFREEBUSY\r\nEND:VCALENDAR\r\n"
+
+func (s *FreeBusySuite) TestFreeBusy(c *C) {
+
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		if r.Method != "REPORT" || r.Header.Get("Depth") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar")
+		fmt.Fprint(w, freeBusyResponse)
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	start := time.Date(2006, 1, 4, 14, 0, 0, 0, time.UTC)
+	end := time.Date(2006, 1, 5, 22, 0, 0, 0, time.UTC)
+	busy, err := client.FreeBusy("/calendars/jon/work/", start, end)
+	c.Assert(err, IsNil)
+
+	c.Assert(strings.Contains(body, "free-busy-query"), Equals, true)
+	c.Assert(strings.Contains(body, `start="20060104T140000Z" end="20060105T220000Z"`), Equals, true)
+
+	// the period starting before the range is clipped, the overlapping ones are merged
+	c.Assert(busy, HasLen, 4)
+	c.Assert(busy[0].String(), Equals, "20060104T140000Z/20060104T143000Z")
+	c.Assert(busy[1].String(), Equals, "20060104T150000Z/20060104T160000Z")
+	c.Assert(busy[2].String(), Equals, "20060104T190000Z/20060104T202000Z")
+	c.Assert(busy[3].String(), Equals, "20060105T170000Z/20060105T180000Z")
+
+}
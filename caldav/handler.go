@@ -0,0 +1,401 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	cent "github.com/dolanor/caldav-go/caldav/entities"
+	"github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/icalendar"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/webdav"
+	"github.com/dolanor/caldav-go/webdav/entities"
+)
+
+// the methods a Handler answers
+const handlerMethods = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, PROPPATCH, MKCALENDAR, REPORT"
+
+// the compliance classes a Handler announces in the DAV header
+const handlerCompliance = "1, 3, calendar-access"
+
+// the media type of calendar object resources
+const calendarContentType = "text/calendar; charset=utf-8"
+
+// serves the calendars of a Backend over CalDAV, see RFC 4791, along with the sync-collection report of RFC 6578.
+// calendar collections may be created anywhere but within another calendar collection, and the collections
+// holding calendars, such as calendar homes, are listed as plain collections
+type Handler struct {
+	// the storage of the calendars
+	Backend Backend
+	// the path of the principal of the user, reported as DAV:current-user-principal when set
+	Principal string
+	// the path of the calendar home of the user, reported as CALDAV:calendar-home-set on the principal when set
+	Home string
+}
+
+// creates a handler serving the calendars of a backend
+func NewHandler(backend Backend) *Handler {
+	return &Handler{Backend: backend}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	// resources are looked up by their clean path, keeping the trailing slash of collections
+	if clean := cleanPath(r.URL.Path); clean != r.URL.Path {
+		r = r.Clone(r.Context())
+		r.URL.Path = clean
+	}
+
+	var err error
+	switch r.Method {
+	case "OPTIONS":
+		err = h.serveOptions(w, r)
+	case "GET", "HEAD":
+		err = h.serveGet(w, r)
+	case "PUT":
+		err = h.servePut(w, r)
+	case "DELETE":
+		err = h.serveDelete(w, r)
+	case "PROPFIND":
+		err = h.servePropfind(w, r)
+	case "PROPPATCH":
+		err = h.serveProppatch(w, r)
+	case "MKCALENDAR":
+		err = h.serveMakeCalendar(w, r)
+	case "REPORT":
+		err = h.serveReport(w, r)
+	default:
+		err = &handlerError{code: http.StatusMethodNotAllowed}
+	}
+
+	if err != nil {
+		serveError(w, err)
+	}
+
+}
+
+// announces the features of the server
+func (h *Handler) serveOptions(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("DAV", handlerCompliance)
+	w.Header().Set("Allow", handlerMethods)
+	w.Header().Set("Content-Length", "0")
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// serves the iCalendar data of a calendar object resource
+func (h *Handler) serveGet(w http.ResponseWriter, r *http.Request) error {
+
+	res, err := h.resolve(r.Context(), r.URL.Path)
+	if err != nil {
+		return err
+	} else if res.object == nil {
+		return &handlerError{code: http.StatusMethodNotAllowed}
+	}
+
+	o := res.object
+	w.Header().Set("ETag", o.ETag)
+	w.Header().Set("Last-Modified", o.ModTime.UTC().Format(http.TimeFormat))
+	if inm := r.Header.Get("If-None-Match"); inm != "" && matchesETag(inm, o.ETag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", calendarContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(o.Data)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != "HEAD" {
+		w.Write(o.Data)
+	}
+	return nil
+
+}
+
+// stores a calendar object resource, checking the preconditions of RFC 4791 section 5.3.2.1
+func (h *Handler) servePut(w http.ResponseWriter, r *http.Request) error {
+
+	ctx, path := r.Context(), r.URL.Path
+	if strings.HasSuffix(path, "/") {
+		return &handlerError{code: http.StatusMethodNotAllowed}
+	}
+
+	collection, err := h.Backend.Collection(ctx, parentPath(path))
+	if errors.Is(err, ErrNotFound) {
+		return &handlerError{code: http.StatusConflict}
+	} else if err != nil {
+		return err
+	}
+
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mediaType, _, _ := mime.ParseMediaType(ct); mediaType != "text/calendar" {
+			return newConditionError(http.StatusForbidden, SupportedCalendarData)
+		}
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return &handlerError{code: http.StatusBadRequest}
+	}
+
+	names, uids := scanCalendarObject(string(data))
+	if err := icalendar.Unmarshal(string(data), new(components.Calendar)); err != nil {
+		return newConditionError(http.StatusForbidden, ValidCalendarData)
+	} else if len(names) == 0 || len(uids) != 1 {
+		return newConditionError(http.StatusForbidden, ValidCalendarObjectResource)
+	}
+
+	if supported := supportedComponents(collection); len(supported) > 0 {
+		for _, name := range names {
+			if !supported[name] {
+				return newConditionError(http.StatusForbidden, SupportedCalendarComponent)
+			}
+		}
+	}
+
+	// the UID of the object is checked by the backend, as it writes it
+	existed := true
+	if _, err := h.Backend.Object(ctx, path); errors.Is(err, ErrNotFound) {
+		existed = false
+	} else if err != nil {
+		return err
+	}
+
+	o, err := h.Backend.PutObject(ctx, path, data, requestConditions(r))
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", o.ETag)
+	if existed {
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	return nil
+
+}
+
+// deletes a calendar object resource or a calendar collection
+func (h *Handler) serveDelete(w http.ResponseWriter, r *http.Request) error {
+
+	res, err := h.resolve(r.Context(), r.URL.Path)
+	if err != nil {
+		return err
+	} else if res.object != nil {
+		err = h.Backend.DeleteObject(r.Context(), res.path, requestConditions(r))
+	} else if res.collection != nil {
+		err = h.Backend.DeleteCollection(r.Context(), res.path)
+	} else {
+		err = &handlerError{code: http.StatusForbidden}
+	}
+
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+
+}
+
+// a resource served by the handler: a calendar object, a calendar collection or a collection of calendars
+type resource struct {
+	// the path of the resource, ending with a slash for collections
+	path       string
+	object     *Object
+	collection *Collection
+}
+
+// finds the resource at a path, collections being found with or without their trailing slash
+func (h *Handler) resolve(ctx context.Context, path string) (*resource, error) {
+
+	if !strings.HasSuffix(path, "/") {
+		if o, err := h.Backend.Object(ctx, path); err == nil {
+			return &resource{path: path, object: o}, nil
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		path += "/"
+	}
+
+	if c, err := h.Backend.Collection(ctx, path); err == nil {
+		return &resource{path: path, collection: c}, nil
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	if path == "/" || path == withSlash(h.Principal) || path == withSlash(h.Home) {
+		return &resource{path: path}, nil
+	} else if children, err := h.Backend.ListCollections(ctx, path); err != nil {
+		return nil, err
+	} else if len(children) > 0 {
+		return &resource{path: path}, nil
+	}
+
+	return nil, ErrNotFound
+
+}
+
+// an error answered with a status and, for failed preconditions, a DAV:error body naming the condition
+type handlerError struct {
+	code      int
+	condition *entities.Property
+}
+
+// creates an error for a failed precondition, optionally listing the resources that caused it
+func newConditionError(code int, condition Condition, hrefs ...string) *handlerError {
+	p := entities.NewProperty(condition.Name())
+	for _, href := range hrefs {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(escapePath(href)))
+		p.InnerXML += `<href xmlns="DAV:">` + buf.String() + `</href>`
+	}
+	return &handlerError{code: code, condition: p}
+}
+
+func (e *handlerError) Error() string {
+	if e.condition != nil {
+		return fmt.Sprintf("%s: %s", http.StatusText(e.code), e.condition.XMLName.Local)
+	}
+	return http.StatusText(e.code)
+}
+
+// answers a failed request, turning the errors of backends into their status
+func serveError(w http.ResponseWriter, err error) {
+
+	var herr *handlerError
+	var uerr *UIDConflictError
+	if !errors.As(err, &herr) {
+		switch {
+		case errors.As(err, &uerr):
+			herr = newConditionError(http.StatusForbidden, NoUIDConflict, uerr.Path)
+		case errors.Is(err, ErrNotFound):
+			herr = &handlerError{code: http.StatusNotFound}
+		case errors.Is(err, ErrConflict):
+			herr = &handlerError{code: http.StatusConflict}
+		case errors.Is(err, ErrPreconditionFailed):
+			herr = &handlerError{code: http.StatusPreconditionFailed}
+		case errors.Is(err, ErrForbidden):
+			herr = &handlerError{code: http.StatusForbidden}
+		case errors.Is(err, ErrExists):
+			herr = newConditionError(http.StatusMethodNotAllowed, ResourceMustBeNull)
+		case errors.Is(err, ErrInvalidSyncToken):
+			herr = newConditionError(http.StatusForbidden, ValidSyncToken)
+		default:
+			herr = &handlerError{code: http.StatusInternalServerError}
+		}
+	}
+
+	if herr.code == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", handlerMethods)
+	}
+
+	if herr.condition == nil {
+		http.Error(w, herr.Error(), herr.code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(herr.code)
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(&entities.Error{Conditions: []*entities.Property{herr.condition}})
+
+}
+
+// decodes the XML body of a request, returning io.EOF if there is none
+func decodeBody(r *http.Request, v interface{}) error {
+	if data, err := ioutil.ReadAll(r.Body); err != nil {
+		return &handlerError{code: http.StatusBadRequest}
+	} else if len(bytes.TrimSpace(data)) == 0 {
+		return io.EOF
+	} else if err := xml.Unmarshal(data, v); err != nil {
+		return &handlerError{code: http.StatusBadRequest}
+	}
+	return nil
+}
+
+// writes a multistatus response
+func writeMultistatus(w http.ResponseWriter, ms interface{}) error {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(webdav.StatusMulti)
+	io.WriteString(w, xml.Header)
+	return xml.NewEncoder(w).Encode(ms)
+}
+
+// reads the conditions of a write from its headers
+func requestConditions(r *http.Request) *Conditions {
+	return &Conditions{IfMatch: r.Header.Get("If-Match"), IfNoneMatch: r.Header.Get("If-None-Match")}
+}
+
+// lists the component types and the UIDs found at the top level of iCalendar data, leaving out time zones
+func scanCalendarObject(data string) (names []values.ComponentName, uids []string) {
+	seen := make(map[string]bool)
+	var depth int
+	for _, line := range strings.Split(strings.Replace(data, "\r\n", "\n", -1), "\n") {
+		upper := strings.ToUpper(line)
+		if strings.HasPrefix(upper, "BEGIN:") {
+			depth++
+			name := values.ComponentName(strings.TrimSpace(upper[len("BEGIN:"):]))
+			if depth == 2 && name != values.TimeZoneComponentName && !seen[string(name)] {
+				seen[string(name)] = true
+				names = append(names, name)
+			}
+		} else if strings.HasPrefix(upper, "END:") {
+			depth--
+		} else if depth == 2 && (strings.HasPrefix(upper, "UID:") || strings.HasPrefix(upper, "UID;")) {
+			uid := strings.TrimSpace(line[strings.Index(line, ":")+1:])
+			if !seen["UID:"+uid] {
+				seen["UID:"+uid] = true
+				uids = append(uids, uid)
+			}
+		}
+	}
+	return
+}
+
+// returns the component types a calendar collection accepts, empty if it accepts any
+func supportedComponents(c *Collection) map[values.ComponentName]bool {
+	supported := make(map[values.ComponentName]bool)
+	set := new(cent.SupportedCalendarComponentSet)
+	if p := c.Property(supportedComponentSetName); p == nil {
+		return supported
+	} else if err := p.Decode(set); err != nil {
+		return supported
+	}
+	for _, comp := range set.Components {
+		supported[values.ComponentName(strings.ToUpper(string(comp.Name)))] = true
+	}
+	return supported
+}
+
+// escapes a path for use in an href
+func escapePath(path string) string {
+	return (&url.URL{Path: path}).EscapedPath()
+}
+
+// cleans a path, keeping its trailing slash
+func cleanPath(p string) string {
+	clean := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && clean != "/" {
+		clean += "/"
+	}
+	return clean
+}
+
+// adds a trailing slash to a non-empty path
+func withSlash(path string) string {
+	if path != "" && !strings.HasSuffix(path, "/") {
+		return path + "/"
+	}
+	return path
+}
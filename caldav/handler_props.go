@@ -0,0 +1,372 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	cent "github.com/dolanor/caldav-go/caldav/entities"
+	"github.com/dolanor/caldav-go/webdav"
+	"github.com/dolanor/caldav-go/webdav/entities"
+)
+
+// the names of the live properties served by a Handler
+var (
+	resourceTypeName          = xml.Name{Space: davNamespace, Local: "resourcetype"}
+	getETagName               = xml.Name{Space: davNamespace, Local: "getetag"}
+	getContentTypeName        = xml.Name{Space: davNamespace, Local: "getcontenttype"}
+	getContentLengthName      = xml.Name{Space: davNamespace, Local: "getcontentlength"}
+	getLastModifiedName       = xml.Name{Space: davNamespace, Local: "getlastmodified"}
+	syncTokenName             = xml.Name{Space: davNamespace, Local: "sync-token"}
+	supportedReportSetName    = xml.Name{Space: davNamespace, Local: "supported-report-set"}
+	currentUserPrincipalName  = xml.Name{Space: davNamespace, Local: "current-user-principal"}
+	getCTagName               = xml.Name{Space: "http://calendarserver.org/ns/", Local: "getctag"}
+	calendarHomeSetName       = xml.Name{Space: caldavNamespace, Local: "calendar-home-set"}
+	supportedComponentSetName = xml.Name{Space: caldavNamespace, Local: "supported-calendar-component-set"}
+)
+
+// the properties computed by the handler, which clients may not set.
+// the supported components of a calendar may only be given on its creation
+var protectedProperties = map[xml.Name]bool{
+	resourceTypeName:          true,
+	getETagName:               true,
+	getContentTypeName:        true,
+	getContentLengthName:      true,
+	getLastModifiedName:       true,
+	syncTokenName:             true,
+	supportedReportSetName:    true,
+	currentUserPrincipalName:  true,
+	getCTagName:               true,
+	calendarHomeSetName:       true,
+	supportedComponentSetName: true,
+}
+
+// the condition of a rejected change to a protected property, see RFC 4918 section 16
+var cannotModifyProtectedProperty = Condition{Space: davNamespace, Local: "cannot-modify-protected-property"}
+
+// a PROPFIND request body
+type propfindRequest struct {
+	XMLName  xml.Name             `xml:"DAV: propfind"`
+	PropName *entities.PropName   `xml:",omitempty"`
+	AllProp  *entities.AllProp    `xml:",omitempty"`
+	Include  *entities.Include    `xml:",omitempty"`
+	Prop     *entities.NamedProps `xml:",omitempty"`
+}
+
+// a PROPPATCH request body, whose instructions apply in document order
+type proppatchRequest struct {
+	XMLName      xml.Name                `xml:"DAV: propertyupdate"`
+	Instructions []*proppatchInstruction `xml:",any"`
+}
+
+// a DAV:set or DAV:remove instruction
+type proppatchInstruction struct {
+	XMLName xml.Name
+	Prop    *propertyList `xml:"DAV: prop"`
+}
+
+// properties along with their values
+type propertyList struct {
+	Props []*entities.Property `xml:",any"`
+}
+
+// a MKCALENDAR request body
+type makeCalendarRequest struct {
+	XMLName xml.Name              `xml:"urn:ietf:params:xml:ns:caldav mkcalendar"`
+	Set     *proppatchInstruction `xml:"DAV: set"`
+}
+
+// lists the properties of a resource and, with a depth of 1, of its members
+func (h *Handler) servePropfind(w http.ResponseWriter, r *http.Request) error {
+
+	ctx := r.Context()
+	depth := webdav.Depth(r.Header.Get("Depth"))
+	if depth != webdav.Depth0 && depth != webdav.Depth1 {
+		return newConditionError(http.StatusForbidden, Condition{Space: davNamespace, Local: "propfind-finite-depth"})
+	}
+
+	req := new(propfindRequest)
+	if err := decodeBody(r, req); err == io.EOF {
+		req.AllProp = new(entities.AllProp)
+	} else if err != nil {
+		return err
+	}
+
+	res, err := h.resolve(ctx, r.URL.Path)
+	if err != nil {
+		return err
+	}
+
+	resources := []*resource{res}
+	if depth == webdav.Depth1 && res.collection != nil {
+		if objects, err := h.Backend.ListObjects(ctx, res.path); err != nil {
+			return err
+		} else {
+			for _, o := range objects {
+				resources = append(resources, &resource{path: o.Path, object: o})
+			}
+		}
+	} else if depth == webdav.Depth1 && res.object == nil {
+		if collections, err := h.Backend.ListCollections(ctx, res.path); err != nil {
+			return err
+		} else {
+			for _, c := range collections {
+				resources = append(resources, &resource{path: c.Path, collection: c})
+			}
+		}
+	}
+
+	ms := new(cent.Multistatus)
+	for _, res := range resources {
+		props := h.properties(res)
+		if req.PropName != nil {
+			ms.Responses = append(ms.Responses, propstatResponse(res.path, namesOf(props), true, nil))
+		} else if req.AllProp != nil {
+			var names []xml.Name
+			if req.Include != nil {
+				for _, p := range req.Include.Props {
+					names = append(names, p.XMLName)
+				}
+			}
+			ms.Responses = append(ms.Responses, propstatResponse(res.path, props, true, names))
+		} else if req.Prop != nil {
+			ms.Responses = append(ms.Responses, propstatResponse(res.path, props, false, requestedNames(req.Prop)))
+		}
+	}
+
+	return writeMultistatus(w, ms)
+
+}
+
+// sets and removes the properties of a calendar collection, all of them or none
+func (h *Handler) serveProppatch(w http.ResponseWriter, r *http.Request) error {
+
+	ctx := r.Context()
+	req := new(proppatchRequest)
+	if err := decodeBody(r, req); err == io.EOF {
+		return &handlerError{code: http.StatusBadRequest}
+	} else if err != nil {
+		return err
+	}
+
+	res, err := h.resolve(ctx, r.URL.Path)
+	if err != nil {
+		return err
+	}
+
+	var set []*entities.Property
+	var remove []xml.Name
+	var names []xml.Name
+	var rejected []xml.Name
+	for _, instruction := range req.Instructions {
+		if instruction.Prop == nil {
+			continue
+		}
+		for _, p := range instruction.Prop.Props {
+			names = append(names, p.XMLName)
+			if protectedProperties[p.XMLName] || res.collection == nil {
+				rejected = append(rejected, p.XMLName)
+			} else if instruction.XMLName.Local == "remove" {
+				remove = append(remove, p.XMLName)
+			} else {
+				set = append(set, p)
+			}
+		}
+	}
+
+	var statuses map[xml.Name]int
+	if len(rejected) > 0 {
+		statuses = failedStatuses(names, rejected)
+	} else if _, err := h.Backend.UpdateCollection(ctx, res.path, set, remove); err != nil {
+		return err
+	}
+
+	ms := new(entities.NamedMultistatus)
+	ms.Responses = append(ms.Responses, &entities.NamedResponse{Href: escapePath(res.path), PropStats: namedPropStats(names, statuses)})
+	return writeMultistatus(w, ms)
+
+}
+
+// creates a calendar collection with its initial properties, see RFC 4791 section 5.3.1
+func (h *Handler) serveMakeCalendar(w http.ResponseWriter, r *http.Request) error {
+
+	ctx, path := r.Context(), withSlash(r.URL.Path)
+	req := new(makeCalendarRequest)
+	if err := decodeBody(r, req); err != nil && err != io.EOF {
+		return err
+	}
+
+	if _, err := h.Backend.Collection(ctx, parentPath(path)); err == nil {
+		return newConditionError(http.StatusForbidden, CalendarCollectionLocationOK)
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	} else if res, err := h.resolve(ctx, path); err == nil && (res.object != nil || res.collection != nil) {
+		return newConditionError(http.StatusMethodNotAllowed, ResourceMustBeNull)
+	}
+
+	var props []*entities.Property
+	var names, rejected []xml.Name
+	if req.Set != nil && req.Set.Prop != nil {
+		for _, p := range req.Set.Prop.Props {
+			names = append(names, p.XMLName)
+			if protectedProperties[p.XMLName] && p.XMLName != supportedComponentSetName {
+				rejected = append(rejected, p.XMLName)
+			} else {
+				props = append(props, p)
+			}
+		}
+	}
+
+	if len(rejected) > 0 {
+		resp := &cent.MakeCalendarResponse{PropStats: namedPropStats(names, failedStatuses(names, rejected))}
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusForbidden)
+		return xml.NewEncoder(w).Encode(resp)
+	}
+
+	if _, err := h.Backend.CreateCollection(ctx, path, props); err != nil {
+		return err
+	}
+	w.Header().Set("Location", escapePath(path))
+	w.WriteHeader(http.StatusCreated)
+	return nil
+
+}
+
+// lists the properties of a resource along with their values
+func (h *Handler) properties(res *resource) []*entities.Property {
+
+	var props []*entities.Property
+	add := func(name xml.Name, v interface{}) {
+		if text, ok := v.(string); ok {
+			props = append(props, entities.NewTextProperty(name, text))
+		} else if p, err := entities.NewTypedProperty(name, v); err == nil {
+			props = append(props, p)
+		}
+	}
+
+	if h.Principal != "" {
+		add(currentUserPrincipalName, &entities.CurrentUserPrincipal{Href: escapePath(h.Principal)})
+	}
+
+	if o := res.object; o != nil {
+		add(resourceTypeName, &entities.ResourceType{})
+		add(getETagName, o.ETag)
+		add(getContentTypeName, calendarContentType)
+		add(getContentLengthName, strconv.Itoa(len(o.Data)))
+		add(getLastModifiedName, o.ModTime.UTC().Format(http.TimeFormat))
+		return props
+	}
+
+	if c := res.collection; c != nil {
+		add(resourceTypeName, &entities.ResourceType{
+			Collection: new(entities.ResourceTypeCollection),
+			Calendar:   new(entities.ResourceTypeCalendar),
+		})
+		add(syncTokenName, c.SyncToken)
+		add(getCTagName, c.SyncToken)
+		reports := entities.NewProperty(supportedReportSetName)
+		for _, report := range []string{`<C:calendar-query xmlns:C="urn:ietf:params:xml:ns:caldav"/>`,
+			`<C:calendar-multiget xmlns:C="urn:ietf:params:xml:ns:caldav"/>`, `<sync-collection/>`} {
+			reports.InnerXML += `<supported-report xmlns="DAV:"><report>` + report + `</report></supported-report>`
+		}
+		props = append(props, reports)
+		for _, p := range c.Properties {
+			if !protectedProperties[p.XMLName] || p.XMLName == supportedComponentSetName {
+				props = append(props, p)
+			}
+		}
+		return props
+	}
+
+	add(resourceTypeName, &entities.ResourceType{Collection: new(entities.ResourceTypeCollection)})
+	if h.Home != "" && res.path == withSlash(h.Principal) {
+		add(calendarHomeSetName, &cent.CalendarHomeSet{Hrefs: []string{escapePath(withSlash(h.Home))}})
+	}
+	return props
+
+}
+
+// builds the response listing the properties of a resource: all of them when asked, along with the named ones.
+// named properties that are not found are listed with a 404 status
+func propstatResponse(path string, props []*entities.Property, all bool, names []xml.Name) *cent.Response {
+
+	found, missing := new(cent.Prop), new(cent.Prop)
+	if all {
+		found.Extra = props
+	}
+	for _, name := range names {
+		if found.Property(name) != nil {
+			continue
+		} else if p := entities.FindProperty(props, name); p != nil {
+			found.Extra = append(found.Extra, p)
+		} else {
+			missing.Extra = append(missing.Extra, entities.NewProperty(name))
+		}
+	}
+
+	resp := &cent.Response{Href: escapePath(path)}
+	if len(found.Extra) > 0 || len(missing.Extra) == 0 {
+		resp.PropStats = append(resp.PropStats, &cent.PropStat{Status: entities.NewStatus(http.StatusOK), Prop: found})
+	}
+	if len(missing.Extra) > 0 {
+		resp.PropStats = append(resp.PropStats, &cent.PropStat{Status: entities.NewStatus(http.StatusNotFound), Prop: missing})
+	}
+	return resp
+
+}
+
+// lists the names of the properties asked for
+func requestedNames(prop *entities.NamedProps) []xml.Name {
+	var names []xml.Name
+	for _, p := range prop.Props {
+		names = append(names, p.XMLName)
+	}
+	return names
+}
+
+// strips the values of properties, as asked by a DAV:propname request
+func namesOf(props []*entities.Property) []*entities.Property {
+	var names []*entities.Property
+	for _, p := range props {
+		names = append(names, entities.NewProperty(p.XMLName))
+	}
+	return names
+}
+
+// fails the rejected properties of an update as forbidden, and the others as dependent on them
+func failedStatuses(names []xml.Name, rejected []xml.Name) map[xml.Name]int {
+	statuses := make(map[xml.Name]int)
+	for _, name := range names {
+		statuses[name] = http.StatusFailedDependency
+	}
+	for _, name := range rejected {
+		statuses[name] = http.StatusForbidden
+	}
+	return statuses
+}
+
+// groups properties by status, those without a status having succeeded
+func namedPropStats(names []xml.Name, statuses map[xml.Name]int) []*entities.NamedPropStat {
+	var propstats []*entities.NamedPropStat
+	byStatus := make(map[int]*entities.NamedPropStat)
+	for _, name := range names {
+		code, found := statuses[name]
+		if !found {
+			code = http.StatusOK
+		}
+		ps := byStatus[code]
+		if ps == nil {
+			ps = &entities.NamedPropStat{Status: entities.NewStatus(code), Prop: new(entities.NamedProps)}
+			if code == http.StatusForbidden {
+				ps.Error = &entities.Error{Conditions: []*entities.Property{entities.NewProperty(cannotModifyProtectedProperty.Name())}}
+			}
+			byStatus[code] = ps
+			propstats = append(propstats, ps)
+		}
+		ps.Prop.Props = append(ps.Prop.Props, &entities.NamedProp{XMLName: name})
+	}
+	return propstats
+}
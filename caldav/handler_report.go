@@ -0,0 +1,227 @@
+package caldav
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	cent "github.com/dolanor/caldav-go/caldav/entities"
+	"github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/webdav"
+	"github.com/dolanor/caldav-go/webdav/entities"
+)
+
+// the body of a calendar-query, calendar-multiget or sync-collection report, told apart by their name
+type reportRequest struct {
+	XMLName   xml.Name
+	AllProp   *entities.AllProp `xml:",omitempty"`
+	Prop      *reportProp       `xml:",omitempty"`
+	Filter    *cent.Filter      `xml:",omitempty"`
+	Hrefs     []string          `xml:"DAV: href"`
+	SyncToken string            `xml:"DAV: sync-token"`
+	SyncLevel string            `xml:"DAV: sync-level"`
+	Limit     *cent.Limit       `xml:",omitempty"`
+}
+
+// the properties asked for by a report, calendar data being returned through its own specifier
+type reportProp struct {
+	XMLName      xml.Name              `xml:"DAV: prop"`
+	CalendarData *cent.CalendarData    `xml:",omitempty"`
+	Props        []*entities.NamedProp `xml:",any"`
+}
+
+var (
+	calendarQueryName    = xml.Name{Space: caldavNamespace, Local: "calendar-query"}
+	calendarMultigetName = xml.Name{Space: caldavNamespace, Local: "calendar-multiget"}
+	syncCollectionName   = xml.Name{Space: davNamespace, Local: "sync-collection"}
+)
+
+// answers the calendar-query and calendar-multiget reports of RFC 4791 sections 7.8 and 7.9,
+// and the sync-collection report of RFC 6578
+func (h *Handler) serveReport(w http.ResponseWriter, r *http.Request) error {
+
+	req := new(reportRequest)
+	if err := decodeBody(r, req); err == io.EOF {
+		return &handlerError{code: http.StatusBadRequest}
+	} else if err != nil {
+		return err
+	}
+
+	res, err := h.resolve(r.Context(), r.URL.Path)
+	if err != nil {
+		return err
+	}
+
+	var ms *cent.Multistatus
+	switch req.XMLName {
+	case calendarQueryName:
+		ms, err = h.calendarQuery(r.Context(), res, webdav.Depth(r.Header.Get("Depth")), req)
+	case calendarMultigetName:
+		ms, err = h.calendarMultiget(r.Context(), req)
+	case syncCollectionName:
+		ms, err = h.syncCollection(r.Context(), res, req)
+	default:
+		err = newConditionError(http.StatusForbidden, SupportedReport)
+	}
+
+	if err != nil {
+		return err
+	}
+	return writeMultistatus(w, ms)
+
+}
+
+// lists the calendar objects matching a filter, either the target object or the members of the target calendar
+func (h *Handler) calendarQuery(ctx context.Context, res *resource, depth webdav.Depth, req *reportRequest) (*cent.Multistatus, error) {
+
+	if req.Filter == nil || req.Filter.ComponentFilter == nil {
+		return nil, newConditionError(http.StatusForbidden, ValidFilter)
+	} else if req.Filter.ComponentFilter.Name != values.CalendarComponentName {
+		return nil, newConditionError(http.StatusForbidden, ValidFilter)
+	} else if !supportedCollations(req.Filter.ComponentFilter) {
+		return nil, newConditionError(http.StatusForbidden, SupportedCollation)
+	}
+
+	var objects []*Object
+	if res.object != nil {
+		objects = append(objects, res.object)
+	} else if res.collection != nil && depth != webdav.Depth0 {
+		var err error
+		if objects, err = h.Backend.ListObjects(ctx, res.path); err != nil {
+			return nil, err
+		}
+	}
+
+	ms := new(cent.Multistatus)
+	for _, o := range objects {
+		if _, err := o.Calendar(); err != nil {
+			continue // stored data that cannot be decoded matches no filter
+		} else if matched, err := req.Filter.MatchesData(string(o.Data)); err != nil {
+			return nil, newConditionError(http.StatusForbidden, SupportedFilter)
+		} else if !matched {
+			continue
+		} else if resp, err := h.reportResponse(o, req); err != nil {
+			return nil, err
+		} else {
+			ms.Responses = append(ms.Responses, resp)
+		}
+	}
+
+	return ms, nil
+
+}
+
+// lists the calendar objects of a set of hrefs, missing ones being reported as not found
+func (h *Handler) calendarMultiget(ctx context.Context, req *reportRequest) (*cent.Multistatus, error) {
+
+	ms := new(cent.Multistatus)
+	for _, href := range req.Hrefs {
+		u, err := url.Parse(href)
+		if err != nil {
+			return nil, &handlerError{code: http.StatusBadRequest}
+		}
+		notFound := entities.NewStatus(http.StatusNotFound)
+		if o, err := h.Backend.Object(ctx, u.Path); errors.Is(err, ErrNotFound) {
+			ms.Responses = append(ms.Responses, &cent.Response{Href: escapePath(u.Path), Status: &notFound})
+		} else if err != nil {
+			return nil, err
+		} else if resp, err := h.reportResponse(o, req); err != nil {
+			return nil, err
+		} else {
+			ms.Responses = append(ms.Responses, resp)
+		}
+	}
+
+	return ms, nil
+
+}
+
+// lists the objects of a calendar collection changed since a synchronization token, deleted ones being
+// reported as not found, along with the token of the current state of the collection
+func (h *Handler) syncCollection(ctx context.Context, res *resource, req *reportRequest) (*cent.Multistatus, error) {
+
+	if res.collection == nil {
+		return nil, newConditionError(http.StatusForbidden, SupportedReport)
+	}
+
+	changes, token, err := h.Backend.Changes(ctx, res.path, req.SyncToken)
+	if err != nil {
+		return nil, err
+	} else if req.Limit != nil && req.Limit.NResults < len(changes) {
+		return nil, newConditionError(http.StatusInsufficientStorage, NumberOfMatchesWithinLimits)
+	}
+
+	ms := &cent.Multistatus{SyncToken: token}
+	notFound := entities.NewStatus(http.StatusNotFound)
+	for _, change := range changes {
+		if change.Deleted {
+			ms.Responses = append(ms.Responses, &cent.Response{Href: escapePath(change.Path), Status: &notFound})
+		} else if o, err := h.Backend.Object(ctx, change.Path); errors.Is(err, ErrNotFound) {
+			ms.Responses = append(ms.Responses, &cent.Response{Href: escapePath(change.Path), Status: &notFound})
+		} else if err != nil {
+			return nil, err
+		} else if resp, err := h.reportResponse(o, req); err != nil {
+			return nil, err
+		} else {
+			ms.Responses = append(ms.Responses, resp)
+		}
+	}
+
+	return ms, nil
+
+}
+
+// builds the response listing the properties of a calendar object asked for by a report, along with its
+// calendar data as shaped by the specifier of the request
+func (h *Handler) reportResponse(o *Object, req *reportRequest) (*cent.Response, error) {
+
+	props := h.properties(&resource{path: o.Path, object: o})
+	if req.Prop == nil {
+		return propstatResponse(o.Path, props, req.AllProp != nil, nil), nil
+	}
+
+	resp := propstatResponse(o.Path, props, false, requestedNames(&entities.NamedProps{Props: req.Prop.Props}))
+
+	if spec := req.Prop.CalendarData; spec != nil {
+		data, err := spec.Apply(string(o.Data))
+		if err != nil {
+			return nil, err
+		}
+		found := resp.PropStats[0]
+		if !found.Status.OK() {
+			found = &cent.PropStat{Status: entities.NewStatus(http.StatusOK), Prop: new(cent.Prop)}
+			resp.PropStats = append([]*cent.PropStat{found}, resp.PropStats...)
+		}
+		found.Prop.CalendarData = &cent.CalendarData{Content: data}
+	}
+
+	return resp, nil
+
+}
+
+// checks that the text matches of a filter only use the collations the handler supports
+func supportedCollations(cf *cent.ComponentFilter) bool {
+	supported := func(t *cent.TextMatch) bool {
+		c := t.EffectiveCollation()
+		return c == values.OctetTextCollation || c == values.ASCIICaseMapCollation
+	}
+	for _, pf := range cf.PropertyFilters {
+		if pf.TextMatch != nil && !supported(pf.TextMatch) {
+			return false
+		}
+		for _, param := range pf.ParameterFilters {
+			if param.TextMatch != nil && !supported(param.TextMatch) {
+				return false
+			}
+		}
+	}
+	for _, child := range cf.ComponentFilters {
+		if !supportedCollations(child) {
+			return false
+		}
+	}
+	return true
+}
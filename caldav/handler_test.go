@@ -0,0 +1,238 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"fmt"
+	cent "github.com/dolanor/caldav-go/caldav/entities"
+	cvalues "github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/webdav"
+	. "gopkg.in/check.v1"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type HandlerSuite struct{}
+
+var _ = Suite(new(HandlerSuite))
+
+func TestHandler(t *testing.T) { TestingT(t) }
+
+func (s *HandlerSuite) TestMemoryBackend(c *C) {
+	exerciseHandler(c, NewMemoryBackend())
+}
+
+// runs a client against a handler storing calendars in a backend, covering the methods and reports it serves
+func exerciseHandler(c *C, backend Backend) {
+
+	ts := httptest.NewServer(&Handler{Backend: backend, Principal: "/principals/jon/", Home: "/calendars/jon/"})
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	do := func(method, path, body string, header ...string) (*http.Response, string) {
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		c.Assert(err, IsNil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := http.DefaultClient.Do(req)
+		c.Assert(err, IsNil)
+		data, err := ioutil.ReadAll(resp.Body)
+		c.Assert(err, IsNil)
+		resp.Body.Close()
+		return resp, string(data)
+	}
+
+	// discovery
+	c.Assert(client.ValidateServer("/"), IsNil)
+	principal, err := client.CurrentUserPrincipal("/")
+	c.Assert(err, IsNil)
+	c.Assert(principal, Equals, "/principals/jon/")
+	home, err := client.CalendarHomeSet(principal)
+	c.Assert(err, IsNil)
+	c.Assert(home, Equals, "/calendars/jon/")
+
+	// collections
+	work := home + "work/"
+	options := &CalendarOptions{DisplayName: "Work", SupportedComponents: []cvalues.ComponentName{cvalues.EventComponentName}}
+	c.Assert(client.MakeCalendar(work, options), IsNil)
+	c.Assert(client.MakeCalendar(work), NotNil)
+	c.Assert(client.MakeCalendar(work+"nested/"), ErrorMatches, "(?s).*calendar-collection-location-ok.*")
+	c.Assert(client.MakeCalendar(home+"team/work/"), IsNil)
+	c.Assert(client.MakeCalendar(home+"team/"), NotNil)
+	c.Assert(client.MakeCalendar(home+"team/work/deep/nested/"), NotNil)
+	resp, _ := do("DELETE", home+"team/work/", "")
+	c.Assert(resp.StatusCode, Equals, http.StatusNoContent)
+	c.Assert(client.RenameCalendar(work, "Office"), IsNil)
+	_, err = client.WebDAV().Proppatch(work, &cent.Prop{CTag: "forged"}, nil)
+	c.Assert(err, ErrorMatches, "(?s).*getctag.*403.*")
+
+	calendars, err := client.ListCalendars(home)
+	c.Assert(err, IsNil)
+	c.Assert(calendars, HasLen, 1)
+	c.Assert(calendars[0].Href, Equals, work)
+	c.Assert(calendars[0].DisplayName, Equals, "Office")
+	c.Assert(calendars[0].Supports(cvalues.ToDoComponentName), Equals, false)
+	c.Assert(calendars[0].SyncToken, Not(Equals), "")
+	initial := calendars[0].SyncToken
+
+	// calendar object resources
+	standup := work + "standup.ics"
+	resp, _ = do("PUT", standup, standupCalendar, "If-None-Match", "*", "Content-Type", "text/calendar")
+	c.Assert(resp.StatusCode, Equals, http.StatusCreated)
+	etag := resp.Header.Get("ETag")
+	c.Assert(etag, Not(Equals), "")
+	resp, _ = do("PUT", standup, standupCalendar, "If-None-Match", "*")
+	c.Assert(resp.StatusCode, Equals, http.StatusPreconditionFailed)
+	resp, body := do("PUT", work+"copy.ics", standupCalendar)
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+	c.Assert(body, Matches, "(?s).*no-uid-conflict.*"+standup+".*")
+	resp, body = do("PUT", work+"todo.ics", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:todo\r\nEND:VTODO\r\nEND:VCALENDAR\r\n")
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+	c.Assert(body, Matches, "(?s).*supported-calendar-component.*")
+	resp, _ = do("PUT", home+"missing/standup.ics", standupCalendar)
+	c.Assert(resp.StatusCode, Equals, http.StatusConflict)
+
+	resp, body = do("GET", standup, "")
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(resp.Header.Get("ETag"), Equals, etag)
+	c.Assert(body, Equals, standupCalendar)
+	resp, _ = do("GET", standup, "", "If-None-Match", etag)
+	c.Assert(resp.StatusCode, Equals, http.StatusNotModified)
+
+	// queries expand recurrences unless asked otherwise
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	expand, err := cent.NewEventRangeQuery(start, start.AddDate(0, 0, 21))
+	c.Assert(err, IsNil)
+	events, err := client.QueryEvents(work, expand)
+	c.Assert(err, IsNil)
+	c.Assert(events, HasLen, 2)
+	c.Assert(events[0].RecurrenceRules, HasLen, 0)
+	c.Assert(events[1].Summary, Equals, "Standup (afternoon)")
+
+	limited := Query().Events().InRange(start, start.AddDate(0, 0, 21)).LimitRecurrenceSet(start, start.AddDate(0, 0, 7))
+	objects, err := client.QueryObjects(work, limited.Properties("SUMMARY", "RRULE").Build())
+	c.Assert(err, IsNil)
+	c.Assert(objects, HasLen, 1)
+	c.Assert(objects[0].ETag, Equals, etag)
+	series := objects[0].Series()
+	c.Assert(series, HasLen, 1)
+	c.Assert(series[0].Master.RecurrenceRules, HasLen, 1)
+	c.Assert(series[0].Master.DateStamp, IsNil)
+	c.Assert(series[0].Overrides, HasLen, 0)
+
+	objects, err = client.QueryObjects(work, Query().Events().Where(Prop("SUMMARY").Contains("retro")).Build())
+	c.Assert(err, IsNil)
+	c.Assert(objects, HasLen, 0)
+
+	events, err = client.MultigetEvents(work, standup, work+"missing.ics")
+	c.Assert(events, HasLen, 2)
+	merr, ok := err.(*webdav.MultistatusError)
+	c.Assert(ok, Equals, true)
+	c.Assert(merr.Failures, HasLen, 1)
+	c.Assert(merr.Failures[0].Status.NotFound(), Equals, true)
+
+	// components and properties the typed calendars do not hold are matched and returned
+	tasks := home + "tasks/"
+	todo := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:%s\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	c.Assert(client.MakeCalendar(tasks), IsNil)
+	resp, _ = do("PUT", tasks+"review.ics", reminderCalendar)
+	c.Assert(resp.StatusCode, Equals, http.StatusCreated)
+	resp, _ = do("PUT", tasks+"todo.ics", fmt.Sprintf(todo, "todo"))
+	c.Assert(resp.StatusCode, Equals, http.StatusCreated)
+	report := func(q *QueryBuilder) string {
+		enc, err := xml.Marshal(q.Build())
+		c.Assert(err, IsNil)
+		resp, body := do("REPORT", tasks, string(enc), "Depth", "1")
+		c.Assert(resp.StatusCode, Equals, webdav.StatusMulti)
+		return body
+	}
+	body = report(Query().Events().Where(Comp(cvalues.AlarmComponentName)).Expand(start, start.AddDate(0, 0, 2)))
+	c.Assert(body, Matches, "(?s).*RECURRENCE-ID:20260106T100000Z.*")
+	c.Assert(body, Matches, "(?s).*BEGIN:VALARM.*TRIGGER:-PT15M.*END:VALARM.*")
+	c.Assert(body, Matches, "(?s).*X-FOO:bar.*")
+	c.Assert(body, Not(Matches), "(?s).*(RRULE|20260107T100000Z|todo.ics).*")
+	// events that do not recur are returned as they are, without a RECURRENCE-ID
+	lunch := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:lunch\r\nDTSTAMP:20260101T000000Z\r\n" +
+		"DTSTART:20260105T120000Z\r\nDTEND:20260105T130000Z\r\nSUMMARY:lunch\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	resp, _ = do("PUT", tasks+"lunch.ics", lunch)
+	c.Assert(resp.StatusCode, Equals, http.StatusCreated)
+	body = report(Query().Events().InRange(start, start.AddDate(0, 0, 2)).Expand(start, start.AddDate(0, 0, 2)))
+	c.Assert(body, Matches, "(?s).*RECURRENCE-ID:20260105T100000Z.*")
+	c.Assert(body, Matches, "(?s).*UID:lunch.*DTSTART:20260105T120000Z.*SUMMARY:lunch.*")
+	c.Assert(strings.Count(body, "RECURRENCE-ID"), Equals, 2)
+	body = report(Query().Todos())
+	c.Assert(body, Matches, "(?s).*todo.ics.*BEGIN:VTODO.*")
+	c.Assert(body, Not(Matches), "(?s).*review.ics.*")
+
+	// the UID of an object is freed once the object is given another UID
+	resp, body = do("PUT", tasks+"copy.ics", fmt.Sprintf(todo, "todo"))
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+	c.Assert(body, Matches, "(?s).*no-uid-conflict.*"+tasks+"todo.ics.*")
+	resp, _ = do("PUT", tasks+"todo.ics", fmt.Sprintf(todo, "renamed"))
+	c.Assert(resp.StatusCode, Equals, http.StatusNoContent)
+	resp, _ = do("PUT", tasks+"copy.ics", fmt.Sprintf(todo, "todo"))
+	c.Assert(resp.StatusCode, Equals, http.StatusCreated)
+	resp, _ = do("DELETE", tasks, "")
+	c.Assert(resp.StatusCode, Equals, http.StatusNoContent)
+
+	// synchronization
+	sync := func(token string) (*http.Response, *cent.Multistatus) {
+		enc, err := xml.Marshal(cent.NewSyncCollection(token))
+		c.Assert(err, IsNil)
+		resp, body := do("REPORT", work, string(enc), "Depth", "0")
+		ms := new(cent.Multistatus)
+		if resp.StatusCode == webdav.StatusMulti {
+			c.Assert(xml.Unmarshal([]byte(body), ms), IsNil)
+		}
+		return resp, ms
+	}
+
+	_, ms := sync(initial)
+	c.Assert(ms.Responses, HasLen, 1)
+	c.Assert(ms.Responses[0].Href, Equals, standup)
+	c.Assert(ms.Responses[0].PropStats[0].Prop.GetETag, Equals, etag)
+	c.Assert(ms.Responses[0].PropStats[0].Prop.CalendarData.Content, Equals, standupCalendar)
+	token := ms.SyncToken
+	c.Assert(token, Not(Equals), initial)
+
+	resp, _ = do("DELETE", standup, "", "If-Match", `"stale"`)
+	c.Assert(resp.StatusCode, Equals, http.StatusPreconditionFailed)
+	resp, _ = do("DELETE", standup, "", "If-Match", "W/"+etag)
+	c.Assert(resp.StatusCode, Equals, http.StatusPreconditionFailed)
+	resp, _ = do("PUT", standup, standupCalendar, "If-None-Match", "W/"+etag)
+	c.Assert(resp.StatusCode, Equals, http.StatusPreconditionFailed)
+	resp, _ = do("DELETE", standup, "", "If-Match", etag)
+	c.Assert(resp.StatusCode, Equals, http.StatusNoContent)
+
+	_, ms = sync(token)
+	c.Assert(ms.Responses, HasLen, 1)
+	c.Assert(ms.Responses[0].Status.NotFound(), Equals, true)
+	c.Assert(ms.SyncToken, Not(Equals), token)
+	_, ms = sync("")
+	c.Assert(ms.Responses, HasLen, 0)
+	resp, _ = sync("urn:unknown")
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+
+	resp, _ = do("OPTIONS", work, "")
+	c.Assert(resp.Header.Get("DAV"), Matches, ".*calendar-access.*")
+	resp, _ = do("PROPFIND", work, "", "Depth", "infinity")
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+	resp, _ = do("COPY", work, "")
+	c.Assert(resp.StatusCode, Equals, http.StatusMethodNotAllowed)
+
+	resp, _ = do("DELETE", work, "")
+	c.Assert(resp.StatusCode, Equals, http.StatusNoContent)
+	calendars, err = client.ListCalendars(home)
+	c.Assert(err, IsNil)
+	c.Assert(calendars, HasLen, 0)
+	resp, _ = do("GET", standup, "")
+	c.Assert(resp.StatusCode, Equals, http.StatusNotFound)
+
+}
@@ -0,0 +1,271 @@
+package caldav
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolanor/caldav-go/webdav/entities"
+)
+
+// the prefix of the synchronization tokens issued by the in-memory backend
+const memorySyncTokenPrefix = "urn:x-caldav-go:memory-sync:"
+
+// a Backend keeping calendars in memory, as used for tests and throwaway servers.
+// it is safe for concurrent use
+type MemoryBackend struct {
+	mu          sync.Mutex
+	collections map[string]*memoryCollection
+	// the number of changes made to any collection, giving each change its own synchronization token
+	seq int64
+}
+
+// a calendar collection held in memory, with the log of the changes of its objects
+type memoryCollection struct {
+	props   []*entities.Property
+	objects map[string]*Object
+	// the paths of the objects by UID
+	uids map[string]string
+	// the sequence number at which the collection was created, older tokens being invalid
+	created int64
+	log     []memoryChange
+}
+
+type memoryChange struct {
+	seq int64
+	Change
+}
+
+// creates an empty in-memory backend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{collections: make(map[string]*memoryCollection)}
+}
+
+func (b *MemoryBackend) Collection(ctx context.Context, path string) (*Collection, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, found := b.collections[path]; !found {
+		return nil, ErrNotFound
+	} else {
+		return c.collection(path), nil
+	}
+}
+
+func (b *MemoryBackend) ListCollections(ctx context.Context, parent string) ([]*Collection, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var collections []*Collection
+	for path, c := range b.collections {
+		if parentPath(path) == parent {
+			collections = append(collections, c.collection(path))
+		}
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].Path < collections[j].Path
+	})
+	return collections, nil
+}
+
+func (b *MemoryBackend) CreateCollection(ctx context.Context, path string, props []*entities.Property) (*Collection, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, found := b.collections[path]; found {
+		return nil, ErrExists
+	}
+	for other := range b.collections {
+		if strings.HasPrefix(path, other) || strings.HasPrefix(other, path) {
+			return nil, ErrConflict // calendar collections do not nest
+		}
+	}
+	b.seq++
+	c := &memoryCollection{objects: make(map[string]*Object), uids: make(map[string]string), created: b.seq}
+	c.props = setProperties(nil, props, nil)
+	b.collections[path] = c
+	return c.collection(path), nil
+}
+
+func (b *MemoryBackend) UpdateCollection(ctx context.Context, path string, set []*entities.Property, remove []xml.Name) (*Collection, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, found := b.collections[path]; !found {
+		return nil, ErrNotFound
+	} else {
+		c.props = setProperties(c.props, set, remove)
+		return c.collection(path), nil
+	}
+}
+
+func (b *MemoryBackend) DeleteCollection(ctx context.Context, path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, found := b.collections[path]; !found {
+		return ErrNotFound
+	}
+	delete(b.collections, path)
+	return nil
+}
+
+func (b *MemoryBackend) Object(ctx context.Context, path string) (*Object, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, found := b.collections[parentPath(path)]; !found {
+		return nil, ErrNotFound
+	} else if o, found := c.objects[path]; !found {
+		return nil, ErrNotFound
+	} else {
+		return o, nil
+	}
+}
+
+func (b *MemoryBackend) ListObjects(ctx context.Context, collection string) ([]*Object, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, found := b.collections[collection]
+	if !found {
+		return nil, ErrNotFound
+	}
+	var objects []*Object
+	for _, o := range c.objects {
+		objects = append(objects, o)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Path < objects[j].Path
+	})
+	return objects, nil
+}
+
+func (b *MemoryBackend) PutObject(ctx context.Context, path string, data []byte, conditions *Conditions) (*Object, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, found := b.collections[parentPath(path)]
+	if !found || strings.HasSuffix(path, "/") {
+		return nil, ErrConflict
+	} else if err := conditions.Check(c.objects[path]); err != nil {
+		return nil, err
+	}
+	uid := objectUID(data)
+	if other, found := c.uids[uid]; uid != "" && found && other != path {
+		return nil, &UIDConflictError{Path: other}
+	}
+	o := &Object{Path: path, ETag: contentETag(data), ModTime: time.Now().UTC(), Data: append([]byte(nil), data...)}
+	c.unindex(path)
+	c.objects[path] = o
+	if uid != "" {
+		c.uids[uid] = path
+	}
+	b.seq++
+	c.log = append(c.log, memoryChange{seq: b.seq, Change: Change{Path: path}})
+	return o, nil
+}
+
+func (b *MemoryBackend) DeleteObject(ctx context.Context, path string, conditions *Conditions) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, found := b.collections[parentPath(path)]
+	if !found || c.objects[path] == nil {
+		return ErrNotFound
+	} else if err := conditions.Check(c.objects[path]); err != nil {
+		return err
+	}
+	c.unindex(path)
+	delete(c.objects, path)
+	b.seq++
+	c.log = append(c.log, memoryChange{seq: b.seq, Change: Change{Path: path, Deleted: true}})
+	return nil
+}
+
+func (b *MemoryBackend) Changes(ctx context.Context, collection string, token string) ([]*Change, string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, found := b.collections[collection]
+	if !found {
+		return nil, "", ErrNotFound
+	}
+
+	current := c.collection(collection).SyncToken
+	if token == "" {
+		var changes []*Change
+		for _, o := range c.objects {
+			changes = append(changes, &Change{Path: o.Path})
+		}
+		sortChanges(changes)
+		return changes, current, nil
+	}
+
+	since, err := strconv.ParseInt(strings.TrimPrefix(token, memorySyncTokenPrefix), 10, 64)
+	if err != nil || !strings.HasPrefix(token, memorySyncTokenPrefix) || since < c.created || since > b.seq {
+		return nil, "", ErrInvalidSyncToken
+	}
+
+	latest := make(map[string]*Change)
+	for _, change := range c.log {
+		if change.seq > since {
+			latest[change.Path] = &Change{Path: change.Path, Deleted: change.Deleted}
+		}
+	}
+	var changes []*Change
+	for _, change := range latest {
+		changes = append(changes, change)
+	}
+	sortChanges(changes)
+	return changes, current, nil
+
+}
+
+// returns a snapshot of the collection, whose token is the sequence number of its latest change
+func (c *memoryCollection) collection(path string) *Collection {
+	seq := c.created
+	if len(c.log) > 0 {
+		seq = c.log[len(c.log)-1].seq
+	}
+	props := append([]*entities.Property(nil), c.props...)
+	return &Collection{Path: path, Properties: props, SyncToken: fmt.Sprintf("%s%d", memorySyncTokenPrefix, seq)}
+}
+
+// removes the UID of the object at a path from the index of the collection
+func (c *memoryCollection) unindex(path string) {
+	if o := c.objects[path]; o != nil {
+		if uid := objectUID(o.Data); c.uids[uid] == path {
+			delete(c.uids, uid)
+		}
+	}
+}
+
+// returns a list of properties with some properties set or replaced, and others removed
+func setProperties(props []*entities.Property, set []*entities.Property, remove []xml.Name) []*entities.Property {
+	var updated []*entities.Property
+	for _, p := range props {
+		var replaced bool
+		for _, s := range set {
+			replaced = replaced || s.XMLName == p.XMLName
+		}
+		for _, name := range remove {
+			replaced = replaced || name == p.XMLName
+		}
+		if !replaced {
+			updated = append(updated, p)
+		}
+	}
+	return append(updated, set...)
+}
+
+// sorts changes by path, so that reports list them in a stable order
+func sortChanges(changes []*Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+}
+
+// computes a strong entity tag from the content of an object
+func contentETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
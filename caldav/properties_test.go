@@ -0,0 +1,133 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"fmt"
+	cent "github.com/dolanor/caldav-go/caldav/entities"
+	"github.com/dolanor/caldav-go/webdav"
+	"github.com/dolanor/caldav-go/webdav/entities"
+	. "gopkg.in/check.v1"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type PropertiesSuite struct{}
+
+var _ = Suite(new(PropertiesSuite))
+
+func TestProperties(t *testing.T) { TestingT(t) }
+
+var (
+	ctagName   = xml.Name{Space: "http://calendarserver.org/ns/", Local: "getctag"}
+	ownerName  = xml.Name{Space: "DAV:", Local: "owner"}
+	customName = xml.Name{Space: "http://example.com/ns/", Local: "rating"}
+)
+
+const propertiesResponse = `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/" xmlns:x="http://example.com/ns/">
+ <d:response>
+  <d:href>/calendars/jon/work/</d:href>
+  <d:propstat>
+   <d:prop>
+    <d:displayname>Work</d:displayname>
+    <d:owner><d:href>/principals/jon/</d:href></d:owner>
+    <x:rating scale="5">4 &amp; rising</x:rating>
+   </d:prop>
+   <d:status>HTTP/1.1 200 OK</d:status>
+  </d:propstat>
+ </d:response>
+</d:multistatus>`
+
+func (s *PropertiesSuite) serve(c *C, response string, body *string) (*Client, *httptest.Server) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		*body = string(data)
+		w.WriteHeader(207)
+		fmt.Fprint(w, response)
+	}))
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	return NewDefaultClient(server), ts
+}
+
+func (s *PropertiesSuite) TestRequestSingleProperty(c *C) {
+	data, err := xml.Marshal(cent.NewNamedPropFind(ctagName))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, `<propfind xmlns="DAV:"><prop xmlns="DAV:">`+
+		`<getctag xmlns="http://calendarserver.org/ns/"></getctag></prop></propfind>`)
+}
+
+func (s *PropertiesSuite) TestRequestAllPropsWithInclude(c *C) {
+	data, err := xml.Marshal(cent.NewAllPropsFind(customName))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, `<propfind xmlns="DAV:"><allprop></allprop><include xmlns="DAV:">`+
+		`<rating xmlns="http://example.com/ns/"></rating></include></propfind>`)
+	data, err = xml.Marshal(cent.NewPropNameFind())
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, `<propfind xmlns="DAV:"><propname xmlns="DAV:"></propname></propfind>`)
+}
+
+func (s *PropertiesSuite) TestUnknownPropertiesExposed(c *C) {
+
+	var body string
+	client, ts := s.serve(c, propertiesResponse, &body)
+	defer ts.Close()
+
+	ms, err := client.Propfind("/calendars/jon/work/", webdav.Depth0, cent.NewNamedPropFind(ownerName, customName))
+	c.Assert(err, IsNil)
+	c.Assert(ms.Responses, HasLen, 1)
+
+	prop := ms.Responses[0].PropStats[0].Prop
+	c.Assert(prop.DisplayName, Equals, "Work")
+	c.Assert(prop.Extra, HasLen, 2)
+	c.Assert(prop.Property(ctagName), IsNil)
+
+	rating := prop.Property(customName)
+	c.Assert(rating, NotNil)
+	c.Assert(rating.Text(), Equals, "4 & rising")
+	c.Assert(rating.Attrs, DeepEquals, []xml.Attr{{Name: xml.Name{Local: "scale"}, Value: "5"}})
+
+	owner := new(struct {
+		Href string `xml:"DAV: href"`
+	})
+	c.Assert(prop.Property(ownerName).Decode(owner), IsNil)
+	c.Assert(owner.Href, Equals, "/principals/jon/")
+	c.Assert(prop.Property(ownerName).InnerXML, Equals, `<href xmlns="DAV:">/principals/jon/</href>`)
+
+	// unknown properties survive a round trip
+	data, err := xml.Marshal(prop)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Matches, `.*<rating xmlns="http://example.com/ns/" scale="5">4 &amp; rising</rating>.*`)
+
+}
+
+func (s *PropertiesSuite) TestTypedProperty(c *C) {
+	p, err := entities.NewTypedProperty(customName, 5)
+	c.Assert(err, IsNil)
+	c.Assert(p.Text(), Equals, "5")
+	var n int
+	c.Assert(p.Decode(&n), IsNil)
+	c.Assert(n, Equals, 5)
+	c.Assert(entities.NewTextProperty(customName, "a<b").InnerXML, Equals, "a&lt;b")
+}
+
+func (s *PropertiesSuite) TestPropNames(c *C) {
+
+	var body string
+	client, ts := s.serve(c, `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:" xmlns:x="http://example.com/ns/">
+ <d:response>
+  <d:href>/calendars/jon/work/</d:href>
+  <d:propstat><d:prop><d:displayname/><x:rating/></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`, &body)
+	defer ts.Close()
+
+	names, err := client.WebDAV().PropNames("/calendars/jon/work/", webdav.Depth0)
+	c.Assert(err, IsNil)
+	c.Assert(body, Equals, `<propfind xmlns="DAV:"><propname xmlns="DAV:"></propname></propfind>`)
+	c.Assert(names["/calendars/jon/work/"], DeepEquals, []xml.Name{{Space: "DAV:", Local: "displayname"}, customName})
+
+}
@@ -0,0 +1,134 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"time"
+
+	cent "github.com/dolanor/caldav-go/caldav/entities"
+	cvalues "github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/icalendar/properties"
+)
+
+// builds calendar queries, such as:
+//
+//	Query().Events().InRange(start, end).Where(Prop("CATEGORIES").Contains("work"), Prop("RRULE").NotDefined())
+type QueryBuilder struct {
+	query  *cent.CalendarQuery
+	target *cent.ComponentFilter
+}
+
+// starts a query for the calendar data of the calendar object resources matching the conditions that follow.
+// until a component is selected, the conditions apply to the VCALENDAR component itself
+func Query() *QueryBuilder {
+	root := cent.NewComponentFilter(cvalues.CalendarComponentName)
+	query := new(cent.CalendarQuery)
+	query.Prop = cent.NewPropNames(xml.Name{Space: "DAV:", Local: "getetag"})
+	query.Prop.CalendarData = new(cent.CalendarData)
+	query.Filter = &cent.Filter{ComponentFilter: root}
+	return &QueryBuilder{query: query, target: root}
+}
+
+// restricts the query to calendar objects holding a component, to which the conditions that follow apply
+func (q *QueryBuilder) Component(name cvalues.ComponentName) *QueryBuilder {
+	cf := cent.NewComponentFilter(name)
+	q.query.Filter.ComponentFilter.Where(cf)
+	q.target = cf
+	return q
+}
+
+// restricts the query to calendar objects holding events
+func (q *QueryBuilder) Events() *QueryBuilder {
+	return q.Component(cvalues.EventComponentName)
+}
+
+// restricts the query to calendar objects holding to-dos
+func (q *QueryBuilder) Todos() *QueryBuilder {
+	return q.Component(cvalues.ToDoComponentName)
+}
+
+// restricts the query to calendar objects holding journal entries
+func (q *QueryBuilder) Journals() *QueryBuilder {
+	return q.Component(cvalues.JournalComponentName)
+}
+
+// restricts the query to components overlapping a time range, zero times leaving the range open ended
+func (q *QueryBuilder) InRange(start, end time.Time) *QueryBuilder {
+	q.target.InRange(start, end)
+	return q
+}
+
+// restricts the query to components matching all of the conditions
+func (q *QueryBuilder) Where(conditions ...cent.ComponentCondition) *QueryBuilder {
+	q.target.Where(conditions...)
+	return q
+}
+
+// returns recurring components as one component per instance within a time range, as NewEventRangeQuery does.
+// the instances lose the recurrence rules of their series, which therefore cannot be edited from the results
+func (q *QueryBuilder) Expand(start, end time.Time) *QueryBuilder {
+	q.query.Prop.CalendarData.SetRecurrenceMode(cent.ExpandRecurrences, start, end)
+	return q
+}
+
+// returns recurring components as their master component, along with the overrides of the instances
+// within a time range
+func (q *QueryBuilder) LimitRecurrenceSet(start, end time.Time) *QueryBuilder {
+	q.query.Prop.CalendarData.SetRecurrenceMode(cent.LimitRecurrences, start, end)
+	return q
+}
+
+// returns recurring components as they are stored, which is the default
+func (q *QueryBuilder) AsIs() *QueryBuilder {
+	q.query.Prop.CalendarData.SetRecurrenceMode(cent.RecurrencesAsIs, time.Time{}, time.Time{})
+	return q
+}
+
+// restricts the free/busy time returned in VFREEBUSY components to a time range
+func (q *QueryBuilder) LimitFreeBusySet(start, end time.Time) *QueryBuilder {
+	q.query.Prop.CalendarData.SetFreeBusyLimit(start, end)
+	return q
+}
+
+// returns only some properties of the selected component, along with the properties of the calendar
+// and its time zones. the UID and RECURRENCE-ID properties are always returned, so that results can still be
+// told apart and grouped into series. until a component is selected, the properties are those of the calendar
+func (q *QueryBuilder) Properties(names ...properties.PropertyName) *QueryBuilder {
+	if q.target.Name == cvalues.CalendarComponentName {
+		root := cent.NewComponent(cvalues.CalendarComponentName, names...)
+		root.AllComponents = new(cent.AllComponents)
+		return q.Select(root)
+	}
+	names = append([]properties.PropertyName{properties.UIDPropertyName, properties.RecurrenceIdPropertyName}, names...)
+	root := cent.NewComponent(cvalues.CalendarComponentName)
+	timezone := cent.NewComponent(cvalues.TimeZoneComponentName)
+	timezone.AllComponents = new(cent.AllComponents)
+	root.Components = []*cent.Component{timezone, cent.NewComponent(q.target.Name, names...)}
+	return q.Select(root)
+}
+
+// returns only the parts of the calendar data described by a VCALENDAR component specifier, see RFC 4791 section 9.6.1
+func (q *QueryBuilder) Select(root *cent.Component) *QueryBuilder {
+	q.query.Prop.CalendarData.Component = root
+	return q
+}
+
+// returns the query built so far, ready to be passed to QueryEvents
+func (q *QueryBuilder) Build() *cent.CalendarQuery {
+	return q.query
+}
+
+// creates a condition on the components nested in the component being filtered, such as the VALARM of a VEVENT
+func Comp(name cvalues.ComponentName) *cent.ComponentFilter {
+	return cent.NewComponentFilter(name)
+}
+
+// creates a condition on a property of the component being filtered, matching as long as the property is defined
+// until further restricted
+func Prop(name properties.PropertyName) *cent.PropertyFilter {
+	return cent.NewPropertyFilter(name)
+}
+
+// creates a condition on a parameter of a property, to be given to the WithParams method of a property condition
+func Param(name properties.ParameterName) *cent.ParameterFilter {
+	return cent.NewParameterFilter(name)
+}
@@ -0,0 +1,588 @@
+package caldav
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	cent "github.com/dolanor/caldav-go/caldav/entities"
+	cvalues "github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/icalendar"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/webdav"
+	. "gopkg.in/check.v1"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type QuerySuite struct{}
+
+var _ = Suite(new(QuerySuite))
+
+func TestQuery(t *testing.T) { TestingT(t) }
+
+const queryResponse = `<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+ <D:response>
+  <D:href>/calendars/jon/work/standup.ics</D:href>
+  <D:propstat>
+   <D:prop>
+    <D:getetag>"1"</D:getetag>
+    <C:calendar-data>BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//EN
+BEGIN:VEVENT
+UID:standup
+DTSTAMP:20260101T090000Z
+DTSTART:20260105T090000Z
+DTEND:20260105T091500Z
+SUMMARY:Standup
+END:VEVENT
+END:VCALENDAR
+</C:calendar-data>
+   </D:prop>
+   <D:status>HTTP/1.1 200 OK</D:status>
+  </D:propstat>
+ </D:response>
+ <D:response>
+  <D:href>/calendars/jon/work/private.ics</D:href>
+  <D:status>HTTP/1.1 403 Forbidden</D:status>
+  <D:responsedescription>private event</D:responsedescription>
+ </D:response>
+ <D:response>
+  <D:href>/calendars/jon/work/broken.ics</D:href>
+  <D:propstat>
+   <D:prop><D:getetag>"2"</D:getetag></D:prop>
+   <D:status>HTTP/1.1 200 OK</D:status>
+  </D:propstat>
+  <D:propstat>
+   <D:prop><C:calendar-data/></D:prop>
+   <D:status>HTTP/1.1 404 Not Found</D:status>
+  </D:propstat>
+ </D:response>
+ <D:response>
+  <D:href>/calendars/jon/old/</D:href>
+  <D:status>HTTP/1.1 301 Moved Permanently</D:status>
+  <D:location><D:href>/calendars/jon/new/</D:href></D:location>
+ </D:response>
+</D:multistatus>`
+
+func (s *QuerySuite) TestQueryEventsPartialFailure(c *C) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(207)
+		fmt.Fprint(w, queryResponse)
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query, err := cent.NewEventRangeQuery(start, start.AddDate(0, 1, 0))
+	c.Assert(err, IsNil)
+
+	events, err := client.QueryEvents("/calendars/jon/work/", query)
+	c.Assert(events, HasLen, 1)
+	c.Assert(events[0].UID, Equals, "standup")
+
+	merr, ok := err.(*webdav.MultistatusError)
+	c.Assert(ok, Equals, true)
+	c.Assert(merr.Failures, HasLen, 3)
+	c.Assert(merr.Failures[0].Href, Equals, "/calendars/jon/work/private.ics")
+	c.Assert(merr.Failures[0].Status.Forbidden(), Equals, true)
+	c.Assert(merr.Failures[0].Description, Equals, "private event")
+	c.Assert(merr.Failures[1].Href, Equals, "/calendars/jon/work/broken.ics")
+	c.Assert(merr.Failures[1].Status.NotFound(), Equals, true)
+	c.Assert(merr.Failures[2].Status.Code, Equals, http.StatusMovedPermanently)
+	c.Assert(err, ErrorMatches, "3 resources failed: /calendars/jon/work/private.ics: HTTP/1.1 403 Forbidden \\(private event\\); .*")
+
+}
+
+func (s *QuerySuite) TestMultistatusStatuses(c *C) {
+
+	ms := new(cent.Multistatus)
+	c.Assert(xml.Unmarshal([]byte(queryResponse), ms), IsNil)
+	c.Assert(ms.Responses, HasLen, 4)
+
+	ok := ms.Responses[0]
+	c.Assert(ok.Status, IsNil)
+	c.Assert(ok.Failed(), Equals, false)
+	c.Assert(ok.PropStats[0].Status.OK(), Equals, true)
+	c.Assert(ok.PropStats[0].Status.Code, Equals, http.StatusOK)
+
+	moved := ms.Responses[3]
+	c.Assert(moved.Failed(), Equals, true)
+	c.Assert(moved.Location, NotNil)
+	c.Assert(moved.Location.Href, Equals, "/calendars/jon/new/")
+
+	// odd status lines are kept as is, failing only their own response
+	ms = new(cent.Multistatus)
+	c.Assert(xml.Unmarshal([]byte(`<multistatus xmlns="DAV:"><response><href>/</href><status>bogus</status></response>`+
+		`<response><href>/empty</href><status></status></response></multistatus>`), ms), IsNil)
+	c.Assert(ms.Responses, HasLen, 2)
+	c.Assert(ms.Responses[0].Status.Line, Equals, "bogus")
+	c.Assert(ms.Responses[0].Status.Code, Equals, 0)
+	c.Assert(ms.Responses[0].Failed(), Equals, true)
+	c.Assert(ms.Responses[1].Status.String(), Equals, "missing status")
+
+}
+
+func (s *QuerySuite) TestQueryEventsFuncStop(c *C) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(207)
+		fmt.Fprint(w, queryResponse)
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query, err := cent.NewEventRangeQuery(start, start.AddDate(0, 1, 0))
+	c.Assert(err, IsNil)
+
+	var hrefs []string
+	err = client.QueryEventsFunc("/calendars/jon/work/", query, func(href string, events []*components.Event) error {
+		hrefs = append(hrefs, href)
+		return webdav.ErrStop
+	})
+	c.Assert(err, IsNil)
+	c.Assert(hrefs, DeepEquals, []string{"/calendars/jon/work/standup.ics"})
+
+	failure := errors.New("failure")
+	err = client.QueryEventsFunc("/calendars/jon/work/", query, func(href string, events []*components.Event) error {
+		return failure
+	})
+	c.Assert(err, Equals, failure)
+
+}
+
+func (s *QuerySuite) TestMultigetEvents(c *C) {
+
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(207)
+		fmt.Fprint(w, strings.Replace(queryResponse, "HTTP/1.1 403 Forbidden", "HTTP/1.1 404 Not Found", 1))
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	events, err := client.MultigetEvents("/calendars/jon/work/", "/calendars/jon/work/standup.ics", "/calendars/jon/work/private.ics")
+	c.Assert(body, Matches, `<calendar-multiget xmlns="urn:ietf:params:xml:ns:caldav"><prop xmlns="DAV:">.*</prop>`+
+		`<href xmlns="DAV:">/calendars/jon/work/standup.ics</href><href xmlns="DAV:">/calendars/jon/work/private.ics</href></calendar-multiget>`)
+	c.Assert(strings.Contains(body, "getetag"), Equals, true)
+	c.Assert(strings.Contains(body, "calendar-data"), Equals, true)
+	c.Assert(events, HasLen, 1)
+	c.Assert(events[0].UID, Equals, "standup")
+
+	merr, ok := err.(*webdav.MultistatusError)
+	c.Assert(ok, Equals, true)
+	c.Assert(merr.Failures[0].Status.NotFound(), Equals, true)
+
+	_, err = client.MultigetEvents("/calendars/jon/work/")
+	c.Assert(err, ErrorMatches, "(?s).*no calendar object hrefs provided.*")
+
+}
+
+func (s *QuerySuite) TestMultistatusDecoder(c *C) {
+
+	d := webdav.NewMultistatusDecoder(strings.NewReader(`<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:">
+ <D:response><D:href>/a</D:href><D:status>HTTP/1.1 200 OK</D:status></D:response>
+ <D:sync-token>token</D:sync-token>
+ <D:response><D:href>/b</D:href><D:status>HTTP/1.1 404 Not Found</D:status></D:response>
+ <D:responsedescription>done</D:responsedescription>
+</D:multistatus>`))
+
+	var hrefs []string
+	for {
+		r := new(cent.Response)
+		if err := d.Next(r); err == io.EOF {
+			break
+		} else {
+			c.Assert(err, IsNil)
+			hrefs = append(hrefs, r.Href)
+		}
+	}
+	c.Assert(hrefs, DeepEquals, []string{"/a", "/b"})
+	c.Assert(d.ResponseDescription, Equals, "done")
+	c.Assert(d.Next(new(cent.Response)), Equals, io.EOF)
+
+	d = webdav.NewMultistatusDecoder(strings.NewReader(`<D:error xmlns:D="DAV:"/>`))
+	c.Assert(d.Next(new(cent.Response)), ErrorMatches, "(?s).*unexpected element DAV: error.*")
+
+	d = webdav.NewMultistatusDecoder(strings.NewReader(`<D:multistatus xmlns:D="DAV:"><D:response><D:href>/a</D:href>`))
+	c.Assert(d.Next(new(cent.Response)), NotNil)
+
+}
+
+func (s *QuerySuite) TestQueryEventsContext(c *C) {
+
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query, err := cent.NewEventRangeQuery(start, start.AddDate(0, 1, 0))
+	c.Assert(err, IsNil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	events, err := client.QueryEventsContext(ctx, "/calendars/jon/work/", query)
+	c.Assert(events, HasLen, 0)
+	c.Assert(err, ErrorMatches, "(?s).*context deadline exceeded.*")
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	c.Assert(client.ValidateServerContext(ctx, "/"), ErrorMatches, "(?s).*context canceled.*")
+
+}
+
+func (s *QuerySuite) TestQueryBuilder(c *C) {
+
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	query := Query().Events().InRange(start, start.AddDate(0, 0, 7)).Where(
+		Prop("CATEGORIES").Contains("Work & Play"),
+		Prop("RRULE").NotDefined(),
+		Prop("SUMMARY").NotContains("Standup").Collation(cvalues.OctetTextCollation),
+		Prop("ATTENDEE").WithParams(Param("PARTSTAT").Contains("NEEDS-ACTION")),
+		Comp(cvalues.AlarmComponentName).InRange(start, time.Time{}),
+	).Build()
+
+	enc, err := xml.Marshal(query.Filter)
+	c.Assert(err, IsNil)
+	stripped := strings.Replace(string(enc), ` xmlns="urn:ietf:params:xml:ns:caldav"`, "", -1)
+	c.Assert(stripped, Equals, `<filter><comp-filter name="VCALENDAR">`+
+		`<comp-filter name="VEVENT"><time-range start="20260105T000000Z" end="20260112T000000Z"></time-range>`+
+		`<prop-filter name="CATEGORIES"><text-match>Work &amp; Play</text-match></prop-filter>`+
+		`<prop-filter name="RRULE"><is-not-defined></is-not-defined></prop-filter>`+
+		`<prop-filter name="SUMMARY"><text-match collation="i;octet" negate-condition="yes">Standup</text-match></prop-filter>`+
+		`<prop-filter name="ATTENDEE"><param-filter name="PARTSTAT"><text-match>NEEDS-ACTION</text-match></param-filter></prop-filter>`+
+		`<comp-filter name="VALARM"><time-range start="20260105T000000Z"></time-range></comp-filter>`+
+		`</comp-filter></comp-filter></filter>`)
+
+	// the filter decodes back into the same tree
+	decoded := new(cent.Filter)
+	c.Assert(xml.Unmarshal(enc, decoded), IsNil)
+	events := decoded.ComponentFilter.ComponentFilters[0]
+	c.Assert(events.TimeRange.EndTime.NativeTime(), Equals, start.AddDate(0, 0, 7))
+	c.Assert(events.PropertyFilters, HasLen, 4)
+	c.Assert(events.PropertyFilters[1].IsNotDefined, NotNil)
+	c.Assert(events.PropertyFilters[2].TextMatch.Negated(), Equals, true)
+	c.Assert(events.PropertyFilters[0].TextMatch.Content, Equals, "Work & Play")
+	c.Assert(events.PropertyFilters[0].TextMatch.EffectiveCollation(), Equals, cvalues.TextCollation(cvalues.ASCIICaseMapCollation))
+	c.Assert(events.ComponentFilters[0].TimeRange.EndTime, IsNil)
+
+}
+
+const standupCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//EN
+BEGIN:VEVENT
+UID:standup
+DTSTAMP:20251220T090000Z
+DTSTART:20260105T090000Z
+DURATION:PT15M
+SUMMARY:Standup
+CATEGORIES:Work,Meetings
+RRULE:FREQ=WEEKLY
+EXDATE:20260119T090000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:standup
+DTSTAMP:20251220T090000Z
+RECURRENCE-ID:20260112T090000Z
+DTSTART:20260112T140000Z
+DURATION:PT15M
+SUMMARY:Standup (afternoon)
+END:VEVENT
+END:VCALENDAR
+`
+
+const holidayCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//EN
+BEGIN:VEVENT
+UID:holiday
+DTSTAMP:20251201T120000Z
+DTSTART;VALUE=DATE:20260110
+DTEND;VALUE=DATE:20260111
+SUMMARY:Day off
+ORGANIZER:mailto:jon@example.com
+ATTENDEE;PARTSTAT=ACCEPTED:mailto:matthew@example.com
+END:VEVENT
+END:VCALENDAR
+`
+
+func (s *QuerySuite) TestFilterMatches(c *C) {
+
+	standup, holiday := standupCalendar, holidayCalendar
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 1, day, hour, 0, 0, 0, time.UTC)
+	}
+	matches := func(q *QueryBuilder, data string) bool {
+		matched, err := q.Build().Filter.MatchesData(data)
+		c.Assert(err, IsNil)
+		return matched
+	}
+
+	// recurring events match through their instances, overridden instances through their override
+	c.Assert(matches(Query().Events().InRange(at(12, 13), at(12, 15)), standup), Equals, true)
+	c.Assert(matches(Query().Events().InRange(at(12, 8), at(12, 10)), standup), Equals, false)
+	c.Assert(matches(Query().Events().InRange(at(19, 8), at(19, 10)), standup), Equals, false)
+	c.Assert(matches(Query().Events().InRange(at(26, 8), at(26, 10)), standup), Equals, true)
+	c.Assert(matches(Query().Events().InRange(at(5, 9).Add(15*time.Minute), at(5, 10)), standup), Equals, false)
+	c.Assert(matches(Query().Events().InRange(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}), standup), Equals, true)
+
+	// all-day events last the whole day
+	c.Assert(matches(Query().Events().InRange(at(10, 23), at(11, 1)), holiday), Equals, true)
+	c.Assert(matches(Query().Events().InRange(at(11, 0), at(11, 1)), holiday), Equals, false)
+	c.Assert(matches(Query().Events().InRange(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}), holiday), Equals, false)
+
+	// text matches ignore ASCII case unless asked otherwise
+	c.Assert(matches(Query().Events().Where(Prop("CATEGORIES").Contains("work")), standup), Equals, true)
+	c.Assert(matches(Query().Events().Where(Prop("CATEGORIES").Contains("work").Collation(cvalues.OctetTextCollation)), standup), Equals, false)
+	c.Assert(matches(Query().Events().Where(Prop("SUMMARY").NotContains("standup")), standup), Equals, false)
+	c.Assert(matches(Query().Events().Where(Prop("SUMMARY").NotContains("standup")), holiday), Equals, true)
+
+	// all the conditions must match the same component
+	c.Assert(matches(Query().Events().Where(Prop("RRULE").NotDefined()), standup), Equals, true)
+	c.Assert(matches(Query().Events().Where(Prop("CATEGORIES").Contains("work"), Prop("RRULE").NotDefined()), standup), Equals, false)
+	c.Assert(matches(Query().Events().Where(Prop("RECURRENCE-ID")), holiday), Equals, false)
+
+	c.Assert(matches(Query().Events().Where(Prop("ATTENDEE").WithParams(Param("PARTSTAT").Contains("accepted"))), holiday), Equals, true)
+	c.Assert(matches(Query().Events().Where(Prop("ATTENDEE").WithParams(Param("ROLE").NotDefined())), holiday), Equals, true)
+	c.Assert(matches(Query().Events().Where(Prop("ORGANIZER").WithParams(Param("PARTSTAT"))), holiday), Equals, false)
+
+	december := Prop("DTSTAMP").InRange(at(1, 0).AddDate(0, -1, 0), at(1, 0).AddDate(0, 0, -20))
+	c.Assert(matches(Query().Events().Where(december), holiday), Equals, true)
+	c.Assert(matches(Query().Events().Where(december), standup), Equals, false)
+
+	c.Assert(matches(Query().Where(Comp(cvalues.ToDoComponentName).NotDefined()), standup), Equals, true)
+	c.Assert(matches(Query().Todos(), standup), Equals, false)
+
+	_, err := Query().Events().Where(Prop("SUMMARY").Contains("a").Collation("i;unknown")).Build().Filter.MatchesData(standup)
+	c.Assert(err, NotNil)
+
+	// typed calendars are matched once encoded
+	cal := new(components.Calendar)
+	c.Assert(icalendar.Unmarshal(holidayCalendar, cal), IsNil)
+	matched, err := Query().Events().InRange(at(10, 0), at(11, 0)).Build().Filter.Matches(cal)
+	c.Assert(err, IsNil)
+	c.Assert(matched, Equals, true)
+
+}
+
+const reminderCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//EN
+BEGIN:VEVENT
+UID:review
+DTSTAMP:20251220T090000Z
+DTSTART:20260105T100000Z
+DURATION:PT1H
+SUMMARY:Review
+RRULE:FREQ=DAILY;COUNT=3
+X-FOO:bar
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:Review soon
+TRIGGER:-PT15M
+REPEAT:1
+DURATION:PT10M
+END:VALARM
+END:VEVENT
+END:VCALENDAR
+`
+
+const todoCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//EN
+BEGIN:VTODO
+UID:report
+DTSTAMP:20251220T090000Z
+DTSTART:20260105T090000Z
+DUE:20260107T170000Z
+SUMMARY:Write the report
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:Report due
+TRIGGER;RELATED=END:-P1D
+END:VALARM
+END:VTODO
+BEGIN:VJOURNAL
+UID:notes
+DTSTAMP:20251220T090000Z
+DTSTART;VALUE=DATE:20260106
+SUMMARY:Notes
+END:VJOURNAL
+END:VCALENDAR
+`
+
+func (s *QuerySuite) TestFilterMatchesComponents(c *C) {
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 1, day, hour, minute, 0, 0, time.UTC)
+	}
+	matches := func(q *QueryBuilder, data string) bool {
+		matched, err := q.Build().Filter.MatchesData(data)
+		c.Assert(err, IsNil)
+		return matched
+	}
+	alarms := func(start, end time.Time) *QueryBuilder {
+		return Query().Events().Where(Comp(cvalues.AlarmComponentName).InRange(start, end))
+	}
+
+	// components and properties the typed calendar does not hold are matched too
+	c.Assert(matches(Query().Events().Where(Comp(cvalues.AlarmComponentName)), reminderCalendar), Equals, true)
+	c.Assert(matches(Query().Events().Where(Prop("X-FOO").Contains("bar")), reminderCalendar), Equals, true)
+	c.Assert(matches(Query().Todos(), todoCalendar), Equals, true)
+	c.Assert(matches(Query().Where(Comp(cvalues.ToDoComponentName).NotDefined()), todoCalendar), Equals, false)
+	c.Assert(matches(Query().Todos().Where(Prop("SUMMARY").Contains("report")), todoCalendar), Equals, true)
+
+	// alarms trigger for each instance of their event, repetitions included
+	c.Assert(matches(alarms(at(6, 9, 40), at(6, 9, 50)), reminderCalendar), Equals, true)
+	c.Assert(matches(alarms(at(6, 9, 50), at(6, 9, 56)), reminderCalendar), Equals, true)
+	c.Assert(matches(alarms(at(6, 9, 0), at(6, 9, 40)), reminderCalendar), Equals, false)
+	c.Assert(matches(alarms(at(8, 9, 0), at(8, 10, 0)), reminderCalendar), Equals, false)
+
+	// to-dos overlap the range between their start and due dates, and their alarms trigger relative to them
+	c.Assert(matches(Query().Todos().InRange(at(6, 0, 0), at(6, 1, 0)), todoCalendar), Equals, true)
+	c.Assert(matches(Query().Todos().InRange(at(8, 0, 0), at(9, 0, 0)), todoCalendar), Equals, false)
+	todoAlarms := func(start, end time.Time) *QueryBuilder {
+		return Query().Todos().Where(Comp(cvalues.AlarmComponentName).InRange(start, end))
+	}
+	c.Assert(matches(todoAlarms(at(6, 17, 0), at(6, 18, 0)), todoCalendar), Equals, true)
+	c.Assert(matches(todoAlarms(at(7, 16, 0), at(7, 18, 0)), todoCalendar), Equals, false)
+
+	// journals starting on a date last the whole day
+	c.Assert(matches(Query().Journals().InRange(at(6, 23, 0), at(7, 0, 0)), todoCalendar), Equals, true)
+	c.Assert(matches(Query().Journals().InRange(at(7, 0, 0), at(8, 0, 0)), todoCalendar), Equals, false)
+
+	// recurring to-dos and calendars cannot be matched against time ranges
+	recurring := strings.Replace(todoCalendar, "DUE:", "RRULE:FREQ=WEEKLY\nDUE:", 1)
+	_, err := Query().Todos().InRange(at(6, 0, 0), at(7, 0, 0)).Build().Filter.MatchesData(recurring)
+	c.Assert(err, NotNil)
+	_, err = Query().InRange(at(6, 0, 0), at(7, 0, 0)).Build().Filter.MatchesData(todoCalendar)
+	c.Assert(err, NotNil)
+
+}
+
+func (s *QuerySuite) TestLimitRecurrenceSet(c *C) {
+
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	query := Query().Events().InRange(start, start.AddDate(0, 0, 14)).
+		LimitRecurrenceSet(start, start.AddDate(0, 0, 14)).
+		LimitFreeBusySet(start, time.Time{}).
+		Properties("SUMMARY", "DTSTART", "RRULE").
+		Build()
+
+	enc, err := xml.Marshal(query.Prop.CalendarData)
+	c.Assert(err, IsNil)
+	stripped := strings.Replace(string(enc), ` xmlns="urn:ietf:params:xml:ns:caldav"`, "", -1)
+	c.Assert(stripped, Equals, `<calendar-data><comp name="VCALENDAR"><allprop></allprop>`+
+		`<comp name="VTIMEZONE"><allprop></allprop><allcomp></allcomp></comp>`+
+		`<comp name="VEVENT"><prop name="UID"></prop><prop name="RECURRENCE-ID"></prop>`+
+		`<prop name="SUMMARY"></prop><prop name="DTSTART"></prop><prop name="RRULE"></prop></comp></comp>`+
+		`<limit-recurrence-set start="20260105T000000Z" end="20260119T000000Z"></limit-recurrence-set>`+
+		`<limit-freebusy-set start="20260105T000000Z" end="99991231T235959Z"></limit-freebusy-set></calendar-data>`)
+	c.Assert(query.Prop.CalendarData.RecurrenceMode(), Equals, cent.LimitRecurrences)
+
+	// the query asks for the entity tags, and the modes replace each other
+	c.Assert(query.Prop.Extra, HasLen, 1)
+	c.Assert(Query().AsIs().Build().Prop.CalendarData.RecurrenceMode(), Equals, cent.RecurrencesAsIs)
+	c.Assert(Query().LimitRecurrenceSet(start, start).Expand(start, start).Build().Prop.CalendarData.RecurrenceSetLimit, IsNil)
+
+	// range queries still expand recurrences unless asked otherwise
+	expanded, err := cent.NewEventRangeQuery(start, start.AddDate(0, 0, 7))
+	c.Assert(err, IsNil)
+	c.Assert(expanded.Prop.CalendarData.RecurrenceMode(), Equals, cent.ExpandRecurrences)
+	limited, err := cent.NewEventRangeQuery(start, start.AddDate(0, 0, 7), cent.LimitRecurrences)
+	c.Assert(err, IsNil)
+	c.Assert(limited.Prop.CalendarData.RecurrenceSetLimit.EndTime.NativeTime(), Equals, start.AddDate(0, 0, 7))
+	c.Assert(limited.Prop.CalendarData.ExpandRecurrenceSet, IsNil)
+	_, err = cent.NewEventRangeQuery(start, start.AddDate(0, 0, 7), "everything")
+	c.Assert(err, NotNil)
+
+	// the calendar data decodes back into the same specifier
+	decoded := new(cent.CalendarData)
+	c.Assert(xml.Unmarshal(enc, decoded), IsNil)
+	c.Assert(decoded.Component.AllProperties, NotNil)
+	c.Assert(decoded.Component.Components, HasLen, 2)
+	c.Assert(decoded.Component.Components[1].Properties, HasLen, 5)
+	c.Assert(decoded.RecurrenceSetLimit.StartTime.NativeTime(), Equals, start)
+
+}
+
+func (s *QuerySuite) TestQueryObjects(c *C) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !strings.Contains(string(body), "limit-recurrence-set") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(207)
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+ <D:response>
+  <D:href>/calendars/jon/work/standup.ics</D:href>
+  <D:propstat>
+   <D:prop><D:getetag>"42"</D:getetag><C:calendar-data>%s</C:calendar-data></D:prop>
+   <D:status>HTTP/1.1 200 OK</D:status>
+  </D:propstat>
+ </D:response>
+</D:multistatus>`, standupCalendar)
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	query := Query().Events().InRange(start, start.AddDate(0, 0, 14)).LimitRecurrenceSet(start, start.AddDate(0, 0, 14)).Build()
+	objects, err := client.QueryObjects("/calendars/jon/work/", query)
+	c.Assert(err, IsNil)
+	c.Assert(objects, HasLen, 1)
+	c.Assert(objects[0].Href, Equals, "/calendars/jon/work/standup.ics")
+	c.Assert(objects[0].ETag, Equals, `"42"`)
+
+	// the override stays attached to the master, which keeps its recurrence rule
+	series := objects[0].Series()
+	c.Assert(series, HasLen, 1)
+	c.Assert(series[0].UID, Equals, "standup")
+	c.Assert(series[0].IsRecurring(), Equals, true)
+	c.Assert(series[0].Master.RecurrenceRules, HasLen, 1)
+	c.Assert(series[0].Overrides, HasLen, 1)
+	c.Assert(series[0].Override(time.Date(2026, 1, 12, 9, 0, 0, 0, time.UTC)).Summary, Equals, "Standup (afternoon)")
+	c.Assert(series[0].Override(time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)), IsNil)
+	c.Assert(components.ExpandEvents(series[0].Events(), start, start.AddDate(0, 0, 14)), HasLen, 2)
+
+}
@@ -2,6 +2,7 @@ package caldav
 
 import (
 	"bytes"
+	"context"
 	"github.com/dolanor/caldav-go/http"
 	"github.com/dolanor/caldav-go/icalendar"
 	"github.com/dolanor/caldav-go/utils"
@@ -24,10 +25,15 @@ func (r *Request) WebDAV() *webdav.Request {
 
 // creates a new CalDAV request object
 func NewRequest(method string, urlstr string, icaldata ...interface{}) (*Request, error) {
+	return NewRequestWithContext(context.Background(), method, urlstr, icaldata...)
+}
+
+// creates a new CalDAV request object, bound to a context that may cancel it
+func NewRequestWithContext(ctx context.Context, method string, urlstr string, icaldata ...interface{}) (*Request, error) {
 	if buffer, err := icalToReadCloser(icaldata...); err != nil {
-		return nil, utils.NewError(NewRequest, "unable to encode icalendar data", icaldata, err)
-	} else if r, err := http.NewRequest(method, urlstr, buffer); err != nil {
-		return nil, utils.NewError(NewRequest, "unable to create request", urlstr, err)
+		return nil, utils.NewError(NewRequestWithContext, "unable to encode icalendar data", icaldata, err)
+	} else if r, err := http.NewRequestWithContext(ctx, method, urlstr, buffer); err != nil {
+		return nil, utils.NewError(NewRequestWithContext, "unable to create request", urlstr, err)
 	} else {
 		if buffer != nil {
 			// set the content type to XML if we have a body
@@ -0,0 +1,270 @@
+package caldav
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	cent "github.com/dolanor/caldav-go/caldav/entities"
+	cvalues "github.com/dolanor/caldav-go/caldav/values"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	"github.com/dolanor/caldav-go/utils"
+	"github.com/dolanor/caldav-go/webdav"
+	"github.com/dolanor/caldav-go/webdav/entities"
+)
+
+// the scheduling properties of a principal, see RFC 6638 section 2
+type SchedulingInfo struct {
+	// the collection that receives the scheduling messages addressed to the principal
+	InboxUrl string
+	// the collection scheduling messages are submitted to on behalf of the principal
+	OutboxUrl string
+	// the addresses identifying the principal as a calendar user, such as mailto: URIs
+	CalendarUserAddresses []string
+}
+
+// checks if an address identifies the principal, either as a URI or as a bare email address
+func (i *SchedulingInfo) HasAddress(address string) bool {
+	address = strings.TrimPrefix(strings.ToLower(address), "mailto:")
+	for _, a := range i.CalendarUserAddresses {
+		if strings.TrimPrefix(strings.ToLower(a), "mailto:") == address {
+			return true
+		}
+	}
+	return false
+}
+
+// the outcome of a scheduling message for one of its recipients
+type RecipientStatus struct {
+	// the calendar user address of the recipient
+	Recipient string
+	// the request status code, such as "2.0" on success or "3.7" for an invalid calendar user
+	Code string
+	// the human readable description that came along with the code
+	Description string
+	// the data returned for the recipient, such as its free/busy time, nil if none
+	Calendar *components.Calendar
+	// the reason the server gave for failing to deliver the message, nil if none
+	Error *entities.Error
+}
+
+// checks if the message was delivered or is pending delivery, that is if the code is of class 1 or 2
+func (s *RecipientStatus) Delivered() bool {
+	return strings.HasPrefix(s.Code, "1.") || strings.HasPrefix(s.Code, "2.")
+}
+
+// returns the busy periods reported for the recipient, merged into sorted intervals
+func (s *RecipientStatus) BusyPeriods() []*values.Period {
+	var busy []*values.Period
+	if s.Calendar != nil {
+		for _, fb := range s.Calendar.FreeBusy {
+			busy = append(busy, fb.BusyPeriods()...)
+		}
+	}
+	return values.MergePeriods(busy...)
+}
+
+// a scheduling message delivered to an inbox
+type InboxMessage struct {
+	// the path of the message, as used to delete it
+	Href string
+	// the entity tag of the message
+	ETag string
+	// the scheduling message itself, whose method tells a request from a reply or a cancellation
+	Calendar *components.Calendar
+}
+
+// the properties requested on a principal to find its scheduling collections
+var schedulingInfoProps = []xml.Name{
+	{Space: "urn:ietf:params:xml:ns:caldav", Local: "schedule-inbox-URL"},
+	{Space: "urn:ietf:params:xml:ns:caldav", Local: "schedule-outbox-URL"},
+	{Space: "urn:ietf:params:xml:ns:caldav", Local: "calendar-user-address-set"},
+}
+
+// fetches the scheduling inbox, outbox and calendar user addresses of a principal
+func (c *Client) SchedulingInfo(principal string) (*SchedulingInfo, error) {
+	return c.SchedulingInfoContext(context.Background(), principal)
+}
+
+// same as SchedulingInfo, using a context to cancel the request
+func (c *Client) SchedulingInfoContext(ctx context.Context, principal string) (*SchedulingInfo, error) {
+
+	info := new(SchedulingInfo)
+	urlstr := c.Server().WebDAV().Http().AbsUrlStr(principal)
+
+	if ms, err := c.propfind(ctx, urlstr, webdav.Depth0, cent.NewNamedPropFind(schedulingInfoProps...)); err != nil {
+		return nil, utils.NewError(c.SchedulingInfoContext, "unable to fetch scheduling properties", c, err)
+	} else {
+		for _, r := range ms.Responses {
+			for _, p := range r.PropStats {
+				if p.Prop == nil || !p.Status.OK() {
+					continue
+				}
+				if p.Prop.ScheduleInboxUrl != nil {
+					info.InboxUrl = p.Prop.ScheduleInboxUrl.Href
+				}
+				if p.Prop.ScheduleOutboxUrl != nil {
+					info.OutboxUrl = p.Prop.ScheduleOutboxUrl.Href
+				}
+				if p.Prop.CalendarUserAddressSet != nil {
+					info.CalendarUserAddresses = append(info.CalendarUserAddresses, p.Prop.CalendarUserAddressSet.Hrefs...)
+				}
+			}
+		}
+	}
+
+	if info.OutboxUrl == "" && info.InboxUrl == "" {
+		return nil, utils.NewError(c.SchedulingInfoContext, "principal does not support scheduling", c, nil)
+	}
+
+	return info, nil
+
+}
+
+// submits a scheduling message to an outbox, see RFC 6638 section 5.
+// the method of the calendar must be set, the server answers with the delivery status of each recipient
+func (c *Client) Schedule(outbox string, cal *components.Calendar) ([]*RecipientStatus, error) {
+	return c.ScheduleContext(context.Background(), outbox, cal)
+}
+
+// same as Schedule, using a context to cancel the request
+func (c *Client) ScheduleContext(ctx context.Context, outbox string, cal *components.Calendar) ([]*RecipientStatus, error) {
+
+	sr := new(cent.ScheduleResponse)
+
+	if cal == nil || cal.Method == "" {
+		return nil, utils.NewError(c.ScheduleContext, "scheduling messages must have a method", c, nil)
+	} else if req, err := c.Server().NewRequestWithContext(ctx, "POST", outbox, cal); err != nil {
+		return nil, utils.NewError(c.ScheduleContext, "unable to encode request", c, err)
+	} else if req.WebDAV().Http().Native().Header.Set("Content-Type", fmt.Sprintf("text/calendar; charset=UTF-8; method=%s", cal.Method)); false {
+	} else if resp, err := c.Do(req); err != nil {
+		return nil, utils.NewError(c.ScheduleContext, "unable to execute request", c, err)
+	} else if resp.StatusCode != http.StatusOK {
+		err := resp.WebDAV().DecodeError()
+		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
+		return nil, utils.NewError(c.ScheduleContext, msg, c, err)
+	} else if err := resp.WebDAV().Decode(sr); err != nil {
+		return nil, utils.NewError(c.ScheduleContext, "unable to decode response", c, err)
+	}
+
+	var statuses []*RecipientStatus
+	for _, r := range sr.Responses {
+		status := &RecipientStatus{Code: r.StatusCode(), Error: r.Error}
+		if r.Recipient != nil {
+			status.Recipient = r.Recipient.Href
+		}
+		if parts := strings.SplitN(r.RequestStatus, ";", 2); len(parts) > 1 {
+			status.Description = strings.TrimSpace(parts[1])
+		} else {
+			status.Description = r.ResponseDescription
+		}
+		if r.CalendarData != nil && strings.TrimSpace(r.CalendarData.Content) != "" {
+			if cal, err := r.CalendarData.CalendarComponent(); err != nil {
+				msg := fmt.Sprintf("unable to decode calendar data of %s", status.Recipient)
+				return nil, utils.NewError(c.ScheduleContext, msg, c, err)
+			} else {
+				status.Calendar = cal
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+
+}
+
+// asks the server for the free/busy time of attendees on behalf of an organizer, see RFC 6638 section 5.3.
+// the organizer must be one of the calendar user addresses of the principal owning the outbox
+func (c *Client) RequestFreeBusy(outbox string, organizer *values.OrganizerContact, attendees []*values.AttendeeContact, start, end time.Time) ([]*RecipientStatus, error) {
+	return c.RequestFreeBusyContext(context.Background(), outbox, organizer, attendees, start, end)
+}
+
+// same as RequestFreeBusy, using a context to cancel the request
+func (c *Client) RequestFreeBusyContext(ctx context.Context, outbox string, organizer *values.OrganizerContact, attendees []*values.AttendeeContact, start, end time.Time) ([]*RecipientStatus, error) {
+
+	if organizer == nil {
+		return nil, utils.NewError(c.RequestFreeBusyContext, "an organizer must be provided", c, nil)
+	} else if len(attendees) <= 0 {
+		return nil, utils.NewError(c.RequestFreeBusyContext, "no attendees provided", c, nil)
+	}
+
+	fb := components.NewFreeBusy(newScheduleUid(), start.UTC(), end.UTC())
+	fb.Organizer = organizer
+	fb.Attendees = attendees
+
+	cal := components.NewCalendar()
+	cal.Method = values.RequestMethod
+	cal.FreeBusy = append(cal.FreeBusy, fb)
+
+	if statuses, err := c.ScheduleContext(ctx, outbox, cal); err != nil {
+		return nil, utils.NewError(c.RequestFreeBusyContext, "unable to request free/busy time", c, err)
+	} else {
+		return statuses, nil
+	}
+
+}
+
+// lists the scheduling messages waiting in an inbox
+func (c *Client) InboxMessages(inbox string) ([]*InboxMessage, error) {
+	return c.InboxMessagesContext(context.Background(), inbox)
+}
+
+// same as InboxMessages, using a context to cancel the request
+func (c *Client) InboxMessagesContext(ctx context.Context, inbox string) ([]*InboxMessage, error) {
+
+	query := new(cent.CalendarQuery)
+	query.Prop = cent.NewPropNames(xml.Name{Space: "DAV:", Local: "getetag"})
+	query.Prop.CalendarData = new(cent.CalendarData)
+	query.Filter = new(cent.Filter)
+	query.Filter.ComponentFilter = new(cent.ComponentFilter)
+	query.Filter.ComponentFilter.Name = cvalues.CalendarComponentName
+
+	var messages []*InboxMessage
+	err := c.report(ctx, inbox, webdav.Depth1, query, func(r *cent.Response) error {
+		for _, p := range r.PropStats {
+			if p.Prop == nil || p.Prop.CalendarData == nil || !p.Status.OK() {
+				continue
+			} else if cal, err := p.Prop.CalendarData.CalendarComponent(); err != nil {
+				msg := fmt.Sprintf("unable to decode calendar data of %s", r.Href)
+				return utils.NewError(c.InboxMessagesContext, msg, c, err)
+			} else {
+				messages = append(messages, &InboxMessage{Href: r.Href, ETag: p.Prop.GetETag, Calendar: cal})
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, utils.NewError(c.InboxMessagesContext, "unable to list inbox messages", c, err)
+	}
+
+	return messages, nil
+
+}
+
+// removes a scheduling message from an inbox, once it has been processed
+func (c *Client) DeleteInboxMessage(href string) error {
+	return c.DeleteInboxMessageContext(context.Background(), href)
+}
+
+// same as DeleteInboxMessage, using a context to cancel the request
+func (c *Client) DeleteInboxMessageContext(ctx context.Context, href string) error {
+	if err := c.WebDAV().DeleteContext(ctx, href); err != nil {
+		return utils.NewError(c.DeleteInboxMessageContext, "unable to delete inbox message", c, err)
+	}
+	return nil
+}
+
+// generates a unique identifier for a scheduling message
+func newScheduleUid() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return fmt.Sprintf("%d@caldav-go", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%x@caldav-go", id)
+}
@@ -0,0 +1,156 @@
+package caldav
+
+import (
+	"fmt"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	. "gopkg.in/check.v1"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ScheduleSuite struct{}
+
+var _ = Suite(new(ScheduleSuite))
+
+func TestSchedule(t *testing.T) { TestingT(t) }
+
+const schedulingPropsResponse = `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:response>
+ <d:href>/principals/users/cyrus/</d:href>
+ <d:propstat><d:prop>
+  <c:schedule-inbox-URL><d:href>/calendars/cyrus/inbox/</d:href></c:schedule-inbox-URL>
+  <c:schedule-outbox-URL><d:href>/calendars/cyrus/outbox/</d:href></c:schedule-outbox-URL>
+  <c:calendar-user-address-set><d:href>mailto:cyrus@example.com</d:href><d:href>/principals/users/cyrus/</d:href></c:calendar-user-address-set>
+ </d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+</d:response></d:multistatus>`
+
+// the response of the example of RFC 6638 appendix B.5
+const scheduleResponse = `<?xml version="1.0" encoding="utf-8"?>
+<C:schedule-response xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+ <C:response>
+  <C:recipient><D:href>mailto:wilfredo@example.com</D:href></C:recipient>
+  <C:request-status>2.0;Success</C:request-status>
+  <C:calendar-data>BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example Corp.//CalDAV Server//EN
+METHOD:REPLY
+BEGIN:VFREEBUSY
+UID:4FD3AD926350
+DTSTAMP:20090602T200733Z
+DTSTART:20090602T000000Z
+DTEND:20090604T000000Z
+ORGANIZER;CN="Cyrus Daboo":mailto:cyrus@example.com
+ATTENDEE;CN="Wilfredo Sanchez Vega":mailto:wilfredo@example.com
+FREEBUSY;FBTYPE=BUSY:20090602T110000Z/20090602T120000Z
+FREEBUSY;FBTYPE=BUSY:20090603T170000Z/20090603T180000Z
+END:VFREEBUSY
+END:VCALENDAR
+</C:calendar-data>
+ </C:response>
+ <C:response>
+  <C:recipient><D:href>mailto:bernard@example.net</D:href></C:recipient>
+  <C:request-status>3.7;Invalid calendar user</C:request-status>
+ </C:response>
+</C:schedule-response>`
+
+const inboxResponse = `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:response>
+ <d:href>/calendars/cyrus/inbox/reply-1.ics</d:href>
+ <d:propstat><d:prop><d:getetag>"abc"</d:getetag><c:calendar-data>BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example Corp.//CalDAV Server//EN
+METHOD:REPLY
+BEGIN:VEVENT
+UID:9263504FD3AD
+DTSTAMP:20090602T200733Z
+DTSTART:20090602T120000Z
+DTEND:20090602T130000Z
+ORGANIZER:mailto:cyrus@example.com
+ATTENDEE;SCHEDULE-STATUS=2.0:mailto:wilfredo@example.com
+END:VEVENT
+END:VCALENDAR
+</c:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+</d:response></d:multistatus>`
+
+func (s *ScheduleSuite) TestScheduling(c *C) {
+
+	var posted, contentType string
+	var deleted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method == "PROPFIND" && r.URL.Path == "/principals/users/cyrus/":
+			w.WriteHeader(207)
+			fmt.Fprint(w, schedulingPropsResponse)
+		case r.Method == "POST" && r.URL.Path == "/calendars/cyrus/outbox/":
+			posted, contentType = string(data), r.Header.Get("Content-Type")
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprint(w, scheduleResponse)
+		case r.Method == "REPORT" && r.URL.Path == "/calendars/cyrus/inbox/":
+			w.WriteHeader(207)
+			fmt.Fprint(w, inboxResponse)
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	info, err := client.SchedulingInfo("/principals/users/cyrus/")
+	c.Assert(err, IsNil)
+	c.Assert(info.InboxUrl, Equals, "/calendars/cyrus/inbox/")
+	c.Assert(info.OutboxUrl, Equals, "/calendars/cyrus/outbox/")
+	c.Assert(info.CalendarUserAddresses, HasLen, 2)
+	c.Assert(info.HasAddress("cyrus@example.com"), Equals, true)
+	c.Assert(info.HasAddress("mailto:bernard@example.net"), Equals, false)
+
+	organizer := values.NewOrganizerContact("Cyrus Daboo", "cyrus@example.com")
+	attendees := []*values.AttendeeContact{
+		values.NewAttendeeContact("Wilfredo Sanchez Vega", "wilfredo@example.com"),
+		values.NewAttendeeContact("", "bernard@example.net"),
+	}
+	start := time.Date(2009, 6, 2, 0, 0, 0, 0, time.UTC)
+	statuses, err := client.RequestFreeBusy(info.OutboxUrl, organizer, attendees, start, start.AddDate(0, 0, 2))
+	c.Assert(err, IsNil)
+	c.Assert(contentType, Equals, "text/calendar; charset=UTF-8; method=REQUEST")
+	c.Assert(posted, Matches, "(?s).*METHOD:REQUEST\r\n.*BEGIN:VFREEBUSY\r\n.*")
+	c.Assert(posted, Matches, "(?s).*\r\nDTSTART:20090602T000000Z\r\nDTEND:20090604T000000Z\r\n.*")
+	c.Assert(posted, Matches, "(?s).*\r\nATTENDEE:MAILTO:bernard@example.net\r\n.*")
+
+	c.Assert(statuses, HasLen, 2)
+	c.Assert(statuses[0].Recipient, Equals, "mailto:wilfredo@example.com")
+	c.Assert(statuses[0].Code, Equals, "2.0")
+	c.Assert(statuses[0].Delivered(), Equals, true)
+	busy := statuses[0].BusyPeriods()
+	c.Assert(busy, HasLen, 2)
+	c.Assert(busy[1].Start(), Equals, time.Date(2009, 6, 3, 17, 0, 0, 0, time.UTC))
+	c.Assert(statuses[1].Code, Equals, "3.7")
+	c.Assert(statuses[1].Description, Equals, "Invalid calendar user")
+	c.Assert(statuses[1].Delivered(), Equals, false)
+	c.Assert(statuses[1].Calendar, IsNil)
+
+	messages, err := client.InboxMessages(info.InboxUrl)
+	c.Assert(err, IsNil)
+	c.Assert(messages, HasLen, 1)
+	c.Assert(messages[0].ETag, Equals, `"abc"`)
+	c.Assert(messages[0].Calendar.Method, Equals, values.ReplyMethod)
+	c.Assert(messages[0].Calendar.Events, HasLen, 1)
+	c.Assert(strings.ToLower(messages[0].Calendar.Events[0].Organizer.Entry.Address), Equals, "cyrus@example.com")
+
+	c.Assert(client.DeleteInboxMessage(messages[0].Href), IsNil)
+	c.Assert(deleted, DeepEquals, []string{"/calendars/cyrus/inbox/reply-1.ics"})
+
+	_, err = client.SchedulingInfo("/principals/users/unknown/")
+	c.Assert(err, NotNil)
+
+}
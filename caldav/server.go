@@ -1,6 +1,7 @@
 package caldav
 
 import (
+	"context"
 	"github.com/dolanor/caldav-go/utils"
 	"github.com/dolanor/caldav-go/webdav"
 )
@@ -28,3 +29,8 @@ func (s *Server) WebDAV() *webdav.Server {
 func (s *Server) NewRequest(method string, path string, icaldata ...interface{}) (*Request, error) {
 	return NewRequest(method, s.WebDAV().Http().AbsUrlStr(path), icaldata...)
 }
+
+// creates a new CalDAV request object, bound to a context that may cancel it
+func (s *Server) NewRequestWithContext(ctx context.Context, method string, path string, icaldata ...interface{}) (*Request, error) {
+	return NewRequestWithContext(ctx, method, s.WebDAV().Http().AbsUrlStr(path), icaldata...)
+}
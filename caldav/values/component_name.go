@@ -5,4 +5,9 @@ type ComponentName string
 const (
 	CalendarComponentName ComponentName = "VCALENDAR"
 	EventComponentName                  = "VEVENT"
+	ToDoComponentName                   = "VTODO"
+	JournalComponentName                = "VJOURNAL"
+	FreeBusyComponentName               = "VFREEBUSY"
+	TimeZoneComponentName               = "VTIMEZONE"
+	AlarmComponentName                  = "VALARM"
 )
@@ -29,3 +29,19 @@ func (d *DateTime) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
 	attr := xml.Attr{Name: name, Value: value}
 	return attr, nil
 }
+
+// decodes the datetime value from the iCalendar specification
+func (d *DateTime) UnmarshalXMLAttr(attr xml.Attr) error {
+	if t, err := time.Parse(values.UTCDateTimeFormatString, attr.Value); err != nil {
+		return err
+	} else {
+		d.name = attr.Name.Local
+		d.t = t
+		return nil
+	}
+}
+
+// returns the native time of the datetime, in UTC
+func (d *DateTime) NativeTime() time.Time {
+	return d.t
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"github.com/dolanor/caldav-go/utils"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// adds credentials to the requests of a client
+type Authenticator interface {
+	// adds credentials to a request before it is sent
+	Authorize(req *http.Request) error
+	// inspects the challenge of a 401 Unauthorized response to a request
+	// returns true if the request should be authorized and sent once more
+	Challenge(req *http.Request, resp *http.Response) (bool, error)
+}
+
+// authenticates requests with a user name and password, see RFC 7617
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// creates a new authenticator sending a user name and password along with every request
+func NewBasicAuth(username, password string) *BasicAuth {
+	return &BasicAuth{Username: username, Password: password}
+}
+
+func (a *BasicAuth) Authorize(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// the credentials are sent with every request, so a challenge means they were rejected
+func (a *BasicAuth) Challenge(req *http.Request, resp *http.Response) (bool, error) {
+	return false, nil
+}
+
+// returns a bearer token, such as an OAuth2 access token.
+// refresh is true when the last token returned was rejected by the server and a new one is needed.
+type TokenSource func(ctx context.Context, refresh bool) (string, error)
+
+// authenticates requests with bearer tokens, see RFC 6750
+type BearerAuth struct {
+	source TokenSource
+	token  string
+	lock   sync.Mutex
+}
+
+// creates a new authenticator sending tokens from a source along with every request
+// tokens are cached until the server rejects them, at which point the source is asked for a fresh one
+func NewBearerAuth(source TokenSource) *BearerAuth {
+	return &BearerAuth{source: source}
+}
+
+func (a *BearerAuth) Authorize(req *http.Request) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.token == "" {
+		if token, err := a.source(req.Context(), false); err != nil {
+			return utils.NewError(a.Authorize, "unable to fetch token", a, err)
+		} else {
+			a.token = token
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (a *BearerAuth) Challenge(req *http.Request, resp *http.Response) (bool, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if sent := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "); sent != a.token {
+		return true, nil // another request refreshed the token in the meantime
+	} else if token, err := a.source(req.Context(), true); err != nil {
+		return false, utils.NewError(a.Challenge, "unable to refresh token", a, err)
+	} else if token == "" || token == sent {
+		return false, nil
+	} else {
+		a.token = token
+		return true, nil
+	}
+}
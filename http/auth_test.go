@@ -0,0 +1,149 @@
+package http
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	. "gopkg.in/check.v1"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type AuthSuite struct{}
+
+var _ = Suite(new(AuthSuite))
+
+func TestAuth(t *testing.T) { TestingT(t) }
+
+func (s *AuthSuite) client(c *C, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	ts := httptest.NewServer(handler)
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	return NewDefaultClient(server), ts
+}
+
+func (s *AuthSuite) TestBasicAuth(c *C) {
+
+	client, ts := s.client(c, func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "jon" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	defer ts.Close()
+
+	req, err := client.Server().NewRequest("GET", "/")
+	c.Assert(err, IsNil)
+	resp, err := client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusUnauthorized)
+
+	client.SetAuthenticator(NewBasicAuth("jon", "secret"))
+	req, err = client.Server().NewRequest("GET", "/")
+	c.Assert(err, IsNil)
+	resp, err = client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+
+}
+
+func (s *AuthSuite) TestBearerAuthRefresh(c *C) {
+
+	var bodies []string
+	client, ts := s.client(c, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	defer ts.Close()
+
+	var refreshes int
+	tokens := []string{"expired", "fresh"}
+	client.SetAuthenticator(NewBearerAuth(func(ctx context.Context, refresh bool) (string, error) {
+		if refresh {
+			refreshes++
+		}
+		return tokens[refreshes], nil
+	}))
+
+	req, err := client.Server().NewRequest("PUT", "/event.ics", ioutil.NopCloser(strings.NewReader("BEGIN:VCALENDAR")))
+	c.Assert(err, IsNil)
+	resp, err := client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(refreshes, Equals, 1)
+	c.Assert(bodies, DeepEquals, []string{"BEGIN:VCALENDAR", "BEGIN:VCALENDAR"})
+
+	// the refreshed token is cached for later requests
+	req, err = client.Server().NewRequest("GET", "/event.ics")
+	c.Assert(err, IsNil)
+	resp, err = client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(refreshes, Equals, 1)
+
+}
+
+func (s *AuthSuite) TestDigestAuth(c *C) {
+
+	const realm, nonce = "caldav@example.com", "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+	hex := func(value string) string {
+		return fmt.Sprintf("%x", md5.Sum([]byte(value)))
+	}
+
+	var challenges int
+	client, ts := s.client(c, func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if strings.HasPrefix(auth, "Digest ") {
+			p := parseChallenge(strings.TrimPrefix(auth, "Digest "))
+			ha1 := hex("jon:" + realm + ":secret")
+			ha2 := hex(r.Method + ":" + p["uri"])
+			expected := hex(strings.Join([]string{ha1, nonce, p["nc"], p["cnonce"], p["qop"], ha2}, ":"))
+			if p["username"] == "jon" && p["response"] == expected && p["opaque"] == "xyz" && p["uri"] == r.URL.RequestURI() {
+				return
+			}
+		}
+		challenges++
+		w.Header().Add("WWW-Authenticate", `Basic realm="`+realm+`"`)
+		w.Header().Add("WWW-Authenticate", `Digest realm="`+realm+`", qop="auth,auth-int", nonce="`+nonce+`", opaque="xyz"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	defer ts.Close()
+
+	client.SetAuthenticator(NewDigestAuth("jon", "secret"))
+	for i := 0; i < 2; i++ {
+		req, err := client.Server().NewRequest("PROPFIND", "/calendars/jon/?x=1")
+		c.Assert(err, IsNil)
+		resp, err := client.Do(req)
+		c.Assert(err, IsNil)
+		c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	}
+	c.Assert(challenges, Equals, 1)
+
+	// wrong credentials are not retried endlessly
+	client.SetAuthenticator(NewDigestAuth("jon", "wrong"))
+	req, err := client.Server().NewRequest("GET", "/")
+	c.Assert(err, IsNil)
+	resp, err := client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusUnauthorized)
+
+}
+
+func (s *AuthSuite) TestDigestResponse(c *C) {
+	// the example of RFC 2617 section 3.5
+	ha1 := digest(md5.New, "Mufasa", "testrealm@host.com", "Circle Of Life")
+	ha2 := digest(md5.New, "GET", "/dir/index.html")
+	response := digest(md5.New, ha1, "dcd98b7102dd2f0e8b11d0f600bfb0c093", "00000001", "0a4f113b", "auth", ha2)
+	c.Assert(response, Equals, "6629fae49393a05397450978507c4ef1")
+}
+
+func (s *AuthSuite) TestParseChallenge(c *C) {
+	params := parseChallenge(`realm="a, \"b\"", qop="auth", nonce=abc, stale=TRUE`)
+	c.Assert(params, DeepEquals, map[string]string{"realm": `a, "b"`, "qop": "auth", "nonce": "abc", "stale": "TRUE"})
+}
@@ -2,23 +2,49 @@ package http
 
 import (
 	"github.com/dolanor/caldav-go/utils"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"sync"
 )
 
 // a client for making HTTP requests
+// the client is safe for concurrent use, including changes to its headers and middleware
 type Client struct {
 	native         *http.Client
 	server         *Server
 	requestHeaders map[string]string
+	authenticator  Authenticator
+	middleware     []Middleware
+	lock           sync.RWMutex
 }
 
+// sets a header sent along with every request of the client
 func (c *Client) SetHeader(key string, value string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	if c.requestHeaders == nil {
 		c.requestHeaders = map[string]string{}
 	}
 	c.requestHeaders[key] = value
 }
 
+// sets the authenticator adding credentials to every request, nil disables authentication
+// credentials found in the userinfo of the server URL are still sent when no authenticator is set
+func (c *Client) SetAuthenticator(authenticator Authenticator) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.authenticator = authenticator
+}
+
+// appends middleware to the chain wrapping every request of the client
+// the first middleware added is the outermost one, and sees requests before any other
+func (c *Client) Use(middleware ...Middleware) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.middleware = append(c.middleware, middleware...)
+}
+
 // downcasts to the native HTTP interface
 func (c *Client) Native() *http.Client {
 	return c.native
@@ -31,14 +57,73 @@ func (c *Client) Server() *Server {
 
 // executes an HTTP request
 func (c *Client) Do(req *Request) (*Response, error) {
+
+	c.lock.RLock()
 	for key, value := range c.requestHeaders {
 		req.Header.Add(key, value)
 	}
-	if resp, err := c.Native().Do((*http.Request)(req)); err != nil {
+	authenticator := c.authenticator
+	var doer Doer = DoerFunc(func(r *http.Request) (*http.Response, error) {
+		if authenticator == nil {
+			return c.Native().Do(r)
+		} else {
+			return c.authenticate(authenticator, r)
+		}
+	})
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		doer = c.middleware[i](doer)
+	}
+	c.lock.RUnlock()
+
+	if resp, err := doer.Do((*http.Request)(req)); err != nil {
 		return nil, utils.NewError(c.Do, "unable to execute HTTP request", c, err)
 	} else {
 		return NewResponse(resp), nil
 	}
+
+}
+
+// sends a request with credentials, answering a single authentication challenge if the server sends one
+func (c *Client) authenticate(authenticator Authenticator, req *http.Request) (*http.Response, error) {
+
+	if err := authenticator.Authorize(req); err != nil {
+		return nil, utils.NewError(c.authenticate, "unable to authorize request", c, err)
+	}
+
+	resp, err := c.Native().Do(req)
+	if err != nil {
+		return nil, utils.NewError(c.authenticate, "unable to execute request", c, err)
+	} else if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	} else if req.Body != nil && req.GetBody == nil {
+		return resp, nil // the request cannot be replayed
+	}
+
+	if retry, err := authenticator.Challenge(req, resp); err != nil {
+		resp.Body.Close()
+		return nil, utils.NewError(c.authenticate, "unable to answer authentication challenge", c, err)
+	} else if !retry {
+		return resp, nil
+	}
+
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	replay := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if replay.Body, err = req.GetBody(); err != nil {
+			return nil, utils.NewError(c.authenticate, "unable to replay request body", c, err)
+		}
+	}
+
+	if err := authenticator.Authorize(replay); err != nil {
+		return nil, utils.NewError(c.authenticate, "unable to authorize request", c, err)
+	} else if resp, err := c.Native().Do(replay); err != nil {
+		return nil, utils.NewError(c.authenticate, "unable to execute request", c, err)
+	} else {
+		return resp, nil
+	}
+
 }
 
 // creates a new client for communicating with an HTTP server
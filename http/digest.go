@@ -0,0 +1,180 @@
+package http
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/dolanor/caldav-go/utils"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// authenticates requests with the challenge and response scheme of RFC 7616
+type DigestAuth struct {
+	Username  string
+	Password  string
+	challenge map[string]string
+	count     int
+	lock      sync.Mutex
+}
+
+// creates a new authenticator answering digest challenges with a user name and password
+// the first request of a client is sent without credentials, later requests reuse the last challenge
+func NewDigestAuth(username, password string) *DigestAuth {
+	return &DigestAuth{Username: username, Password: password}
+}
+
+func (a *DigestAuth) Authorize(req *http.Request) error {
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.challenge == nil {
+		return nil // wait for the server to send a challenge
+	}
+
+	var h func() hash.Hash
+	algorithm := a.challenge["algorithm"]
+	switch strings.ToUpper(strings.TrimSuffix(strings.ToLower(algorithm), "-sess")) {
+	case "", "MD5":
+		h = md5.New
+	case "SHA-256":
+		h = sha256.New
+	default:
+		return utils.NewError(a.Authorize, "unsupported digest algorithm "+algorithm, a, nil)
+	}
+
+	var cnonce [8]byte
+	if _, err := rand.Read(cnonce[:]); err != nil {
+		return utils.NewError(a.Authorize, "unable to generate client nonce", a, err)
+	}
+
+	a.count++
+	nc := fmt.Sprintf("%08x", a.count)
+	cn := hex.EncodeToString(cnonce[:])
+	realm, nonce, uri := a.challenge["realm"], a.challenge["nonce"], req.URL.RequestURI()
+
+	ha1 := digest(h, a.Username, realm, a.Password)
+	if strings.HasSuffix(strings.ToLower(algorithm), "-sess") {
+		ha1 = digest(h, ha1, nonce, cn)
+	}
+	ha2 := digest(h, req.Method, uri)
+
+	fields := []string{
+		fmt.Sprintf(`username="%s"`, a.Username),
+		fmt.Sprintf(`realm="%s"`, realm),
+		fmt.Sprintf(`nonce="%s"`, nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+	}
+
+	if qop := a.qop(); qop != "" {
+		response := digest(h, ha1, nonce, nc, cn, qop, ha2)
+		fields = append(fields, fmt.Sprintf(`response="%s"`, response), "qop="+qop, "nc="+nc, fmt.Sprintf(`cnonce="%s"`, cn))
+	} else {
+		fields = append(fields, fmt.Sprintf(`response="%s"`, digest(h, ha1, nonce, ha2)))
+	}
+
+	if algorithm != "" {
+		fields = append(fields, "algorithm="+algorithm)
+	}
+
+	if opaque, ok := a.challenge["opaque"]; ok {
+		fields = append(fields, fmt.Sprintf(`opaque="%s"`, opaque))
+	}
+
+	req.Header.Set("Authorization", "Digest "+strings.Join(fields, ", "))
+	return nil
+
+}
+
+func (a *DigestAuth) Challenge(req *http.Request, resp *http.Response) (bool, error) {
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for _, header := range resp.Header[http.CanonicalHeaderKey("WWW-Authenticate")] {
+		if scheme := strings.SplitN(strings.TrimSpace(header), " ", 2); !strings.EqualFold(scheme[0], "Digest") || len(scheme) < 2 {
+			continue
+		} else if challenge := parseChallenge(scheme[1]); challenge["nonce"] == "" {
+			return false, utils.NewError(a.Challenge, "digest challenge without nonce", a, nil)
+		} else {
+			// credentials sent for the same nonce were rejected, unless the server only found the nonce stale
+			sent := strings.HasPrefix(req.Header.Get("Authorization"), "Digest ")
+			retry := !sent || strings.EqualFold(challenge["stale"], "true") || a.challenge == nil || a.challenge["nonce"] != challenge["nonce"]
+			a.challenge, a.count = challenge, 0
+			return retry, nil
+		}
+	}
+
+	return false, nil
+
+}
+
+// picks the quality of protection from the challenge, preferring authentication only
+func (a *DigestAuth) qop() string {
+	if options, ok := a.challenge["qop"]; !ok {
+		return ""
+	} else {
+		for _, option := range strings.Split(options, ",") {
+			if strings.TrimSpace(option) == "auth" {
+				return "auth"
+			}
+		}
+		return ""
+	}
+}
+
+// hashes colon separated values and returns the result in hex
+func digest(h func() hash.Hash, values ...string) string {
+	sum := h()
+	sum.Write([]byte(strings.Join(values, ":")))
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// parses the comma separated parameters of an authentication challenge, such as realm="x", nonce="y"
+func parseChallenge(params string) map[string]string {
+
+	result := make(map[string]string)
+
+	for len(params) > 0 {
+
+		params = strings.TrimLeft(params, ", ")
+		eq := strings.Index(params, "=")
+		if eq < 0 {
+			break
+		}
+
+		key := strings.ToLower(strings.TrimSpace(params[:eq]))
+		params = strings.TrimLeft(params[eq+1:], " ")
+
+		var value string
+		if strings.HasPrefix(params, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(params) && params[i] != '"'; i++ {
+				if params[i] == '\\' && i+1 < len(params) {
+					i++
+				}
+				b.WriteByte(params[i])
+			}
+			if i < len(params) {
+				i++ // skip the closing quote
+			}
+			value, params = b.String(), params[i:]
+		} else if end := strings.Index(params, ","); end >= 0 {
+			value, params = strings.TrimSpace(params[:end]), params[end:]
+		} else {
+			value, params = strings.TrimSpace(params), ""
+		}
+
+		result[key] = value
+
+	}
+
+	return result
+
+}
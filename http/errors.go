@@ -0,0 +1,81 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// sentinel errors matched by errors.Is against the status of a failed response
+var (
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// the number of bytes of a response body kept in an HTTPError
+const errorBodySnippetLength = 512
+
+// an error describing a response with an unexpected status
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	// the beginning of the response body, to help diagnose the failure
+	Body string
+}
+
+// creates an error describing a response, keeping the beginning of its body
+func NewHTTPError(resp *http.Response, body []byte) *HTTPError {
+	e := &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	if req := resp.Request; req != nil {
+		e.Method = req.Method
+		e.URL = req.URL.String()
+	}
+	if len(body) > errorBodySnippetLength {
+		body = body[:errorBodySnippetLength]
+	}
+	e.Body = strings.TrimSpace(string(body))
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("%s %s: unexpected server response %s", e.Method, e.URL, e.Status)
+	if e.Body != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Body)
+	}
+	return msg
+}
+
+// matches the sentinel error corresponding to the status code
+func (e *HTTPError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusConflict:
+		return target == ErrConflict
+	case http.StatusPreconditionFailed:
+		return target == ErrPreconditionFailed
+	default:
+		return false
+	}
+}
+
+// reads the beginning of the body of an unexpected response into an error
+func (r *Response) HTTPError() *HTTPError {
+	var body []byte
+	if r.Body != nil {
+		body, _ = ioutil.ReadAll(io.LimitReader(r.Body, errorBodySnippetLength))
+	}
+	return NewHTTPError(r.Native(), body)
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// executes HTTP requests, such as the native HTTP client
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// adapts a function to the Doer interface
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// wraps the execution of requests with additional behavior, such as logging or metrics
+type Middleware func(next Doer) Doer
+
+// describes the outcome of a request, as passed to hooks
+type RequestInfo struct {
+	Method string
+	URL    *url.URL
+	// the status code of the response, zero if the request failed
+	StatusCode int
+	// the time at which the request was sent
+	Start time.Time
+	// the time it took to receive the response headers
+	Duration time.Duration
+	// the error returned instead of a response, if any
+	Err error
+}
+
+// functions called around every request of a client
+type Hooks struct {
+	// called before a request is sent, and may modify it
+	BeforeRequest func(req *http.Request)
+	// called once a response is received or the request failed
+	AfterResponse func(info *RequestInfo)
+}
+
+// returns a middleware calling the hooks around every request
+func (h *Hooks) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if h.BeforeRequest != nil {
+				h.BeforeRequest(req)
+			}
+			info := &RequestInfo{Method: req.Method, URL: req.URL, Start: time.Now()}
+			resp, err := next.Do(req)
+			info.Duration, info.Err = time.Since(info.Start), err
+			if resp != nil {
+				info.StatusCode = resp.StatusCode
+			}
+			if h.AfterResponse != nil {
+				h.AfterResponse(info)
+			}
+			return resp, err
+		})
+	}
+}
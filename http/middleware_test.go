@@ -0,0 +1,123 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	. "gopkg.in/check.v1"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type MiddlewareSuite struct{}
+
+var _ = Suite(new(MiddlewareSuite))
+
+func TestMiddleware(t *testing.T) { TestingT(t) }
+
+func (s *MiddlewareSuite) TestChainOrder(c *C) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Seen", r.Header.Get("X-Injected"))
+		w.WriteHeader(http.StatusMultiStatus)
+	}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				resp, err := next.Do(req)
+				calls = append(calls, name+" after")
+				return resp, err
+			})
+		}
+	}
+	inject := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("X-Injected", "yes")
+			return next.Do(req)
+		})
+	}
+
+	var infos []*RequestInfo
+	hooks := &Hooks{AfterResponse: func(info *RequestInfo) {
+		infos = append(infos, info)
+	}}
+
+	client.Use(trace("outer"), trace("inner"))
+	client.Use(inject, hooks.Middleware())
+
+	req, err := server.NewRequest("PROPFIND", "/calendars/jon/")
+	c.Assert(err, IsNil)
+	resp, err := client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(resp.Header.Get("X-Seen"), Equals, "yes")
+	c.Assert(calls, DeepEquals, []string{"outer before", "inner before", "inner after", "outer after"})
+
+	c.Assert(infos, HasLen, 1)
+	c.Assert(infos[0].Method, Equals, "PROPFIND")
+	c.Assert(infos[0].URL.Path, Equals, "/calendars/jon/")
+	c.Assert(infos[0].StatusCode, Equals, http.StatusMultiStatus)
+	c.Assert(infos[0].Duration > 0, Equals, true)
+	c.Assert(infos[0].Err, IsNil)
+
+}
+
+func (s *MiddlewareSuite) TestFaultInjection(c *C) {
+
+	server, err := NewServer("http://localhost:1")
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	failure := errors.New("injected")
+	var info *RequestInfo
+	hooks := &Hooks{
+		BeforeRequest: func(req *http.Request) { req.Header.Set("X-Before", "1") },
+		AfterResponse: func(i *RequestInfo) { info = i },
+	}
+	client.Use(hooks.Middleware(), func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			c.Assert(req.Header.Get("X-Before"), Equals, "1")
+			return nil, failure
+		})
+	})
+
+	req, err := server.NewRequest("GET", "/")
+	c.Assert(err, IsNil)
+	_, err = client.Do(req)
+	c.Assert(err, ErrorMatches, "(?s).*injected.*")
+	c.Assert(info.Err, Equals, failure)
+	c.Assert(info.StatusCode, Equals, 0)
+
+}
+
+func (s *MiddlewareSuite) TestConcurrentHeaders(c *C) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client.SetHeader(fmt.Sprintf("X-Header-%d", i), "value")
+			if req, err := server.NewRequest("GET", "/"); err == nil {
+				client.Do(req)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+}
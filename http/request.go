@@ -1,8 +1,11 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"github.com/dolanor/caldav-go/utils"
 	"io"
+	"io/ioutil"
 	"net/http"
 )
 
@@ -16,18 +19,29 @@ func (r *Request) Native() *http.Request {
 
 // creates a new HTTP request object
 func NewRequest(method string, urlstr string, body ...io.ReadCloser) (*Request, error) {
+	return NewRequestWithContext(context.Background(), method, urlstr, body...)
+}
+
+// creates a new HTTP request object, bound to a context that may cancel it
+// the body is buffered, so that the request can be replayed to answer authentication challenges
+func NewRequestWithContext(ctx context.Context, method string, urlstr string, body ...io.ReadCloser) (*Request, error) {
 
 	var err error
 	var r = new(http.Request)
 
 	if len(body) > 0 && body[0] != nil {
-		r, err = http.NewRequest(method, urlstr, body[0])
+		var data []byte
+		if data, err = ioutil.ReadAll(body[0]); err != nil {
+			return nil, utils.NewError(NewRequestWithContext, "unable to read request body", urlstr, err)
+		}
+		body[0].Close()
+		r, err = http.NewRequestWithContext(ctx, method, urlstr, bytes.NewReader(data))
 	} else {
-		r, err = http.NewRequest(method, urlstr, nil)
+		r, err = http.NewRequestWithContext(ctx, method, urlstr, nil)
 	}
 
 	if err != nil {
-		return nil, utils.NewError(NewRequest, "unable to create request", urlstr, err)
+		return nil, utils.NewError(NewRequestWithContext, "unable to create request", urlstr, err)
 	} else if auth := r.URL.User; auth != nil {
 		pass, _ := auth.Password()
 		r.SetBasicAuth(auth.Username(), pass)
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"context"
+	"github.com/dolanor/caldav-go/utils"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// decides whether and when failed requests are attempted again
+// transport errors and 429, 502, 503 and 504 responses are considered transient
+type RetryPolicy struct {
+	// the maximum number of attempts, including the first one
+	MaxAttempts int
+	// the delay before the first retry, doubled for every further attempt
+	BaseDelay time.Duration
+	// the upper bound of the delay between attempts. if the server asks
+	// for a longer delay with Retry-After, its response is returned as is
+	MaxDelay time.Duration
+	// the fraction of every delay that is randomized, between 0 and 1
+	Jitter float64
+	// checks whether a request may be sent more than once, defaults to IsIdempotent
+	Retryable func(req *http.Request) bool
+}
+
+// creates a retry policy of three attempts, waiting half a second before the first retry
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.5,
+	}
+}
+
+// checks if sending a request more than once has the same effect as sending it once.
+// writes only qualify when they are conditional on an entity tag, as a retried write would
+// otherwise overwrite changes made in the meantime
+func IsIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PROPFIND", "REPORT":
+		return true
+	case "PUT", "DELETE":
+		return req.Header.Get("If-Match") != "" || req.Header.Get("If-None-Match") != ""
+	default:
+		return false
+	}
+}
+
+// returns a middleware retrying the requests allowed by the policy
+func (p *RetryPolicy) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return p.do(next, req)
+		})
+	}
+}
+
+func (p *RetryPolicy) do(next Doer, req *http.Request) (*http.Response, error) {
+
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsIdempotent
+	}
+
+	if p.MaxAttempts <= 1 || !retryable(req) || (req.Body != nil && req.GetBody == nil) {
+		return next.Do(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+
+		current := req
+		if attempt > 1 {
+			current = req.Clone(req.Context())
+			if req.GetBody != nil {
+				if body, err := req.GetBody(); err != nil {
+					return nil, utils.NewError(p.do, "unable to replay request body", p, err)
+				} else {
+					current.Body = body
+				}
+			}
+		}
+
+		resp, err := next.Do(current)
+		if attempt >= p.MaxAttempts || !transient(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		delay := p.backoff(attempt)
+		if resp != nil {
+			if wait, ok := retryAfter(resp); ok && wait > delay {
+				if p.MaxDelay > 0 && wait > p.MaxDelay {
+					return resp, nil // the server will not be back in time
+				}
+				delay = wait
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, utils.NewError(p.do, "request cancelled while waiting to retry", p, err)
+		}
+
+	}
+
+}
+
+// computes the randomized delay to wait after a failed attempt
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(float64(delay) * p.Jitter * rand.Float64())
+	}
+	return delay
+}
+
+// checks if the outcome of a request is worth another attempt
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parses the Retry-After header of a response, given either in seconds or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if value := resp.Header.Get("Retry-After"); value == "" {
+		return 0, false
+	} else if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	} else if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	} else {
+		return 0, false
+	}
+}
+
+// waits for a delay, unless the context is done first
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	. "gopkg.in/check.v1"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type RetrySuite struct{}
+
+var _ = Suite(new(RetrySuite))
+
+func TestRetry(t *testing.T) { TestingT(t) }
+
+func (s *RetrySuite) client(c *C, policy *RetryPolicy, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	ts := httptest.NewServer(handler)
+	server, err := NewServer(ts.URL)
+	c.Assert(err, IsNil)
+	client := NewDefaultClient(server)
+	client.Use(policy.Middleware())
+	return client, ts
+}
+
+func (s *RetrySuite) policy() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second, Jitter: 0.5}
+}
+
+func (s *RetrySuite) TestRetryTransientFailures(c *C) {
+
+	var bodies []string
+	client, ts := s.client(c, s.policy(), func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else if len(bodies) == 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		} else {
+			w.WriteHeader(http.StatusMultiStatus)
+		}
+	})
+	defer ts.Close()
+
+	req, err := client.Server().NewRequest("REPORT", "/calendars/jon/", ioutil.NopCloser(strings.NewReader("<query/>")))
+	c.Assert(err, IsNil)
+	resp, err := client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusMultiStatus)
+	c.Assert(bodies, DeepEquals, []string{"<query/>", "<query/>", "<query/>"})
+
+}
+
+func (s *RetrySuite) TestGiveUpAfterMaxAttempts(c *C) {
+
+	var attempts int
+	client, ts := s.client(c, s.policy(), func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer ts.Close()
+
+	req, err := client.Server().NewRequest("GET", "/")
+	c.Assert(err, IsNil)
+	resp, err := client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusBadGateway)
+	c.Assert(attempts, Equals, 3)
+
+}
+
+func (s *RetrySuite) TestOnlyIdempotentRequests(c *C) {
+
+	var attempts int
+	client, ts := s.client(c, s.policy(), func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer ts.Close()
+
+	req, err := client.Server().NewRequest("PUT", "/event.ics", ioutil.NopCloser(strings.NewReader("BEGIN:VCALENDAR")))
+	c.Assert(err, IsNil)
+	_, err = client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(attempts, Equals, 1)
+
+	req, err = client.Server().NewRequest("PUT", "/event.ics", ioutil.NopCloser(strings.NewReader("BEGIN:VCALENDAR")))
+	c.Assert(err, IsNil)
+	req.Header.Set("If-Match", `"1"`)
+	_, err = client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(attempts, Equals, 4)
+
+	req, err = client.Server().NewRequest("MKCALENDAR", "/calendars/jon/new/")
+	c.Assert(err, IsNil)
+	_, err = client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(attempts, Equals, 5)
+
+}
+
+func (s *RetrySuite) TestRetryAfterBeyondMaxDelay(c *C) {
+
+	var attempts int
+	client, ts := s.client(c, s.policy(), func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer ts.Close()
+
+	req, err := client.Server().NewRequest("GET", "/")
+	c.Assert(err, IsNil)
+	resp, err := client.Do(req)
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusServiceUnavailable)
+	c.Assert(attempts, Equals, 1)
+
+}
+
+func (s *RetrySuite) TestCancelWhileWaiting(c *C) {
+
+	policy := s.policy()
+	policy.BaseDelay, policy.Jitter = time.Hour, 0
+	client, ts := s.client(c, policy, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err := client.Server().NewRequestWithContext(ctx, "GET", "/")
+	c.Assert(err, IsNil)
+	_, err = client.Do(req)
+	c.Assert(err, ErrorMatches, "(?s).*context deadline exceeded.*")
+
+}
+
+func (s *RetrySuite) TestBackoff(c *C) {
+	policy := &RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	c.Assert(policy.backoff(1), Equals, time.Second)
+	c.Assert(policy.backoff(2), Equals, 2*time.Second)
+	c.Assert(policy.backoff(3), Equals, 4*time.Second)
+	c.Assert(policy.backoff(4), Equals, 5*time.Second)
+	c.Assert(policy.backoff(80), Equals, 5*time.Second)
+	policy.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		delay := policy.backoff(2)
+		c.Assert(delay >= time.Second && delay <= 2*time.Second, Equals, true)
+	}
+}
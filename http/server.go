@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"github.com/dolanor/caldav-go/utils"
 	"io"
 	"log"
@@ -46,3 +47,8 @@ func (s *Server) AbsUrlStr(path string) string {
 func (s *Server) NewRequest(method string, path string, body ...io.ReadCloser) (*Request, error) {
 	return NewRequest(method, s.AbsUrlStr(path), body...)
 }
+
+// creates a new HTTP request object, bound to a context that may cancel it
+func (s *Server) NewRequestWithContext(ctx context.Context, method string, path string, body ...io.ReadCloser) (*Request, error) {
+	return NewRequestWithContext(ctx, method, s.AbsUrlStr(path), body...)
+}
@@ -30,6 +30,9 @@ type Calendar struct {
 
 	// unique events to be stored together in the icalendar file
 	Events []*Event `ical:",omitempty"`
+
+	// free/busy time information, as requested from or returned by a server
+	FreeBusy []*FreeBusy `ical:",omitempty"`
 }
 
 func (c *Calendar) UseTimeZone(location *time.Location) *TimeZone {
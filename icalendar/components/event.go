@@ -74,11 +74,11 @@ type Event struct {
 	// defines a rule or repeating pattern for recurring events, to-dos, or time zone definitions.
 	RecurrenceRules []*values.RecurrenceRule `ical:",omitempty"`
 
-	// property provides the capability to associate a document object with a calendar component.
-	Attachment *values.Url `ical:"attach,omitempty"`
+	// property provides the capability to associate document objects with a calendar component.
+	Attachments []*values.Attachment `ical:"attach,omitempty"`
 
 	// defines an "Attendee" within a calendar component.
-	Attendees []*values.AttendeeContact `ical:",omitempty"`
+	Attendees []*values.AttendeeContact `ical:"attendee,omitempty"`
 
 	// defines the categories for a calendar component.
 	Categories *values.CSV `ical:",omitempty"`
@@ -52,7 +52,7 @@ func (s *EventSuite) TestFullEventMarshal(c *C) {
 	oneWeek := oneDay * 7
 	event := NewEventWithEnd("1:2:3", now, end)
 	uri, _ := url.Parse("http://dolanor.com/some/attachment.ics")
-	event.Attachment = values.NewUrl(*uri)
+	event.Attachments = []*values.Attachment{values.NewUriAttachment(*uri)}
 	event.Attendees = []*values.AttendeeContact{
 		values.NewAttendeeContact("Jon Azoff", "jon@dolanor.com"),
 		values.NewAttendeeContact("Matthew Davie", "matthew@dolanor.com"),
@@ -89,11 +89,13 @@ func (s *EventSuite) TestFullEventMarshal(c *C) {
 		c.Fatal(err.Error())
 	}
 	tmpl := "BEGIN:VEVENT\r\nUID:1:2:3\r\nDTSTAMP:%sZ\r\nDTSTART:%sZ\r\nDTEND:%sZ\r\nCREATED:%sZ\r\n" +
-		"DESCRIPTION:An all-levels class combining strength and flexibility with breath\r\n" +
+		"DESCRIPTION:An all-levels class combining strength and flexibility with bre\r\n" +
+		" ath\r\n" +
 		"GEO:37.747643 -122.445400\r\nLAST-MODIFIED:%sZ\r\nLOCATION:Dolores Park\r\n" +
 		"ORGANIZER;CN=\"Jon Azoff\":MAILTO:jon@dolanor.com\r\nPRIORITY:1\r\nSEQUENCE:1\r\nSTATUS:TENTATIVE\r\n" +
 		"SUMMARY:Jon's Super-Sweaty Vinyasa 1\r\nTRANSP:OPAQUE\r\n" +
-		"URL;VALUE=URI:http://student.dolanor.com/san-francisco/jonathan-azoff/vinyasa-1\r\n" +
+		"URL;VALUE=URI:http://student.dolanor.com/san-francisco/jonathan-azoff/vinya\r\n" +
+		" sa-1\r\n" +
 		"RECURRENCE-ID:%sZ\r\nRRULE:FREQ=WEEKLY\r\nATTACH;VALUE=URI:http://dolanor.com/some/attachment.ics\r\n" +
 		"ATTENDEE;CN=\"Jon Azoff\":MAILTO:jon@dolanor.com\r\nATTENDEE;CN=\"Matthew Davie\":MAILTO:matthew@dolanor.com\r\n" +
 		"CATEGORIES:vinyasa,level 1\r\nCOMMENT:Great class, 5 stars!\r\nCOMMENT:I love this class!\r\n" +
@@ -145,3 +147,16 @@ END:VEVENT`
 	c.Assert(e.Attendees[0].Entry.Address, Equals, "fakemcfakebiz.com_b3a0grbjdr4dcje2fc4ikmaeq8@group.calendar.google.com")
 	c.Assert(e.Attendees[0].Entry.Name, Equals, "Fakebiz Shared")
 }
+
+func (s *EventSuite) TestUnmarshalRecurrence(c *C) {
+	raw := "BEGIN:VEVENT\r\nUID:standup\r\nDTSTAMP:20260101T090000Z\r\nDTSTART:20260105T090000Z\r\n" +
+		"CATEGORIES:Work,Meetings\r\nRRULE:FREQ=WEEKLY;COUNT=4\r\nEXDATE:20260119T090000Z\r\nRDATE:20260131T090000Z\r\nEND:VEVENT"
+	event := new(Event)
+	c.Assert(icalendar.Unmarshal(raw, event), IsNil)
+	c.Assert(event.RecurrenceRules, HasLen, 1)
+	c.Assert(event.RecurrenceRules[0].Count, Equals, 4)
+	c.Assert(event.ExceptionDateTimes, NotNil)
+	c.Assert(*event.ExceptionDateTimes, HasLen, 1)
+	c.Assert(event.RecurrenceDateTimes, NotNil)
+	c.Assert(event.Categories, DeepEquals, values.NewCSV("Work", "Meetings"))
+}
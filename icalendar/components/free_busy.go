@@ -0,0 +1,152 @@
+package components
+
+import (
+	"crypto/rand"
+	"fmt"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	"github.com/dolanor/caldav-go/utils"
+	"time"
+)
+
+// a request for, a reply to a request for, or a published set of free/busy time information
+type FreeBusy struct {
+
+	// defines the persistent, globally unique identifier for the calendar component.
+	UID string `ical:",omitempty"`
+
+	// indicates the date/time that the instance of the iCalendar object was created.
+	DateStamp *values.DateTime `ical:"dtstamp,required"`
+
+	// specifies the start of the range of time the free/busy information is provided for.
+	DateStart *values.DateTime `ical:"dtstart,omitempty"`
+
+	// specifies the end of the range of time the free/busy information is provided for.
+	DateEnd *values.DateTime `ical:"dtend,omitempty"`
+
+	// defines the calendar user requesting the free/busy time, or whose free/busy time is published.
+	Organizer *values.OrganizerContact `ical:",omitempty"`
+
+	// defines the calendar users whose free/busy time is requested or provided.
+	Attendees []*values.AttendeeContact `ical:"attendee,omitempty"`
+
+	// defines a Uniform Resource Locator (URL) where the free/busy time information can be found.
+	Url *values.Url `ical:",omitempty"`
+
+	// defines the free or busy time intervals.
+	FreeBusyTimes []*values.FreeBusyTime `ical:"freebusy,omitempty"`
+}
+
+// validates the free/busy internals
+func (f *FreeBusy) ValidateICalValue() error {
+	if f.DateStamp == nil {
+		return utils.NewError(f.ValidateICalValue, "free/busy time stamp must be set", f, nil)
+	}
+	return nil
+}
+
+// adds one or more periods of a free/busy type
+func (f *FreeBusy) AddPeriods(fbtype values.FreeBusyType, periods ...*values.Period) {
+	f.FreeBusyTimes = append(f.FreeBusyTimes, values.NewFreeBusyTime(fbtype, periods...))
+}
+
+// returns the busy periods, whatever their free/busy type, merged into sorted, non-overlapping intervals
+func (f *FreeBusy) BusyPeriods() []*values.Period {
+	var periods []*values.Period
+	for _, fb := range f.FreeBusyTimes {
+		if fb != nil && fb.IsBusy() {
+			periods = append(periods, fb.Periods...)
+		}
+	}
+	return values.MergePeriods(periods...)
+}
+
+// creates a new iCalendar free/busy component covering a range of time, generating a UID if none is given
+func NewFreeBusy(uid string, start, end time.Time) *FreeBusy {
+	f := new(FreeBusy)
+	f.UID = uid
+	if f.UID == "" {
+		f.UID = newUID()
+	}
+	f.DateStamp = values.NewDateTime(time.Now().UTC())
+	f.DateStart = values.NewDateTime(start)
+	f.DateEnd = values.NewDateTime(end)
+	return f
+}
+
+// computes the free/busy time of a set of events over a range of time. recurring events are expanded, transparent
+// and cancelled events are ignored, while tentative events are reported as BUSY-TENTATIVE. the periods are clipped
+// to the range, merged and expressed in UTC
+func NewFreeBusyFromEvents(events []*Event, start, end time.Time) *FreeBusy {
+
+	busy := make(map[values.FreeBusyType][]*values.Period)
+	for _, i := range ExpandEvents(events, start, end) {
+		if i.Event.TimeTransparency == values.TransparentTimeTransparency {
+			continue
+		} else if i.Event.Status == values.CancelledEventStatus || !i.End.After(i.Start) {
+			continue
+		}
+		var fbtype values.FreeBusyType = values.BusyFreeBusyType
+		if i.Event.Status == values.TentativeEventStatus {
+			fbtype = values.BusyTentativeFreeBusyType
+		}
+		pstart, pend := i.Start, i.End
+		if pstart.Before(start) {
+			pstart = start
+		}
+		if pend.After(end) {
+			pend = end
+		}
+		busy[fbtype] = append(busy[fbtype], values.NewPeriod(pstart.UTC(), pend.UTC()))
+	}
+
+	f := NewFreeBusy("", start.UTC(), end.UTC())
+	for _, fbtype := range []values.FreeBusyType{values.BusyFreeBusyType, values.BusyTentativeFreeBusyType} {
+		if periods := values.MergePeriods(busy[fbtype]...); len(periods) > 0 {
+			f.AddPeriods(fbtype, periods...)
+		}
+	}
+	return f
+
+}
+
+// finds the periods of at least the requested length, within a range of time, during which none of the
+// free/busy components are busy. tentative and unavailable time counts as busy
+func CommonFreeSlots(start, end time.Time, length time.Duration, busy ...*FreeBusy) []*values.Period {
+
+	var periods []*values.Period
+	for _, f := range busy {
+		if f != nil {
+			periods = append(periods, f.BusyPeriods()...)
+		}
+	}
+
+	var slots []*values.Period
+	free := start
+	add := func(until time.Time) {
+		if until.Sub(free) >= length && until.After(free) {
+			slots = append(slots, values.NewPeriod(free, until))
+		}
+	}
+	for _, p := range values.MergePeriods(periods...) {
+		if !p.End().After(free) {
+			continue
+		} else if !p.Start().Before(end) {
+			break
+		}
+		add(p.Start())
+		free = p.End()
+	}
+	add(end)
+
+	return slots
+
+}
+
+// generates a globally unique identifier for a component
+func newUID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return fmt.Sprintf("%d@caldav-go", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%x@caldav-go", id)
+}
@@ -0,0 +1,162 @@
+package components
+
+import (
+	"github.com/dolanor/caldav-go/icalendar"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	. "gopkg.in/check.v1"
+	"testing"
+	"time"
+)
+
+type FreeBusySuite struct{}
+
+var _ = Suite(new(FreeBusySuite))
+
+func TestFreeBusy(t *testing.T) { TestingT(t) }
+
+func (s *FreeBusySuite) TestMarshal(c *C) {
+	start := time.Date(2006, 1, 4, 14, 0, 0, 0, time.UTC)
+	fb := NewFreeBusy("fb-1", start, start.Add(8*time.Hour))
+	fb.DateStamp = values.NewDateTime(start)
+	fb.AddPeriods(values.BusyTentativeFreeBusyType, values.NewPeriod(start, start.Add(time.Hour)))
+	fb.AddPeriods(values.BusyFreeBusyType, values.NewPeriodWithDuration(start.Add(2*time.Hour), 90*time.Minute))
+	enc, err := icalendar.Marshal(fb)
+	c.Assert(err, IsNil)
+	c.Assert(enc, Equals, "BEGIN:VFREEBUSY\r\nUID:fb-1\r\nDTSTAMP:20060104T140000Z\r\nDTSTART:20060104T140000Z\r\n"+
+		"DTEND:20060104T220000Z\r\nFREEBUSY;FBTYPE=BUSY-TENTATIVE:20060104T140000Z/20060104T150000Z\r\n"+
+		"FREEBUSY:20060104T160000Z/PT1H30M\r\nEND:VFREEBUSY")
+}
+
+func (s *FreeBusySuite) TestUnmarshal(c *C) {
+
+	raw := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Example Corp.//CalDAV Server//EN\r\nBEGIN:VFREEBUSY\r\n" +
+		"DTSTAMP:20050125T090000Z\r\nDTSTART:20060104T140000Z\r\nDTEND:20060105T220000Z\r\n" +
+		"FREEBUSY;FBTYPE=BUSY-TENTATIVE:20060104T150000Z/PT1H\r\n" +
+		"FREEBUSY:20060104T190000Z/PT1H,20060104T195000Z/20060104T210000Z\r\n" +
+		"FREEBUSY;FBTYPE=FREE:20060104T210000Z/PT2H\r\n" +
+		"FREEBUSY;FBTYPE=BUSY-UNAVAILABLE:20060104T230000Z/PT1H\r\nEND:VFREEBUSY\r\nEND:VCALENDAR"
+
+	cal := new(Calendar)
+	c.Assert(icalendar.Unmarshal(raw, cal), IsNil)
+	c.Assert(cal.FreeBusy, HasLen, 1)
+
+	fb := cal.FreeBusy[0]
+	c.Assert(fb.FreeBusyTimes, HasLen, 4)
+	c.Assert(fb.FreeBusyTimes[0].Type, Equals, values.FreeBusyType(values.BusyTentativeFreeBusyType))
+	c.Assert(fb.FreeBusyTimes[1].Type, Equals, values.FreeBusyType(values.BusyFreeBusyType))
+	c.Assert(fb.FreeBusyTimes[1].Periods, HasLen, 2)
+	c.Assert(fb.FreeBusyTimes[2].IsBusy(), Equals, false)
+
+	day := func(hour, min int) time.Time {
+		return time.Date(2006, 1, 4, hour, min, 0, 0, time.UTC)
+	}
+	busy := fb.BusyPeriods()
+	c.Assert(busy, HasLen, 3)
+	c.Assert(busy[0].Start(), Equals, day(15, 0))
+	c.Assert(busy[0].End(), Equals, day(16, 0))
+	c.Assert(busy[1].Start(), Equals, day(19, 0))
+	c.Assert(busy[1].End(), Equals, day(21, 0))
+	c.Assert(busy[2].Start(), Equals, day(23, 0))
+	c.Assert(busy[2].Duration(), Equals, time.Hour)
+
+}
+
+func (s *FreeBusySuite) TestFromEvents(c *C) {
+
+	raw := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Example Corp.//CalDAV Client//EN\r\n" +
+		// a weekly meeting in New York, except on the 10th, and moved an hour later on the 17th
+		"BEGIN:VEVENT\r\nUID:weekly\r\nDTSTAMP:20060101T000000Z\r\nDTSTART;TZID=America/New_York:20060103T090000\r\n" +
+		"DTEND;TZID=America/New_York:20060103T100000\r\nRRULE:FREQ=WEEKLY;COUNT=4\r\nEXDATE:20060110T140000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:weekly\r\nDTSTAMP:20060101T000000Z\r\nRECURRENCE-ID:20060117T140000Z\r\n" +
+		"DTSTART:20060117T150000Z\r\nDURATION:PT1H\r\nSTATUS:TENTATIVE\r\nEND:VEVENT\r\n" +
+		// a floating lunch, interpreted in the timezone of the range
+		"BEGIN:VEVENT\r\nUID:lunch\r\nDTSTAMP:20060101T000000Z\r\nDTSTART:20060103T123000\r\nDURATION:PT1H\r\nEND:VEVENT\r\n" +
+		// an all-day event, a transparent event and a cancelled event
+		"BEGIN:VEVENT\r\nUID:holiday\r\nDTSTAMP:20060101T000000Z\r\nDTSTART;VALUE=DATE:20060120\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:reminder\r\nDTSTAMP:20060101T000000Z\r\nDTSTART:20060103T160000Z\r\nDURATION:PT1H\r\n" +
+		"TRANSP:TRANSPARENT\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:cancelled\r\nDTSTAMP:20060101T000000Z\r\nDTSTART:20060104T160000Z\r\nDURATION:PT1H\r\n" +
+		"STATUS:CANCELLED\r\nEND:VEVENT\r\nEND:VCALENDAR"
+
+	cal := new(Calendar)
+	c.Assert(icalendar.Unmarshal(raw, cal), IsNil)
+	c.Assert(cal.Events, HasLen, 6)
+
+	loc, err := time.LoadLocation("America/New_York")
+	c.Assert(err, IsNil)
+	start := time.Date(2006, 1, 1, 0, 0, 0, 0, loc)
+	end := time.Date(2006, 1, 20, 12, 0, 0, 0, loc)
+
+	instances := ExpandEvents(cal.Events, start, end)
+	c.Assert(instances, HasLen, 6)
+	c.Assert(instances[0].Event.UID, Equals, "weekly")
+	c.Assert(instances[0].Start.Equal(time.Date(2006, 1, 3, 14, 0, 0, 0, time.UTC)), Equals, true)
+	c.Assert(instances[2].Event.UID, Equals, "lunch")
+	c.Assert(instances[2].Start.Equal(time.Date(2006, 1, 3, 17, 30, 0, 0, time.UTC)), Equals, true)
+	c.Assert(instances[4].Event, Equals, cal.Events[1])
+	c.Assert(instances[4].RecurrenceId.Equal(time.Date(2006, 1, 17, 14, 0, 0, 0, time.UTC)), Equals, true)
+	c.Assert(instances[5].End.Sub(instances[5].Start), Equals, 24*time.Hour)
+
+	fb := NewFreeBusyFromEvents(cal.Events, start, end)
+	c.Assert(fb.FreeBusyTimes, HasLen, 2)
+	c.Assert(fb.FreeBusyTimes[0].Type, Equals, values.FreeBusyType(values.BusyFreeBusyType))
+	busy := fb.FreeBusyTimes[0].Periods
+	c.Assert(busy, HasLen, 3)
+	c.Assert(busy[0].Start(), Equals, time.Date(2006, 1, 3, 14, 0, 0, 0, time.UTC))
+	c.Assert(busy[1].Start(), Equals, time.Date(2006, 1, 3, 17, 30, 0, 0, time.UTC))
+	c.Assert(busy[2].Start(), Equals, time.Date(2006, 1, 20, 5, 0, 0, 0, time.UTC))
+	c.Assert(busy[2].End(), Equals, end.UTC())
+	c.Assert(fb.FreeBusyTimes[1].Type, Equals, values.FreeBusyType(values.BusyTentativeFreeBusyType))
+	c.Assert(fb.FreeBusyTimes[1].Periods, HasLen, 1)
+	c.Assert(fb.FreeBusyTimes[1].Periods[0].Start(), Equals, time.Date(2006, 1, 17, 15, 0, 0, 0, time.UTC))
+
+	enc, err := icalendar.Marshal(fb)
+	c.Assert(err, IsNil)
+	c.Assert(enc, Matches, "(?s).*\r\nFREEBUSY:20060103T140000Z/20060103T150000Z,20060103T173000Z/.*")
+
+}
+
+func (s *FreeBusySuite) TestFromEventsWithoutUID(c *C) {
+
+	start := time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)
+	first := NewEventWithEnd("", start.Add(9*time.Hour), start.Add(10*time.Hour))
+	second := NewEventWithEnd("", start.Add(11*time.Hour), start.Add(12*time.Hour))
+	// unrelated events wrongly sharing a UID are kept apart
+	third := NewEventWithEnd("shared", start.Add(13*time.Hour), start.Add(14*time.Hour))
+	fourth := NewEventWithEnd("shared", start.Add(15*time.Hour), start.Add(16*time.Hour))
+
+	events := []*Event{first, second, third, fourth}
+	c.Assert(ExpandEvents(events, start, start.AddDate(0, 0, 1)), HasLen, 4)
+
+	fb := NewFreeBusyFromEvents(events, start, start.AddDate(0, 0, 1))
+	c.Assert(fb.UID, Not(Equals), "")
+	c.Assert(fb.FreeBusyTimes, HasLen, 1)
+	c.Assert(fb.FreeBusyTimes[0].Periods, HasLen, 4)
+
+}
+
+func (s *FreeBusySuite) TestCommonFreeSlots(c *C) {
+
+	at := func(hour, min int) time.Time {
+		return time.Date(2006, 1, 4, hour, min, 0, 0, time.UTC)
+	}
+	alice := NewFreeBusy("alice", at(9, 0), at(17, 0))
+	alice.AddPeriods(values.BusyFreeBusyType, values.NewPeriod(at(8, 0), at(10, 0)), values.NewPeriod(at(13, 0), at(14, 0)))
+	bob := NewFreeBusy("bob", at(9, 0), at(17, 0))
+	bob.AddPeriods(values.BusyTentativeFreeBusyType, values.NewPeriod(at(10, 30), at(12, 30)))
+	bob.AddPeriods(values.FreeFreeBusyType, values.NewPeriod(at(14, 0), at(17, 0)))
+
+	slots := CommonFreeSlots(at(9, 0), at(17, 0), time.Hour, alice, bob)
+	c.Assert(slots, HasLen, 1)
+	c.Assert(slots[0].Start(), Equals, at(14, 0))
+	c.Assert(slots[0].End(), Equals, at(17, 0))
+
+	slots = CommonFreeSlots(at(9, 0), at(17, 0), 30*time.Minute, alice, bob)
+	c.Assert(slots, HasLen, 3)
+	c.Assert(slots[0].Start(), Equals, at(10, 0))
+	c.Assert(slots[0].End(), Equals, at(10, 30))
+	c.Assert(slots[1].Start(), Equals, at(12, 30))
+
+	c.Assert(CommonFreeSlots(at(9, 0), at(17, 0), 4*time.Hour, alice, bob), HasLen, 0)
+
+}
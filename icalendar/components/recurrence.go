@@ -0,0 +1,155 @@
+package components
+
+import (
+	"sort"
+	"time"
+)
+
+// a single instance of the recurrence set of an event
+type EventInstance struct {
+	// the event describing the instance, either the master event or the override of the instance
+	Event *Event
+	// the start the instance has in the recurrence set of the master event, as identified by a RECURRENCE-ID
+	RecurrenceId time.Time
+	Start        time.Time
+	End          time.Time
+}
+
+// checks if the instance overlaps a range of time, following the rules of RFC 4791 section 9.9
+// instances of no length overlap the range when they start within it
+func (i *EventInstance) Overlaps(start, end time.Time) bool {
+	if i.End.After(i.Start) {
+		return i.Start.Before(end) && i.End.After(start)
+	} else {
+		return !i.Start.Before(start) && i.Start.Before(end)
+	}
+}
+
+// computes the instances of a set of events that overlap a range of time, sorted by start.
+// events sharing a UID form a recurrence set: the master event is expanded with its RRULE, RDATE and EXDATE
+// properties, while overrides holding a RECURRENCE-ID replace the instances they identify. events without a UID,
+// and master events sharing the UID of an earlier master, are expanded on their own.
+// dates and floating times are interpreted in the location of the range start
+func ExpandEvents(events []*Event, start, end time.Time) []*EventInstance {
+
+	loc := start.Location()
+	var sets []*eventSet
+	byUID := make(map[string]*eventSet)
+
+	for _, e := range events {
+		if e == nil || e.DateStart == nil {
+			continue
+		}
+		set := byUID[e.UID]
+		if set == nil || e.UID == "" || (!e.IsRecurrence() && set.master != nil) {
+			set = new(eventSet)
+			sets = append(sets, set)
+			if e.UID != "" && byUID[e.UID] == nil {
+				byUID[e.UID] = set
+			}
+		}
+		if e.IsRecurrence() {
+			set.overrides = append(set.overrides, e)
+		} else {
+			set.master = e
+		}
+	}
+
+	var instances []*EventInstance
+	for _, set := range sets {
+		replaced := make(map[int64]bool)
+		for _, o := range set.overrides {
+			id := o.RecurrenceId.In(loc)
+			replaced[id.Unix()] = true
+			if i := o.instance(id, o.DateStart.In(loc), loc); i.Overlaps(start, end) {
+				instances = append(instances, i)
+			}
+		}
+		if master := set.master; master != nil {
+			for _, t := range master.recurrenceSet(start, end, loc) {
+				if i := master.instance(t, t, loc); !replaced[t.Unix()] && i.Overlaps(start, end) {
+					instances = append(instances, i)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(instances, func(i, j int) bool {
+		return instances[i].Start.Before(instances[j].Start)
+	})
+
+	return instances
+
+}
+
+// the events of a recurrence set, as grouped for expansion
+type eventSet struct {
+	master    *Event
+	overrides []*Event
+}
+
+// computes the start times of the recurrence set of an event that may overlap a range of time
+func (e *Event) recurrenceSet(start time.Time, limit time.Time, loc *time.Location) []time.Time {
+
+	dtstart := e.DateStart.In(loc)
+	if len(e.RecurrenceRules) == 0 && e.RecurrenceDateTimes == nil {
+		return []time.Time{dtstart}
+	}
+
+	// instances starting before the range may still overlap it, a day being kept as a margin for all-day events
+	first := e.instance(dtstart, dtstart, loc)
+	from := start.Add(-first.End.Sub(first.Start) - 24*time.Hour)
+
+	seen := make(map[int64]bool)
+	var starts []time.Time
+	add := func(t time.Time) {
+		if !seen[t.Unix()] && t.Before(limit) {
+			seen[t.Unix()] = true
+			starts = append(starts, t)
+		}
+	}
+
+	add(dtstart)
+	for _, r := range e.RecurrenceRules {
+		for _, t := range r.ExpandRange(dtstart, from, limit) {
+			add(t)
+		}
+	}
+	if e.RecurrenceDateTimes != nil {
+		for _, d := range *e.RecurrenceDateTimes {
+			add(d.In(loc))
+		}
+	}
+
+	if e.ExceptionDateTimes != nil {
+		for _, d := range *e.ExceptionDateTimes {
+			delete(seen, d.In(loc).Unix())
+		}
+	}
+
+	var kept []time.Time
+	for _, t := range starts {
+		if seen[t.Unix()] {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+
+}
+
+// creates the instance of an event starting at a given time, lasting as long as the event
+func (e *Event) instance(id time.Time, start time.Time, loc *time.Location) *EventInstance {
+	i := &EventInstance{Event: e, RecurrenceId: id, Start: start, End: start}
+	if e.DateEnd != nil && e.DateStart.IsDate() {
+		// all-day events last whole days, whatever the length of the days
+		days := int(e.DateEnd.In(loc).Sub(e.DateStart.In(loc)).Hours()/24 + 0.5)
+		i.End = start.AddDate(0, 0, days)
+	} else if e.DateEnd != nil {
+		i.End = start.Add(e.DateEnd.In(loc).Sub(e.DateStart.In(loc)))
+	} else if e.Duration != nil {
+		i.End = start.Add(e.Duration.NativeDuration())
+	} else if e.DateStart.IsDate() {
+		i.End = start.AddDate(0, 0, 1)
+	}
+	return i
+}
@@ -0,0 +1,82 @@
+package components
+
+import (
+	"sort"
+	"time"
+)
+
+// the events sharing a UID within a calendar object: the master event holding the recurrence rules, if any,
+// along with the overrides replacing some of its instances
+type EventSeries struct {
+	UID string
+	// the event without a RECURRENCE-ID, nil when only instances are known, as when a server expanded the recurrence set
+	Master *Event
+	// the events holding a RECURRENCE-ID, sorted by recurrence id
+	Overrides []*Event
+}
+
+// groups events by UID, keeping the series in the order their first event appears
+func GroupEvents(events []*Event) []*EventSeries {
+
+	var series []*EventSeries
+	byUID := make(map[string]*EventSeries)
+
+	for _, e := range events {
+		if e == nil {
+			continue
+		}
+		s, found := byUID[e.UID]
+		if !found {
+			s = &EventSeries{UID: e.UID}
+			byUID[e.UID] = s
+			series = append(series, s)
+		}
+		if e.IsRecurrence() {
+			s.Overrides = append(s.Overrides, e)
+		} else if s.Master == nil {
+			s.Master = e
+		}
+	}
+
+	for _, s := range series {
+		sort.SliceStable(s.Overrides, func(i, j int) bool {
+			return s.Overrides[i].RecurrenceId.In(time.UTC).Before(s.Overrides[j].RecurrenceId.In(time.UTC))
+		})
+	}
+
+	return series
+
+}
+
+// groups the events of the calendar by UID, see GroupEvents
+func (c *Calendar) EventSeries() []*EventSeries {
+	return GroupEvents(c.Events)
+}
+
+// checks if the series recurs, either through the recurrence rules of its master or through several instances
+func (s *EventSeries) IsRecurring() bool {
+	if m := s.Master; m != nil && (len(m.RecurrenceRules) > 0 || m.RecurrenceDateTimes != nil) {
+		return true
+	}
+	return len(s.Overrides) > 0
+}
+
+// returns the override of the instance identified by a recurrence id, or nil if the instance is not overridden.
+// dates and floating times are interpreted in the location of the recurrence id
+func (s *EventSeries) Override(recurrenceId time.Time) *Event {
+	for _, o := range s.Overrides {
+		if o.RecurrenceId.In(recurrenceId.Location()).Equal(recurrenceId) {
+			return o
+		}
+	}
+	return nil
+}
+
+// returns all the events of the series, master first, as expected by ExpandEvents
+func (s *EventSeries) Events() []*Event {
+	var events []*Event
+	if s.Master != nil {
+		events = append(events, s.Master)
+	}
+	return append(events, s.Overrides...)
+}
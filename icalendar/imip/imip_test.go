@@ -0,0 +1,106 @@
+package imip
+
+import (
+	"bytes"
+	"errors"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	. "gopkg.in/check.v1"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type ImipSuite struct{}
+
+var _ = Suite(new(ImipSuite))
+
+func TestImip(t *testing.T) { TestingT(t) }
+
+func (s *ImipSuite) parseFixture(c *C, name string) (*Message, error) {
+	f, err := os.Open(filepath.Join("testdata", name))
+	c.Assert(err, IsNil)
+	defer f.Close()
+	return Parse(f)
+}
+
+func (s *ImipSuite) TestParseRequest(c *C) {
+
+	m, err := s.parseFixture(c, "request.eml")
+	c.Assert(err, IsNil)
+	c.Assert(m.Method(), Equals, values.Method(values.RequestMethod))
+	c.Assert(m.Subject, Equals, "Invitation: Design review")
+	c.Assert(m.From.Address, Equals, "alice@example.com")
+	c.Assert(m.To, HasLen, 1)
+	c.Assert(m.Date.Equal(time.Date(2009, 6, 1, 8, 0, 0, 0, time.UTC)), Equals, true)
+	c.Assert(m.Text, Matches, "You have been invited.*")
+
+	c.Assert(m.Calendar.Events, HasLen, 1)
+	e := m.Calendar.Events[0]
+	c.Assert(e.Summary, Equals, "Design review")
+	c.Assert(e.Attendees, HasLen, 1)
+	c.Assert(e.Attendees[0].Rsvp, Equals, true)
+
+}
+
+func (s *ImipSuite) TestParseNestedReply(c *C) {
+
+	m, err := s.parseFixture(c, "reply.eml")
+	c.Assert(err, IsNil)
+	c.Assert(m.Method(), Equals, values.Method(values.ReplyMethod))
+	c.Assert(m.Text, Matches, "Bob accepted.*\\s*")
+	c.Assert(m.Calendar.Events, HasLen, 1)
+	c.Assert(m.Calendar.Events[0].Attendees[0].ParticipationStatus, Equals, values.ParticipationStatus(values.AcceptedParticipationStatus))
+
+}
+
+func (s *ImipSuite) TestParseInvalid(c *C) {
+
+	_, err := s.parseFixture(c, "mismatch.eml")
+	c.Assert(err, ErrorMatches, "(?s).*does not match.*")
+
+	_, err = s.parseFixture(c, "plain.eml")
+	c.Assert(errors.Is(err, ErrNoCalendar), Equals, true)
+
+}
+
+func (s *ImipSuite) TestRenderRoundTrip(c *C) {
+
+	start := time.Date(2009, 6, 2, 14, 0, 0, 0, time.UTC)
+	e := components.NewEventWithEnd("review", start, start.Add(time.Hour))
+	e.Summary = "Revue de conception"
+	e.Location = values.NewLocation("Salle 101")
+	e.Organizer = values.NewOrganizerContact("Alice", "alice@example.com")
+	e.Attendees = []*values.AttendeeContact{values.NewAttendeeContact("Bob", "bob@example.com")}
+	cal := components.NewCalendar(e)
+	cal.Method = values.RequestMethod
+
+	msg := &Message{
+		From:     &mail.Address{Name: "Alice", Address: "alice@example.com"},
+		To:       []*mail.Address{{Name: "Bob", Address: "bob@example.com"}},
+		Subject:  "Invitation : réunion",
+		Date:     start.Add(-time.Hour),
+		Calendar: cal,
+	}
+	raw, err := Render(msg)
+	c.Assert(err, IsNil)
+	c.Assert(string(raw), Matches, "(?s)MIME-Version: 1.0\r\n.*Content-Type: multipart/alternative; boundary=.*")
+	c.Assert(string(raw), Matches, "(?s).*\r\nContent-Type: text/calendar; charset=UTF-8; method=REQUEST\r\n.*")
+
+	parsed, err := Parse(bytes.NewReader(raw))
+	c.Assert(err, IsNil)
+	c.Assert(parsed.Subject, Equals, msg.Subject)
+	c.Assert(parsed.From.String(), Equals, msg.From.String())
+	c.Assert(parsed.Method(), Equals, values.Method(values.RequestMethod))
+	c.Assert(parsed.Text, Matches, "(?s)You have been invited.*Title: Revue de conception\r\n.*Where: Salle 101\r\n.*")
+	c.Assert(parsed.Calendar.Events, HasLen, 1)
+	c.Assert(parsed.Calendar.Events[0].Summary, Equals, e.Summary)
+	c.Assert(parsed.Calendar.Events[0].DateStart.NativeTime().Equal(start), Equals, true)
+
+	cal.Method = ""
+	_, err = Render(msg)
+	c.Assert(err, NotNil)
+
+}
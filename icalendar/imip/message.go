@@ -0,0 +1,231 @@
+package imip
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/dolanor/caldav-go/icalendar"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	"github.com/dolanor/caldav-go/utils"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// returned when a MIME message carries no iCalendar object
+var ErrNoCalendar = errors.New("message holds no text/calendar part")
+
+// an iTIP message transported by email, see RFC 6047
+type Message struct {
+	From    *mail.Address
+	To      []*mail.Address
+	Subject string
+	Date    time.Time
+	// the plain text alternative of the calendar, a summary is generated when rendering if empty
+	Text string
+	// the iTIP message itself, whose method must be set
+	Calendar *components.Calendar
+}
+
+// returns the iTIP method of the message, such as REQUEST or REPLY
+func (m *Message) Method() values.Method {
+	if m.Calendar == nil {
+		return ""
+	}
+	return m.Calendar.Method
+}
+
+// renders the message as a multipart/alternative MIME message, holding a text/plain summary
+// followed by the text/calendar part carrying the method
+func Render(m *Message) ([]byte, error) {
+
+	if m.Calendar == nil || m.Calendar.Method == "" {
+		return nil, utils.NewError(Render, "an iTIP calendar with a method is required", m, nil)
+	}
+
+	ical, err := icalendar.Marshal(m.Calendar)
+	if err != nil {
+		return nil, utils.NewError(Render, "unable to encode calendar", m, err)
+	}
+
+	text := m.Text
+	if text == "" {
+		text = Summarize(m.Calendar)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	var out bytes.Buffer
+	header := make(textproto.MIMEHeader)
+	header.Set("MIME-Version", "1.0")
+	if m.From != nil {
+		header.Set("From", m.From.String())
+	}
+	if len(m.To) > 0 {
+		var to []string
+		for _, a := range m.To {
+			to = append(to, a.String())
+		}
+		header.Set("To", strings.Join(to, ", "))
+	}
+	if m.Subject != "" {
+		header.Set("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	}
+	if !m.Date.IsZero() {
+		header.Set("Date", m.Date.Format(time.RFC1123Z))
+	}
+	header.Set("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary()))
+	writeHeader(&out, header)
+
+	if err := writePart(parts, "text/plain; charset=UTF-8", text); err != nil {
+		return nil, utils.NewError(Render, "unable to write text part", m, err)
+	}
+	contentType := fmt.Sprintf("text/calendar; charset=UTF-8; method=%s", m.Calendar.Method)
+	if err := writePart(parts, contentType, ical+"\r\n"); err != nil {
+		return nil, utils.NewError(Render, "unable to write calendar part", m, err)
+	} else if err := parts.Close(); err != nil {
+		return nil, utils.NewError(Render, "unable to close message", m, err)
+	}
+
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+
+}
+
+// parses a MIME message, finding the iTIP message in the first text/calendar part, whether the message is
+// multipart/alternative, multipart/mixed or a single part. the method of the part must match the calendar
+func Parse(r io.Reader) (*Message, error) {
+
+	raw, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, utils.NewError(Parse, "unable to read message", r, err)
+	}
+
+	m := new(Message)
+	decoder := new(mime.WordDecoder)
+	if from, err := raw.Header.AddressList("From"); err == nil && len(from) > 0 {
+		m.From = from[0]
+	}
+	if to, err := raw.Header.AddressList("To"); err == nil {
+		m.To = to
+	}
+	if subject, err := decoder.DecodeHeader(raw.Header.Get("Subject")); err == nil {
+		m.Subject = subject
+	}
+	if date, err := raw.Header.Date(); err == nil {
+		m.Date = date
+	}
+
+	header := textproto.MIMEHeader(raw.Header)
+	if err := m.walk(header, raw.Body); err != nil {
+		return nil, utils.NewError(Parse, "unable to parse message", m, err)
+	} else if m.Calendar == nil {
+		return nil, utils.NewError(Parse, "unable to parse message", m, ErrNoCalendar)
+	}
+
+	return m, nil
+
+}
+
+// visits a MIME part and its children, keeping the first plain text and calendar parts
+func (m *Message) walk(header textproto.MIMEHeader, body io.Reader) error {
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		parts := multipart.NewReader(body, params["boundary"])
+		for {
+			if part, err := parts.NextPart(); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return utils.NewError(m.walk, "unable to read part", m, err)
+			} else if err := m.walk(part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/calendar" && mediaType != "application/ics" {
+		return nil
+	}
+
+	content, err := ioutil.ReadAll(decodeTransfer(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return utils.NewError(m.walk, "unable to read part content", m, err)
+	}
+
+	if mediaType == "text/plain" {
+		if m.Text == "" {
+			m.Text = string(content)
+		}
+		return nil
+	} else if m.Calendar != nil {
+		return nil // only the first calendar part holds the iTIP message
+	}
+
+	cal := new(components.Calendar)
+	method := values.Method(strings.ToUpper(params["method"]))
+	if err := icalendar.Unmarshal(string(content), cal); err != nil {
+		return utils.NewError(m.walk, "unable to decode calendar", m, err)
+	} else if cal.Method == "" {
+		cal.Method = method
+	} else if method != "" && !strings.EqualFold(string(cal.Method), string(method)) {
+		msg := fmt.Sprintf("part method %s does not match calendar method %s", method, cal.Method)
+		return utils.NewError(m.walk, msg, m, nil)
+	}
+	m.Calendar = cal
+
+	return nil
+
+}
+
+// decodes the content of a part according to its transfer encoding.
+// note that multipart readers already decode quoted-printable parts
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// writes a part of the message, encoded as quoted-printable
+func writePart(parts *multipart.Writer, contentType string, content string) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	if w, err := parts.CreatePart(header); err != nil {
+		return err
+	} else {
+		qp := quotedprintable.NewWriter(w)
+		if _, err := io.WriteString(qp, content); err != nil {
+			return err
+		}
+		return qp.Close()
+	}
+}
+
+// writes the top level headers of the message in a stable order
+func writeHeader(w io.Writer, header textproto.MIMEHeader) {
+	for _, name := range []string{"MIME-Version", "Date", "From", "To", "Subject", "Content-Type"} {
+		if value := header.Get(name); value != "" {
+			fmt.Fprintf(w, "%s: %s\r\n", name, value)
+		}
+	}
+	fmt.Fprint(w, "\r\n")
+}
@@ -0,0 +1,97 @@
+package imip
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	"time"
+)
+
+// describes an iTIP message in plain text, for mail readers that do not understand text/calendar parts
+func Summarize(cal *components.Calendar) string {
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, intro(cal.Method))
+	if len(cal.Events) <= 0 || cal.Events[0] == nil {
+		return b.String()
+	}
+
+	e := cal.Events[0]
+	fmt.Fprintln(&b)
+	if e.Summary != "" {
+		fmt.Fprintf(&b, "Title: %s\n", e.Summary)
+	}
+	if when := describeTime(e); when != "" {
+		fmt.Fprintf(&b, "When: %s\n", when)
+	}
+	if e.Location != nil && e.Location.String() != "" {
+		fmt.Fprintf(&b, "Where: %s\n", e.Location)
+	}
+	if e.Organizer != nil {
+		fmt.Fprintf(&b, "Organizer: %s\n", e.Organizer.Entry.String())
+	}
+	if cal.Method == values.ReplyMethod {
+		for _, a := range e.Attendees {
+			if a != nil && a.ParticipationStatus != "" {
+				fmt.Fprintf(&b, "%s: %s\n", a.Entry.String(), a.ParticipationStatus)
+			}
+		}
+	}
+	if e.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", e.Description)
+	}
+
+	return b.String()
+
+}
+
+// introduces the message according to its method
+func intro(method values.Method) string {
+	switch method {
+	case values.RequestMethod:
+		return "You have been invited to the following event."
+	case values.ReplyMethod:
+		return "An attendee replied to the following event."
+	case values.CancelMethod:
+		return "The following event has been cancelled."
+	case values.CounterMethod:
+		return "An attendee proposed changes to the following event."
+	case values.DeclineCounterMethod:
+		return "The organizer declined the changes proposed to the following event."
+	case values.RefreshMethod:
+		return "An attendee asked for the latest version of the following event."
+	case values.AddMethod:
+		return "Instances have been added to the following event."
+	default:
+		return "The following event has been published."
+	}
+}
+
+// describes when an event takes place, including its time zone
+func describeTime(e *components.Event) string {
+	if e.DateStart == nil {
+		return ""
+	}
+	start := e.DateStart.NativeTime()
+	if e.DateStart.IsDate() {
+		return start.Format("Monday, January 2, 2006") + " (all day)"
+	}
+	when := start.Format("Monday, January 2, 2006 15:04 MST")
+	if e.DateEnd != nil {
+		when += " - " + e.DateEnd.NativeTime().Format(endLayout(start, e.DateEnd.NativeTime()))
+	} else if e.Duration != nil {
+		end := start.Add(e.Duration.NativeDuration())
+		when += " - " + end.Format(endLayout(start, end))
+	}
+	return when
+}
+
+// returns a shorter layout for ends on the same day as the start
+func endLayout(start, end time.Time) string {
+	y, m, d := start.Date()
+	if ey, em, ed := end.In(start.Location()).Date(); ey == y && em == m && ed == d {
+		return "15:04 MST"
+	}
+	return "Monday, January 2, 2006 15:04 MST"
+}
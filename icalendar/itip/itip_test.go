@@ -0,0 +1,199 @@
+package itip
+
+import (
+	"errors"
+	"github.com/dolanor/caldav-go/icalendar"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	. "gopkg.in/check.v1"
+	"testing"
+	"time"
+)
+
+type ItipSuite struct {
+	start time.Time
+}
+
+var _ = Suite(new(ItipSuite))
+
+func TestItip(t *testing.T) { TestingT(t) }
+
+func (s *ItipSuite) SetUpTest(c *C) {
+	s.start = time.Date(2009, 6, 2, 12, 0, 0, 0, time.UTC)
+}
+
+// a weekly meeting organized by alice, with bob and carol attending
+func (s *ItipSuite) meeting() *components.Event {
+	e := components.NewEventWithEnd("standup", s.start, s.start.Add(time.Hour))
+	e.DateStamp = values.NewDateTime(s.start.AddDate(0, 0, -7))
+	e.Summary = "Standup"
+	e.Sequence = 2
+	e.Organizer = values.NewOrganizerContact("Alice", "alice@example.com")
+	e.Attendees = []*values.AttendeeContact{
+		values.NewAttendeeContact("Bob", "bob@example.com"),
+		values.NewAttendeeContact("Carol", "carol@example.com"),
+	}
+	e.AddRecurrenceRules(values.NewRecurrenceRule(values.WeekRecurrenceFrequency))
+	return e
+}
+
+func (s *ItipSuite) TestRequest(c *C) {
+	cal, err := NewRequest(s.meeting())
+	c.Assert(err, IsNil)
+	c.Assert(cal.Method, Equals, values.RequestMethod)
+	enc, err := icalendar.Marshal(cal)
+	c.Assert(err, IsNil)
+	c.Assert(enc, Matches, "(?s).*\r\nMETHOD:REQUEST\r\n.*\r\nSEQUENCE:2\r\n.*\r\nATTENDEE;CN=Carol:MAILTO:carol@example.com\r\n.*")
+
+	noAttendees := s.meeting()
+	noAttendees.Attendees = nil
+	_, err = NewRequest(noAttendees)
+	c.Assert(err, NotNil)
+}
+
+func (s *ItipSuite) TestCancelInstance(c *C) {
+
+	master := s.meeting()
+	instance := s.start.AddDate(0, 0, 7)
+	cal, err := NewCancelInstance(master, instance)
+	c.Assert(err, IsNil)
+	c.Assert(cal.Method, Equals, values.CancelMethod)
+	c.Assert(cal.Events, HasLen, 1)
+
+	e := cal.Events[0]
+	c.Assert(e.UID, Equals, "standup")
+	c.Assert(e.RecurrenceId.NativeTime(), Equals, instance)
+	c.Assert(e.DateEnd.NativeTime(), Equals, instance.Add(time.Hour))
+	c.Assert(e.Status, Equals, values.EventStatus(values.CancelledEventStatus))
+	c.Assert(e.Sequence, Equals, 3)
+	c.Assert(e.RecurrenceRules, HasLen, 0)
+	c.Assert(e.Attendees, HasLen, 2)
+	c.Assert(master.Sequence, Equals, 2)
+
+	enc, err := icalendar.Marshal(cal)
+	c.Assert(err, IsNil)
+	c.Assert(enc, Matches, "(?s).*\r\nRECURRENCE-ID:20090609T120000Z\r\n.*")
+	c.Assert(enc, Not(Matches), "(?s).*RRULE.*")
+
+	_, err = NewCancelInstance(components.NewEventWithDuration("once", s.start, time.Hour), instance)
+	c.Assert(err, NotNil)
+
+}
+
+func (s *ItipSuite) TestReply(c *C) {
+
+	stored := components.NewCalendar(s.meeting())
+	invitation := s.meeting()
+
+	reply, err := NewReply(invitation, "mailto:BOB@example.com", values.AcceptedParticipationStatus)
+	c.Assert(err, IsNil)
+	c.Assert(reply.Events[0].Attendees, HasLen, 1)
+	enc, err := icalendar.Marshal(reply)
+	c.Assert(err, IsNil)
+	c.Assert(enc, Matches, "(?s).*\r\nMETHOD:REPLY\r\n.*\r\nATTENDEE;CN=Bob;PARTSTAT=ACCEPTED:MAILTO:bob@example.com\r\n.*")
+
+	// the reply goes through the wire before being applied
+	received := new(components.Calendar)
+	c.Assert(icalendar.Unmarshal(enc, received), IsNil)
+	c.Assert(ApplyReply(stored, received), IsNil)
+	c.Assert(stored.Events[0].Attendees[0].ParticipationStatus, Equals, values.ParticipationStatus(values.AcceptedParticipationStatus))
+	c.Assert(stored.Events[0].Attendees[1].ParticipationStatus, Equals, values.ParticipationStatus(""))
+
+	_, err = NewReply(invitation, "mallory@example.com", values.AcceptedParticipationStatus)
+	c.Assert(errors.Is(err, ErrUnknownAttendee), Equals, true)
+
+	invitation.DateStart = nil
+	_, err = NewReply(invitation, "bob@example.com", values.AcceptedParticipationStatus)
+	c.Assert(err, ErrorMatches, "(?s).*event has no start.*")
+
+}
+
+func (s *ItipSuite) TestReplyToInstance(c *C) {
+
+	stored := components.NewCalendar(s.meeting())
+	instance := s.meeting()
+	instance.RecurrenceId = values.NewDateTime(s.start.AddDate(0, 0, 14))
+	instance.DateStart = instance.RecurrenceId
+
+	reply, err := NewReply(instance, "carol@example.com", values.DeclinedParticipationStatus)
+	c.Assert(err, IsNil)
+	c.Assert(ApplyReply(stored, reply), IsNil)
+	c.Assert(stored.Events, HasLen, 2)
+	c.Assert(stored.Events[0].Attendees[1].ParticipationStatus, Equals, values.ParticipationStatus(""))
+
+	override := stored.Events[1]
+	c.Assert(override.RecurrenceId.NativeTime(), Equals, s.start.AddDate(0, 0, 14))
+	c.Assert(override.RecurrenceRules, HasLen, 0)
+	c.Assert(override.Attendees[1].ParticipationStatus, Equals, values.ParticipationStatus(values.DeclinedParticipationStatus))
+
+}
+
+func (s *ItipSuite) TestOutdatedReply(c *C) {
+
+	stored := components.NewCalendar(s.meeting())
+	old := s.meeting()
+	old.Sequence = 1
+	reply, err := NewReply(old, "bob@example.com", values.AcceptedParticipationStatus)
+	c.Assert(err, IsNil)
+	c.Assert(errors.Is(ApplyReply(stored, reply), ErrOutdated), Equals, true)
+
+	// replies of an attendee are ordered against the previous replies of the same attendee
+	reply, err = NewReply(s.meeting(), "bob@example.com", values.AcceptedParticipationStatus)
+	c.Assert(err, IsNil)
+	reply.Events[0].DateStamp = values.NewDateTime(s.start.AddDate(0, 0, -5))
+	c.Assert(ApplyReply(stored, reply), IsNil)
+	reply, err = NewReply(s.meeting(), "bob@example.com", values.DeclinedParticipationStatus)
+	c.Assert(err, IsNil)
+	reply.Events[0].DateStamp = values.NewDateTime(s.start.AddDate(0, 0, -6))
+	c.Assert(errors.Is(ApplyReply(stored, reply), ErrOutdated), Equals, true)
+	c.Assert(stored.Events[0].Attendees[0].ParticipationStatus, Equals, values.ParticipationStatus(values.AcceptedParticipationStatus))
+
+	// a late reply stays valid after the organizer touched the event without a new revision
+	stored.Events[0].DateStamp = values.NewDateTime(s.start)
+	reply, err = NewReply(s.meeting(), "carol@example.com", values.TentativeParticipationStatus)
+	c.Assert(err, IsNil)
+	reply.Events[0].DateStamp = values.NewDateTime(s.start.AddDate(0, 0, -1))
+	c.Assert(ApplyReply(stored, reply), IsNil)
+	c.Assert(stored.Events[0].Attendees[1].ParticipationStatus, Equals, values.ParticipationStatus(values.TentativeParticipationStatus))
+
+	// the state kept about attendees is not sent to them
+	request, err := NewRequest(stored.Events[0])
+	c.Assert(err, IsNil)
+	c.Assert(request.Events[0].Attendees[0].ExtraParams, HasLen, 0)
+	c.Assert(stored.Events[0].Attendees[0].ExtraParams, HasLen, 1)
+
+	reply, err = NewReply(s.meeting(), "bob@example.com", values.AcceptedParticipationStatus)
+	c.Assert(err, IsNil)
+	reply.Events[0].UID = "other"
+	c.Assert(errors.Is(ApplyReply(stored, reply), ErrUnknownEvent), Equals, true)
+
+}
+
+func (s *ItipSuite) TestCounter(c *C) {
+
+	stored := components.NewCalendar(s.meeting())
+	stored.Events[0].Attendees[1].ParticipationStatus = values.AcceptedParticipationStatus
+
+	proposal := s.meeting()
+	proposal.DateStamp = values.NewDateTime(s.start)
+	proposal.DateStart = values.NewDateTime(s.start.Add(time.Hour))
+	proposal.DateEnd = values.NewDateTime(s.start.Add(2 * time.Hour))
+	proposal.Attendees = proposal.Attendees[:1]
+	counter := components.NewCalendar(proposal)
+	counter.Method = values.CounterMethod
+
+	c.Assert(ApplyCounter(stored, counter), IsNil)
+	e := stored.Events[0]
+	c.Assert(e.DateStart.NativeTime(), Equals, s.start.Add(time.Hour))
+	c.Assert(e.DateEnd.NativeTime(), Equals, s.start.Add(2*time.Hour))
+	c.Assert(e.Sequence, Equals, 3)
+	c.Assert(e.Summary, Equals, "Standup")
+	c.Assert(e.Attendees[1].ParticipationStatus, Equals, values.ParticipationStatus(values.NeedsActionParticipationStatus))
+
+	// the same proposal no longer applies to the new revision
+	c.Assert(errors.Is(ApplyCounter(stored, counter), ErrOutdated), Equals, true)
+
+	counter.Method = values.ReplyMethod
+	c.Assert(ApplyCounter(stored, counter), NotNil)
+
+}
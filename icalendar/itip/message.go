@@ -0,0 +1,224 @@
+package itip
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/icalendar/properties"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	"github.com/dolanor/caldav-go/utils"
+	"strings"
+	"time"
+)
+
+var (
+	// returned when a message refers to an older revision of the event than the stored one
+	ErrOutdated = errors.New("message is older than the stored event")
+	// returned when the calendar user sending a message is not an attendee of the event
+	ErrUnknownAttendee = errors.New("calendar user is not an attendee of the event")
+	// returned when a message does not refer to any of the stored events
+	ErrUnknownEvent = errors.New("message does not refer to a stored event")
+)
+
+// the attendee parameter recording, in the calendar of the organizer, the DTSTAMP of the last message processed
+// from the attendee, named as CalendarServer does. it is left out of the messages sent to attendees
+const attendeeStampParameterName properties.ParameterName = "X-CALENDARSERVER-DTSTAMP"
+
+// creates a REQUEST inviting the attendees of an event, see RFC 5546 section 3.2.2.
+// overrides of instances of a recurring event may be sent along with the master event, as long as they share its UID
+func NewRequest(events ...*components.Event) (*components.Calendar, error) {
+
+	if len(events) <= 0 || events[0] == nil {
+		return nil, utils.NewError(NewRequest, "no event provided", events, nil)
+	}
+
+	cal := newMessage(values.RequestMethod)
+	for i, e := range events {
+		if e == nil || e.UID != events[0].UID {
+			msg := fmt.Sprintf("event %d does not share the UID of the first event", i)
+			return nil, utils.NewError(NewRequest, msg, events, nil)
+		} else if e.Organizer == nil {
+			msg := fmt.Sprintf("event %d has no organizer", i)
+			return nil, utils.NewError(NewRequest, msg, events, nil)
+		} else if len(e.Attendees) <= 0 {
+			msg := fmt.Sprintf("event %d has no attendees", i)
+			return nil, utils.NewError(NewRequest, msg, events, nil)
+		}
+		cal.Events = append(cal.Events, stamped(e))
+	}
+
+	return cal, nil
+
+}
+
+// creates a CANCEL removing an event, along with all of its instances, from the calendars of its attendees,
+// see RFC 5546 section 3.2.5. the message carries the next sequence number of the event, which the organizer
+// is expected to store as well
+func NewCancel(event *components.Event) (*components.Calendar, error) {
+
+	if err := checkOrganizerEvent(event); err != nil {
+		return nil, utils.NewError(NewCancel, "unable to cancel event", event, err)
+	}
+
+	e := stamped(event)
+	e.Status = values.CancelledEventStatus
+	e.Sequence++
+
+	cal := newMessage(values.CancelMethod)
+	cal.Events = append(cal.Events, e)
+	return cal, nil
+
+}
+
+// creates a CANCEL removing a single instance of a recurring event, identified by its original start time,
+// see RFC 5546 section 3.2.5. the organizer is expected to exclude the instance from the master event and store
+// the next sequence number the message carries
+func NewCancelInstance(master *components.Event, recurrenceId time.Time) (*components.Calendar, error) {
+
+	if err := checkOrganizerEvent(master); err != nil {
+		return nil, utils.NewError(NewCancelInstance, "unable to cancel instance", master, err)
+	} else if len(master.RecurrenceRules) == 0 && master.RecurrenceDateTimes == nil {
+		return nil, utils.NewError(NewCancelInstance, "event does not recur", master, nil)
+	}
+
+	e := newInstance(master, recurrenceDateTime(master, recurrenceId))
+	e.DateStamp = values.NewDateTime(time.Now().UTC())
+	e.Attendees = sentAttendees(e.Attendees)
+	e.Status = values.CancelledEventStatus
+	e.Sequence++
+
+	cal := newMessage(values.CancelMethod)
+	cal.Events = append(cal.Events, e)
+	return cal, nil
+
+}
+
+// creates a REPLY telling the organizer of an event about the participation status of one of its attendees,
+// see RFC 5546 section 3.2.3. the reply only lists the replying attendee, and applies to a single instance
+// if the event is an override
+func NewReply(event *components.Event, attendee string, status values.ParticipationStatus) (*components.Calendar, error) {
+
+	var replying *values.AttendeeContact
+	if event == nil || event.Organizer == nil {
+		return nil, utils.NewError(NewReply, "event has no organizer", event, nil)
+	} else if event.DateStart == nil {
+		return nil, utils.NewError(NewReply, "event has no start", event, nil)
+	} else if a := findAttendee(event, attendee); a == nil {
+		return nil, utils.NewError(NewReply, "unable to reply", event, ErrUnknownAttendee)
+	} else {
+		// the reply keeps the parameters describing the attendee, but none of the scheduling state of the organizer
+		replying = sentAttendees([]*values.AttendeeContact{a})[0]
+		replying.ParticipationStatus = status
+		replying.Rsvp = false
+		replying.ScheduleStatus = nil
+		replying.ScheduleForceSend = ""
+	}
+
+	e := components.NewEvent(event.UID, event.DateStart.NativeTime())
+	e.DateStart = event.DateStart
+	e.DateEnd = event.DateEnd
+	e.Duration = event.Duration
+	e.RecurrenceId = event.RecurrenceId
+	e.Sequence = event.Sequence
+	e.Organizer = event.Organizer
+	e.Attendees = []*values.AttendeeContact{replying}
+
+	cal := newMessage(values.ReplyMethod)
+	cal.Events = append(cal.Events, e)
+	return cal, nil
+
+}
+
+// creates a new calendar holding a scheduling message
+func newMessage(method values.Method) *components.Calendar {
+	cal := components.NewCalendar()
+	cal.Method = method
+	return cal
+}
+
+// copies an event to send it, stamping the copy with the current time
+func stamped(event *components.Event) *components.Event {
+	e := *event
+	e.DateStamp = values.NewDateTime(time.Now().UTC())
+	e.Attendees = sentAttendees(event.Attendees)
+	return &e
+}
+
+// copies attendees to send them, leaving out the state the organizer keeps about them
+func sentAttendees(attendees []*values.AttendeeContact) []*values.AttendeeContact {
+	sent := make([]*values.AttendeeContact, len(attendees))
+	for i, a := range attendees {
+		copied := *a
+		if _, found := a.ExtraParams[attendeeStampParameterName]; found {
+			copied.ExtraParams = make(properties.Params)
+			for name, value := range a.ExtraParams {
+				if name != attendeeStampParameterName {
+					copied.ExtraParams[name] = value
+				}
+			}
+		}
+		sent[i] = &copied
+	}
+	return sent
+}
+
+// checks that an event can be the subject of a message sent by its organizer
+func checkOrganizerEvent(event *components.Event) error {
+	if event == nil {
+		return utils.NewError(checkOrganizerEvent, "no event provided", event, nil)
+	} else if event.Organizer == nil {
+		return utils.NewError(checkOrganizerEvent, "event has no organizer", event, nil)
+	} else if event.DateStart == nil {
+		return utils.NewError(checkOrganizerEvent, "event has no start", event, nil)
+	}
+	return nil
+}
+
+// creates an override for an instance of a recurring event, lasting as long as the master event
+func newInstance(master *components.Event, recurrenceId *values.DateTime) *components.Event {
+	e := *master
+	e.RecurrenceId = recurrenceId
+	e.DateStart = recurrenceId
+	if master.DateEnd != nil {
+		length := master.DateEnd.NativeTime().Sub(master.DateStart.NativeTime())
+		if master.DateEnd.IsDate() {
+			e.DateEnd = values.NewDate(recurrenceId.NativeTime().Add(length))
+		} else {
+			e.DateEnd = values.NewDateTime(recurrenceId.NativeTime().Add(length))
+		}
+	}
+	e.RecurrenceRules = nil
+	e.RecurrenceDateTimes = nil
+	e.ExceptionDateTimes = nil
+	e.Attendees = make([]*values.AttendeeContact, len(master.Attendees))
+	for i, a := range master.Attendees {
+		attendee := *a
+		e.Attendees[i] = &attendee
+	}
+	return &e
+}
+
+// creates the recurrence identifier of an instance, using the value type of the start of the master event
+func recurrenceDateTime(master *components.Event, t time.Time) *values.DateTime {
+	if master.DateStart.IsDate() {
+		return values.NewDate(t)
+	} else {
+		return values.NewDateTime(t)
+	}
+}
+
+// returns the attendee of an event with a calendar user address, ignoring case and mailto: prefixes
+func findAttendee(event *components.Event, address string) *values.AttendeeContact {
+	address = normalizeAddress(address)
+	for _, a := range event.Attendees {
+		if a != nil && normalizeAddress(a.Entry.Address) == address {
+			return a
+		}
+	}
+	return nil
+}
+
+func normalizeAddress(address string) string {
+	address = strings.ToLower(strings.TrimSpace(address))
+	return strings.TrimPrefix(address, "mailto:")
+}
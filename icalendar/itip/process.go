@@ -0,0 +1,189 @@
+package itip
+
+import (
+	"github.com/dolanor/caldav-go/icalendar/components"
+	"github.com/dolanor/caldav-go/icalendar/properties"
+	"github.com/dolanor/caldav-go/icalendar/values"
+	"github.com/dolanor/caldav-go/utils"
+	"time"
+)
+
+// applies a REPLY to the calendar object of the organizer, recording the participation status of the replying
+// attendees, see RFC 5546 section 3.2.3. replies about a single instance of a recurring event are recorded on the
+// override of the instance, which is created when missing.
+// returns ErrOutdated if the reply was sent for an older revision of the event, in which case nothing is changed
+func ApplyReply(stored *components.Calendar, reply *components.Calendar) error {
+
+	if stored == nil || reply == nil {
+		return utils.NewError(ApplyReply, "no calendar provided", reply, nil)
+	} else if reply.Method != values.ReplyMethod {
+		return utils.NewError(ApplyReply, "message is not a reply", reply, nil)
+	}
+
+	for _, r := range reply.Events {
+		if r == nil {
+			continue
+		} else if target, created, err := resolveTarget(stored, r); err != nil {
+			return utils.NewError(ApplyReply, "unable to find the event replied to", reply, err)
+		} else if err := checkRevision(target, r); err != nil {
+			return utils.NewError(ApplyReply, "unable to apply reply", reply, err)
+		} else {
+			// the attendees are all checked before any of them is changed
+			for _, a := range r.Attendees {
+				if findAttendee(target, a.Entry.Address) == nil {
+					return utils.NewError(ApplyReply, "unable to apply reply", reply, ErrUnknownAttendee)
+				}
+			}
+			for _, a := range r.Attendees {
+				attendee := findAttendee(target, a.Entry.Address)
+				if a.ParticipationStatus != "" {
+					attendee.ParticipationStatus = a.ParticipationStatus
+				}
+				recordStamp(attendee, r)
+			}
+			if created {
+				stored.Events = append(stored.Events, target)
+			}
+		}
+	}
+
+	return nil
+
+}
+
+// applies a COUNTER to the calendar object of the organizer, accepting the start, end, location, summary and
+// description proposed by an attendee, see RFC 5546 section 3.2.7. the sequence of the event is incremented and,
+// if the time changed, the participation status of the other attendees is reset, so that a new REQUEST can be sent.
+// returns ErrOutdated if the counter proposal was made for an older revision of the event
+func ApplyCounter(stored *components.Calendar, counter *components.Calendar) error {
+
+	if stored == nil || counter == nil {
+		return utils.NewError(ApplyCounter, "no calendar provided", counter, nil)
+	} else if counter.Method != values.CounterMethod {
+		return utils.NewError(ApplyCounter, "message is not a counter proposal", counter, nil)
+	}
+
+	for _, c := range counter.Events {
+		if c == nil {
+			continue
+		} else if c.DateStart == nil {
+			return utils.NewError(ApplyCounter, "counter proposal has no start", counter, nil)
+		} else if target, created, err := resolveTarget(stored, c); err != nil {
+			return utils.NewError(ApplyCounter, "unable to find the event countered", counter, err)
+		} else if err := checkRevision(target, c); err != nil {
+			return utils.NewError(ApplyCounter, "unable to apply counter proposal", counter, err)
+		} else {
+			rescheduled := !c.DateStart.Equals(target.DateStart) || !sameEnd(c, target)
+			target.DateStart, target.DateEnd, target.Duration = c.DateStart, c.DateEnd, c.Duration
+			if c.Location != nil {
+				target.Location = c.Location
+			}
+			if c.Summary != "" {
+				target.Summary = c.Summary
+			}
+			if c.Description != "" {
+				target.Description = c.Description
+			}
+			if rescheduled {
+				for _, a := range target.Attendees {
+					if findAttendee(c, a.Entry.Address) == nil {
+						a.ParticipationStatus = values.NeedsActionParticipationStatus
+					}
+				}
+			}
+			for _, a := range c.Attendees {
+				if attendee := findAttendee(target, a.Entry.Address); attendee != nil {
+					recordStamp(attendee, c)
+				}
+			}
+			target.Sequence++
+			target.DateStamp = values.NewDateTime(time.Now().UTC())
+			if created {
+				stored.Events = append(stored.Events, target)
+			}
+		}
+	}
+
+	return nil
+
+}
+
+// finds the stored event a message refers to. when the message is about an instance of a recurring event that has
+// no override yet, a new override is returned, which the caller adds to the calendar once the message is applied
+func resolveTarget(stored *components.Calendar, message *components.Event) (*components.Event, bool, error) {
+
+	var master *components.Event
+	for _, e := range stored.Events {
+		if e == nil || e.UID != message.UID {
+			continue
+		} else if !e.IsRecurrence() {
+			master = e
+		}
+		if message.RecurrenceId == nil && !e.IsRecurrence() {
+			return e, false, nil
+		} else if message.RecurrenceId != nil && e.IsRecurrence() && e.RecurrenceId.Equals(message.RecurrenceId) {
+			return e, false, nil
+		}
+	}
+
+	if master == nil || message.RecurrenceId == nil {
+		return nil, false, ErrUnknownEvent
+	}
+
+	return newInstance(master, recurrenceDateTime(master, message.RecurrenceId.NativeTime())), true, nil
+
+}
+
+// checks that a message of an attendee was not sent for an older revision of an event, see RFC 5546 section 2.1.5.
+// within a revision, messages are ordered by their DTSTAMP against the last message processed from the same
+// attendees, since the DTSTAMP of the stored event changes along with the edits of the organizer
+func checkRevision(stored *components.Event, message *components.Event) error {
+	if message.Sequence < stored.Sequence {
+		return ErrOutdated
+	} else if message.Sequence > stored.Sequence || message.DateStamp == nil {
+		return nil
+	}
+	for _, a := range message.Attendees {
+		if attendee := findAttendee(stored, a.Entry.Address); attendee == nil {
+			continue
+		} else if last, found := lastStamp(attendee); found && message.DateStamp.NativeTime().Before(last) {
+			return ErrOutdated
+		}
+	}
+	return nil
+}
+
+// returns the DTSTAMP of the last message processed from an attendee, if any
+func lastStamp(attendee *values.AttendeeContact) (time.Time, bool) {
+	if value, found := attendee.ExtraParams[attendeeStampParameterName]; !found {
+		return time.Time{}, false
+	} else if t, err := time.Parse(values.UTCDateTimeFormatString, value); err != nil {
+		return time.Time{}, false
+	} else {
+		return t, true
+	}
+}
+
+// records the DTSTAMP of a message processed from an attendee
+func recordStamp(attendee *values.AttendeeContact, message *components.Event) {
+	if message.DateStamp == nil {
+		return
+	} else if attendee.ExtraParams == nil {
+		attendee.ExtraParams = make(properties.Params)
+	}
+	attendee.ExtraParams[attendeeStampParameterName] = message.DateStamp.NativeTime().UTC().Format(values.UTCDateTimeFormatString)
+}
+
+// checks if two events end at the same time, whether given by an end or a duration
+func sameEnd(a, b *components.Event) bool {
+	return eventEnd(a).Equal(eventEnd(b))
+}
+
+func eventEnd(e *components.Event) time.Time {
+	if e.DateEnd != nil {
+		return e.DateEnd.NativeTime()
+	} else if e.Duration != nil {
+		return e.DateStart.NativeTime().Add(e.Duration.NativeDuration())
+	}
+	return e.DateStart.NativeTime()
+}
@@ -7,10 +7,13 @@ import (
 	"log"
 	"reflect"
 	"strings"
+	"unicode/utf8"
 )
 
 const (
 	Newline = "\r\n"
+	// the maximum length of a content line in octets, excluding the line break, see RFC 5545 section 3.1
+	MaxLineOctets = 75
 )
 
 var _ = log.Print
@@ -190,7 +193,31 @@ func Marshal(target interface{}) (string, error) {
 	} else if encoded == "" {
 		return "", utils.NewError(Marshal, "unable to encode interface, all methods exhausted", v.Interface(), nil)
 	} else {
-		return encoded, nil
+		return foldLines(encoded), nil
 	}
 
 }
+
+// folds the content lines longer than MaxLineOctets, continuing them on lines starting with a space.
+// lines are only folded between characters, so that multi-octet UTF-8 sequences are kept whole
+func foldLines(encoded string) string {
+	lines := strings.Split(encoded, Newline)
+	for i, line := range lines {
+		if len(line) <= MaxLineOctets {
+			continue
+		}
+		var folded strings.Builder
+		for limit := MaxLineOctets; len(line) > limit; limit = MaxLineOctets - 1 {
+			cut := limit
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			folded.WriteString(line[:cut])
+			folded.WriteString(Newline + " ")
+			line = line[cut:]
+		}
+		folded.WriteString(line)
+		lines[i] = folded.String()
+	}
+	return strings.Join(lines, Newline)
+}
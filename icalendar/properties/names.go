@@ -12,16 +12,39 @@ const (
 	ExceptionDateTimesPropertyName               = "EXDATE"
 	RecurrenceDateTimesPropertyName              = "RDATE"
 	RecurrenceRulePropertyName                   = "RRULE"
+	RecurrenceIdPropertyName                     = "RECURRENCE-ID"
 	LocationPropertyName                         = "LOCATION"
+	FreeBusyPropertyName                         = "FREEBUSY"
+	AttachPropertyName                           = "ATTACH"
 )
 
 type ParameterName string
 
 const (
-	CanonicalNameParameterName  ParameterName = "CN"
-	TimeZoneIdPropertyName                    = "TZID"
-	ValuePropertyName                         = "VALUE"
-	AlternateRepresentationName               = "ALTREP"
+	CanonicalNameParameterName       ParameterName = "CN"
+	TimeZoneIdPropertyName                         = "TZID"
+	ValuePropertyName                              = "VALUE"
+	AlternateRepresentationName                    = "ALTREP"
+	FreeBusyTypeParameterName                      = "FBTYPE"
+	ScheduleAgentParameterName                     = "SCHEDULE-AGENT"
+	ScheduleStatusParameterName                    = "SCHEDULE-STATUS"
+	ScheduleForceSendParameterName                 = "SCHEDULE-FORCE-SEND"
+	ParticipationStatusParameterName               = "PARTSTAT"
+	RoleParameterName                              = "ROLE"
+	RsvpParameterName                              = "RSVP"
+	CalendarUserTypeParameterName                  = "CUTYPE"
+	DelegatedToParameterName                       = "DELEGATED-TO"
+	DelegatedFromParameterName                     = "DELEGATED-FROM"
+	MemberParameterName                            = "MEMBER"
+	SentByParameterName                            = "SENT-BY"
+	DirectoryParameterName                         = "DIR"
+	LanguageParameterName                          = "LANGUAGE"
+	EmailParameterName                             = "EMAIL"
+	EncodingParameterName                          = "ENCODING"
+	FormatTypeParameterName                        = "FMTTYPE"
+	FilenameParameterName                          = "FILENAME"
+	SizeParameterName                              = "SIZE"
+	ManagedIdParameterName                         = "MANAGED-ID"
 )
 
 type Params map[ParameterName]string
@@ -5,6 +5,7 @@ import (
 	"github.com/dolanor/caldav-go/utils"
 	"log"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -94,10 +95,18 @@ func MarshalProperty(p *Property) string {
 	name := strings.ToUpper(propNameSanitizer.Replace(string(p.Name)))
 	value := propValueSanitizer.Replace(p.Value)
 	keys := []string{name}
-	for name, value := range p.Params {
-		name = ParameterName(strings.ToUpper(propNameSanitizer.Replace(string(name))))
-		value = propValueSanitizer.Replace(value)
-		if strings.ContainsAny(value, " :") {
+	// params are written in a stable order, so that the same property always encodes the same way
+	var names []string
+	for name := range p.Params {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, pname := range names {
+		value := p.Params[ParameterName(pname)]
+		name := ParameterName(strings.ToUpper(propNameSanitizer.Replace(pname)))
+		if isQuotedParamValue(value) {
+			keys = append(keys, fmt.Sprintf("%s=%s", name, value))
+		} else if value = propValueSanitizer.Replace(value); strings.ContainsAny(value, " :;,") {
 			keys = append(keys, fmt.Sprintf("%s=\"%s\"", name, value))
 		} else {
 			keys = append(keys, fmt.Sprintf("%s=%s", name, value))
@@ -145,23 +154,22 @@ func PropertyFromInterface(target interface{}) (p *Property, err error) {
 }
 
 func UnmarshalProperty(line string) *Property {
-	nvp := strings.SplitN(line, ":", 2)
 	prop := new(Property)
-	if len(nvp) > 1 {
-		prop.Value = strings.TrimSpace(nvp[1])
+	npp := splitUnquoted(line, ';', ':')
+	if last := npp[len(npp)-1]; len(last) > 0 && last[0] == ':' {
+		prop.Value = strings.TrimSpace(last[1:])
+		npp = npp[:len(npp)-1]
 	}
-	npp := strings.Split(nvp[0], ";")
 	if len(npp) > 1 {
 		prop.Params = make(map[ParameterName]string, 0)
 		for i := 1; i < len(npp); i++ {
 			var key, value string
-			kvp := strings.Split(npp[i], "=")
-			key = strings.TrimSpace(kvp[0])
-			key = propNameDesanitizer.Replace(key)
+			kvp := strings.SplitN(npp[i][1:], "=", 2)
+			// unlike property names, parameter names are not mapped to field names and keep their dashes
+			key = strings.ToUpper(strings.TrimSpace(kvp[0]))
 			if len(kvp) > 1 {
-				value = strings.TrimSpace(kvp[1])
+				value = strings.Join(SplitParamValues(kvp[1]), ",")
 				value = propValueDesanitizer.Replace(value)
-				value = strings.Trim(value, "\"")
 			}
 			prop.Params[ParameterName(key)] = value
 		}
@@ -172,6 +180,64 @@ func UnmarshalProperty(line string) *Property {
 	return prop
 }
 
+// splits the name and params of a content line, keeping the separators in front of each part.
+// separators within quoted param values or escaped by a backslash do not count, and the line ends with the value,
+// following the first unquoted value separator
+func splitUnquoted(line string, separator, valueSeparator byte) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && !quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == separator && !quoted:
+			parts = append(parts, line[start:i])
+			start = i
+		case c == valueSeparator && !quoted:
+			return append(parts, line[start:i], line[i:])
+		}
+	}
+	return append(parts, line[start:])
+}
+
+// splits a param value holding a list of values, each of which may be quoted
+func SplitParamValues(value string) []string {
+	var values []string
+	quoted, current := false, ""
+	for _, r := range value {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ',' && !quoted {
+			values = append(values, strings.TrimSpace(current))
+			current = ""
+		} else {
+			current += string(r)
+		}
+	}
+	return append(values, strings.TrimSpace(current))
+}
+
+// quotes each value of a param holding a list, as required for lists of URIs.
+// params quoted this way are written as they are when marshaling the property
+func QuoteParamValues(values ...string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("\"%s\"", strings.Replace(value, "\"", "'", -1))
+	}
+	return strings.Join(quoted, ",")
+}
+
+// checks if a param value is made of quoted values only
+func isQuotedParamValue(value string) bool {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return false
+	}
+	inner := strings.Replace(value[1:len(value)-1], "\",\"", "", -1)
+	return !strings.Contains(inner, "\"")
+}
+
 func NewProperty(name, value string) *Property {
 	return &Property{Name: PropertyName(name), Value: value}
 }
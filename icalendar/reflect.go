@@ -32,13 +32,18 @@ func isInvalidOrEmptyValue(v reflect.Value) bool {
 	return false
 }
 
+var canDecodeValueType = reflect.TypeOf((*properties.CanDecodeValue)(nil)).Elem()
+
 func newValue(in reflect.Value) (out reflect.Value, isArrayElement bool) {
 
 	typ := in.Type()
 	kind := typ.Kind()
 
 	for {
-		if kind == reflect.Array || kind == reflect.Slice {
+		if kind != reflect.Ptr && reflect.PtrTo(typ).Implements(canDecodeValueType) {
+			// values decoding themselves, such as lists of comma separated values, are not array elements
+			break
+		} else if kind == reflect.Array || kind == reflect.Slice {
 			isArrayElement = true
 		} else if kind != reflect.Ptr {
 			break
@@ -52,6 +57,12 @@ func newValue(in reflect.Value) (out reflect.Value, isArrayElement bool) {
 
 }
 
+// returns a new zero value of the element type of a field, as an interface
+func newValueInterface(v reflect.Value) interface{} {
+	vnew, _ := newValue(v)
+	return vnew.Interface()
+}
+
 func dereferencePointerValue(v reflect.Value) reflect.Value {
 	for (v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) && v.Elem().IsValid() {
 		v = v.Elem()
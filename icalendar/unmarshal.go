@@ -70,10 +70,8 @@ func tokenizeSlice(slice []string, name ...string) (*token, error) {
 					}
 				}
 			}
-		} else if existing, ok := tok.properties[prop.Name]; ok {
-			tok.properties[prop.Name] = []*properties.Property{prop}
 		} else {
-			tok.properties[prop.Name] = append(existing, prop)
+			tok.properties[prop.Name] = append(tok.properties[prop.Name], prop)
 		}
 
 	}
@@ -196,6 +194,9 @@ func hydrateProperty(v reflect.Value, prop *properties.Property) error {
 		// for arrays, append the new value into the array structure
 		if !voldval.CanSet() {
 			return utils.NewError(hydrateProperty, "unable to set array value", v, nil)
+		} else if voldval.Type().Elem().Kind() == reflect.Ptr {
+			// arrays of pointers take the new value as is
+			voldval.Set(reflect.Append(voldval, vnew))
 		} else {
 			voldval.Set(reflect.Append(voldval, vnewval))
 		}
@@ -264,6 +265,13 @@ func hydrateProperties(v reflect.Value, component *token) error {
 
 		vfield := vdref.Field(i)
 
+		// values naming their own property, such as RRULE or EXDATE, are looked up by that name
+		if encoder, ok := newValueInterface(vfield).(properties.CanEncodeName); ok {
+			if name, err := encoder.EncodeICalName(); err == nil && name != "" {
+				prop.Name = properties.PropertyName(strings.Replace(string(name), "-", "_", -1))
+			}
+		}
+
 		// first try to hydrate property values
 		if properties, ok := component.properties[prop.Name]; ok {
 			for _, prop := range properties {
@@ -0,0 +1,159 @@
+package values
+
+import (
+	"encoding/base64"
+	"github.com/dolanor/caldav-go/icalendar/properties"
+	"github.com/dolanor/caldav-go/utils"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// A document object associated with a calendar component, either referenced by a URI or inlined as binary data.
+// Attachments managed by the server, see RFC 8607, carry the identifier, name and size the server gave them.
+type Attachment struct {
+	// the location of the document, nil for inline attachments
+	Uri *url.URL
+	// the content of the document, nil for attachments referenced by a URI
+	Data []byte
+	// the media type of the document, such as application/pdf
+	FormatType string
+	// the suggested name of the document
+	Filename string
+	// the size of the document in octets, zero if unknown
+	Size int64
+	// the identifier of the attachment, when managed by the server
+	ManagedId string
+	// the other parameters of the property, kept as they were decoded
+	ExtraParams properties.Params
+	// the value as it was decoded, until the params tell how to read it
+	raw string
+}
+
+// creates a new attachment referencing a document by URI
+func NewUriAttachment(u url.URL, formatType ...string) *Attachment {
+	a := &Attachment{Uri: &u}
+	if len(formatType) > 0 {
+		a.FormatType = formatType[0]
+	}
+	return a
+}
+
+// creates a new attachment inlining a document
+func NewBinaryAttachment(data []byte, formatType string, filename ...string) *Attachment {
+	a := &Attachment{Data: data, FormatType: formatType}
+	if len(filename) > 0 {
+		a.Filename = filename[0]
+	}
+	return a
+}
+
+// checks if the document is inlined in the attachment
+func (a *Attachment) IsBinary() bool {
+	return a.Uri == nil && a.Data != nil
+}
+
+// checks if the attachment is managed by the server
+func (a *Attachment) IsManaged() bool {
+	return a.ManagedId != ""
+}
+
+// encodes the attachment property name for the iCalendar specification
+func (a *Attachment) EncodeICalName() (properties.PropertyName, error) {
+	return properties.AttachPropertyName, nil
+}
+
+// encodes the attachment value for the iCalendar specification
+func (a *Attachment) EncodeICalValue() (string, error) {
+	if a.IsBinary() {
+		return base64.StdEncoding.EncodeToString(a.Data), nil
+	} else if a.Uri != nil {
+		return a.Uri.String(), nil
+	}
+	return "", nil
+}
+
+// encodes the attachment params for the iCalendar specification
+func (a *Attachment) EncodeICalParams() (params properties.Params, err error) {
+	params = make(properties.Params)
+	for name, value := range a.ExtraParams {
+		params[name] = value
+	}
+	if a.IsBinary() {
+		params[properties.EncodingParameterName] = "BASE64"
+		params[properties.ValuePropertyName] = "BINARY"
+	} else {
+		params[properties.ValuePropertyName] = "URI"
+	}
+	if a.FormatType != "" {
+		params[properties.FormatTypeParameterName] = a.FormatType
+	}
+	if a.Filename != "" {
+		params[properties.FilenameParameterName] = a.Filename
+	}
+	if a.Size > 0 {
+		params[properties.SizeParameterName] = strconv.FormatInt(a.Size, 10)
+	}
+	if a.ManagedId != "" {
+		params[properties.ManagedIdParameterName] = a.ManagedId
+	}
+	return
+}
+
+// decodes the attachment value from the iCalendar specification, as a URI unless the params say otherwise
+func (a *Attachment) DecodeICalValue(value string) error {
+	a.raw = value
+	if u, err := url.Parse(value); err != nil {
+		return utils.NewError(a.DecodeICalValue, "unable to parse attachment uri", a, err)
+	} else {
+		a.Uri = u
+		return nil
+	}
+}
+
+// decodes the attachment params from the iCalendar specification
+func (a *Attachment) DecodeICalParams(params properties.Params) error {
+	for name, value := range params {
+		switch name {
+		case properties.EncodingParameterName, properties.ValuePropertyName:
+			// tells how to read the value, see below
+		case properties.FormatTypeParameterName:
+			a.FormatType = value
+		case properties.FilenameParameterName:
+			a.Filename = value
+		case properties.SizeParameterName:
+			if size, err := strconv.ParseInt(value, 10, 64); err != nil {
+				return utils.NewError(a.DecodeICalParams, "unable to parse attachment size", a, err)
+			} else {
+				a.Size = size
+			}
+		case properties.ManagedIdParameterName:
+			a.ManagedId = value
+		default:
+			if a.ExtraParams == nil {
+				a.ExtraParams = make(properties.Params)
+			}
+			a.ExtraParams[name] = value
+		}
+	}
+	if strings.EqualFold(params[properties.EncodingParameterName], "BASE64") || strings.EqualFold(params[properties.ValuePropertyName], "BINARY") {
+		if data, err := base64.StdEncoding.DecodeString(a.raw); err != nil {
+			return utils.NewError(a.DecodeICalParams, "unable to decode binary attachment", a, err)
+		} else {
+			a.Uri, a.Data = nil, data
+		}
+	}
+	return nil
+}
+
+// validates the attachment against the iCalendar specification
+func (a *Attachment) ValidateICalValue() error {
+	if a.Uri == nil && a.Data == nil {
+		return utils.NewError(a.ValidateICalValue, "attachment must have either a uri or binary data", a, nil)
+	} else if a.Uri != nil && a.Data != nil {
+		return utils.NewError(a.ValidateICalValue, "attachment cannot have both a uri and binary data", a, nil)
+	} else if a.Size < 0 {
+		return utils.NewError(a.ValidateICalValue, "attachment size cannot be negative", a, nil)
+	}
+	return nil
+}
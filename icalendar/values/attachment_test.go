@@ -0,0 +1,74 @@
+package values
+
+import (
+	"github.com/dolanor/caldav-go/icalendar"
+	. "gopkg.in/check.v1"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+type AttachmentSuite struct{}
+
+var _ = Suite(new(AttachmentSuite))
+
+func TestAttachment(t *testing.T) { TestingT(t) }
+
+func (s *AttachmentSuite) TestBinary(c *C) {
+
+	a := NewBinaryAttachment([]byte("hello, world"), "text/plain", "hello world.txt")
+	enc, err := icalendar.Marshal(a)
+	c.Assert(err, IsNil)
+	c.Assert(enc, Equals, "ATTACH;ENCODING=BASE64;FILENAME=\"hello world.txt\";FMTTYPE=text/plain;VALUE=\r\n BINARY:aGVsbG8sIHdvcmxk")
+
+	after := new(Attachment)
+	c.Assert(icalendar.Unmarshal(enc, after), IsNil)
+	c.Assert(after.IsBinary(), Equals, true)
+	c.Assert(string(after.Data), Equals, "hello, world")
+	c.Assert(after.FormatType, Equals, "text/plain")
+	c.Assert(after.Filename, Equals, "hello world.txt")
+
+	// inline data is folded into content lines of at most 75 octets
+	data := []byte(strings.Repeat("hello, world\n", 100))
+	enc, err = icalendar.Marshal(NewBinaryAttachment(data, "text/plain", "ünïcödé.txt"))
+	c.Assert(err, IsNil)
+	lines := strings.Split(enc, icalendar.Newline)
+	c.Assert(len(lines) > 20, Equals, true)
+	for i, line := range lines {
+		c.Assert(len(line) <= icalendar.MaxLineOctets, Equals, true)
+		c.Assert(utf8.ValidString(line), Equals, true)
+		if i > 0 {
+			c.Assert(line, Matches, " .*")
+		}
+	}
+	after = new(Attachment)
+	c.Assert(icalendar.Unmarshal(enc, after), IsNil)
+	c.Assert(after.Data, DeepEquals, data)
+	c.Assert(after.Filename, Equals, "ünïcödé.txt")
+
+}
+
+func (s *AttachmentSuite) TestManaged(c *C) {
+
+	// the example of RFC 8607 section 3.4.1
+	enc := "ATTACH;MANAGED-ID=97S;FMTTYPE=text/plain;FILENAME=agenda.txt;SIZE=59:https://files.example.com/abcd1234.txt"
+	a := new(Attachment)
+	c.Assert(icalendar.Unmarshal(enc, a), IsNil)
+	c.Assert(a.IsBinary(), Equals, false)
+	c.Assert(a.IsManaged(), Equals, true)
+	c.Assert(a.Uri.Host, Equals, "files.example.com")
+	c.Assert(a.ManagedId, Equals, "97S")
+	c.Assert(a.Size, Equals, int64(59))
+
+	reenc, err := icalendar.Marshal(a)
+	c.Assert(err, IsNil)
+	c.Assert(reenc, Equals, "ATTACH;FILENAME=agenda.txt;FMTTYPE=text/plain;MANAGED-ID=97S;SIZE=59;VALUE=\r\n URI:https://files.example.com/abcd1234.txt")
+
+	u, _ := url.Parse("https://example.com/a.pdf")
+	invalid := NewUriAttachment(*u)
+	invalid.Data = []byte("a")
+	_, err = icalendar.Marshal(invalid)
+	c.Assert(err, NotNil)
+
+}
@@ -0,0 +1,24 @@
+package values
+
+// Specifies the participation role of a calendar user in a group scheduled calendar component, as carried by the
+// ROLE parameter of the "ATTENDEE" property.
+type Role string
+
+const (
+	ChairRole               Role = "CHAIR"           // Indicates chair of the calendar entity.
+	RequiredParticipantRole      = "REQ-PARTICIPANT" // Indicates a participant whose participation is required. DEFAULT
+	OptionalParticipantRole      = "OPT-PARTICIPANT" // Indicates a participant whose participation is optional.
+	NonParticipantRole           = "NON-PARTICIPANT" // Indicates a participant who is copied for information purposes only.
+)
+
+// Identifies the type of a calendar user, as carried by the CUTYPE parameter of the "ATTENDEE" and "ORGANIZER"
+// properties.
+type CalendarUserType string
+
+const (
+	IndividualCalendarUserType CalendarUserType = "INDIVIDUAL" // An individual. DEFAULT
+	GroupCalendarUserType                       = "GROUP"      // A group of individuals.
+	ResourceCalendarUserType                    = "RESOURCE"   // A physical resource.
+	RoomCalendarUserType                        = "ROOM"       // A room resource.
+	UnknownCalendarUserType                     = "UNKNOWN"    // Otherwise not known.
+)
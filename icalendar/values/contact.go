@@ -6,6 +6,7 @@ import (
 	"github.com/dolanor/caldav-go/utils"
 	"log"
 	"net/mail"
+	"net/url"
 	"strings"
 )
 
@@ -21,24 +22,79 @@ var _ = log.Print
 // parameters may also be specified on this property. If the LANGUAGE property parameter is specified, the identified
 // language applies to the CN parameter value.
 type Contact struct {
+	// the common name and the calendar user address of the contact. the address is an email address for mailto:
+	// calendar user addresses, and the full URI for any other scheme, such as urn:uuid:
 	Entry mail.Address
+	// the participation role of an attendee
+	Role Role
+	// the participation status of an attendee, as set in replies
+	ParticipationStatus ParticipationStatus
+	// whether the organizer expects a reply from an attendee
+	Rsvp bool
+	// the type of calendar user, such as an individual or a room
+	CalendarUserType CalendarUserType
+	// the calendar users an attendee delegated its participation to
+	DelegatedTo []string
+	// the calendar users that delegated their participation to an attendee
+	DelegatedFrom []string
+	// the groups or list memberships of the calendar user
+	Member []string
+	// the calendar user acting on behalf of the contact
+	SentBy string
+	// a reference to the directory entry of the contact
+	Directory string
+	// the language of the common name
+	Language string
+	// the email address of the contact, when the calendar user address is not a mailto: URI, see RFC 7986
+	Email string
+	// the calendar user agent responsible for delivering the scheduling messages of the contact, see RFC 6638
+	ScheduleAgent ScheduleAgent
+	// the status codes of the last scheduling messages delivered to the contact, as reported by the server
+	ScheduleStatus ScheduleStatus
+	// asks the server to deliver a scheduling message to the contact even if it would not have otherwise
+	ScheduleForceSend ScheduleForceSend
+	// the other parameters of the property, such as X- parameters, kept as they were decoded
+	ExtraParams properties.Params
 }
 
 type AttendeeContact Contact
 type OrganizerContact Contact
 
 // creates a new icalendar attendee representation
-func NewAttendeeContact(name, email string) *AttendeeContact {
-	return &AttendeeContact{Entry: mail.Address{Name: name, Address: email}}
+// the address may either be an email address or a calendar user address URI
+func NewAttendeeContact(name, address string) *AttendeeContact {
+	return &AttendeeContact{Entry: mail.Address{Name: name, Address: address}}
 }
 
 // creates a new icalendar organizer representation
-func NewOrganizerContact(name, email string) *OrganizerContact {
-	return &OrganizerContact{Entry: mail.Address{Name: name, Address: email}}
+// the address may either be an email address or a calendar user address URI
+func NewOrganizerContact(name, address string) *OrganizerContact {
+	return &OrganizerContact{Entry: mail.Address{Name: name, Address: address}}
+}
+
+// checks if the calendar user address is an email address, that is a mailto: URI
+func (c *Contact) IsEmail() bool {
+	return !hasUriScheme(c.Entry.Address)
+}
+
+// returns the calendar user address as a URI, such as mailto:jane@example.com
+func (c *Contact) Uri() string {
+	if c.IsEmail() {
+		return fmt.Sprintf("mailto:%s", c.Entry.Address)
+	} else {
+		return c.Entry.Address
+	}
 }
 
 // validates the contact value for the iCalendar specification
 func (c *Contact) ValidateICalValue() error {
+	if !c.IsEmail() {
+		if _, err := url.Parse(c.Entry.Address); err != nil {
+			msg := fmt.Sprintf("unable to validate address %s", c.Entry.Address)
+			return utils.NewError(c.ValidateICalValue, msg, c, err)
+		}
+		return nil
+	}
 	email := c.Entry.String()
 	if _, err := mail.ParseAddress(email); err != nil {
 		msg := fmt.Sprintf("unable to validate address %s", email)
@@ -50,34 +106,125 @@ func (c *Contact) ValidateICalValue() error {
 
 // encodes the contact value for the iCalendar specification
 func (c *Contact) EncodeICalValue() (string, error) {
-	return fmt.Sprintf("MAILTO:%s", c.Entry.Address), nil
+	if c.IsEmail() {
+		return fmt.Sprintf("MAILTO:%s", c.Entry.Address), nil
+	} else {
+		return c.Entry.Address, nil
+	}
 }
 
 // encodes the contact params for the iCalendar specification
 func (c *Contact) EncodeICalParams() (params properties.Params, err error) {
-	if c.Entry.Name != "" {
-		params = properties.Params{properties.CanonicalNameParameterName: c.Entry.Name}
+	params = make(properties.Params)
+	for name, value := range c.ExtraParams {
+		params[name] = value
+	}
+	set := func(name properties.ParameterName, value string) {
+		if value != "" {
+			params[name] = value
+		}
+	}
+	list := func(name properties.ParameterName, values []string) {
+		if len(values) > 0 {
+			params[name] = properties.QuoteParamValues(values...)
+		}
+	}
+	set(properties.CanonicalNameParameterName, c.Entry.Name)
+	set(properties.RoleParameterName, string(c.Role))
+	set(properties.ParticipationStatusParameterName, string(c.ParticipationStatus))
+	if c.Rsvp {
+		params[properties.RsvpParameterName] = "TRUE"
+	}
+	set(properties.CalendarUserTypeParameterName, string(c.CalendarUserType))
+	list(properties.DelegatedToParameterName, c.DelegatedTo)
+	list(properties.DelegatedFromParameterName, c.DelegatedFrom)
+	list(properties.MemberParameterName, c.Member)
+	if c.SentBy != "" {
+		params[properties.SentByParameterName] = properties.QuoteParamValues(c.SentBy)
+	}
+	if c.Directory != "" {
+		params[properties.DirectoryParameterName] = properties.QuoteParamValues(c.Directory)
+	}
+	set(properties.LanguageParameterName, c.Language)
+	set(properties.EmailParameterName, c.Email)
+	set(properties.ScheduleAgentParameterName, string(c.ScheduleAgent))
+	list(properties.ScheduleStatusParameterName, c.ScheduleStatus)
+	set(properties.ScheduleForceSendParameterName, string(c.ScheduleForceSend))
+	if len(params) == 0 {
+		params = nil
 	}
 	return
 }
 
 // decodes the contact value from the iCalendar specification
 func (c *Contact) DecodeICalValue(value string) error {
-	parts := strings.SplitN(value, ":", 2)
-	if len(parts) > 1 {
+	if parts := strings.SplitN(value, ":", 2); len(parts) > 1 && strings.EqualFold(parts[0], "mailto") {
 		c.Entry.Address = parts[1]
+	} else {
+		c.Entry.Address = value
 	}
 	return nil
 }
 
 // decodes the contact params from the iCalendar specification
 func (c *Contact) DecodeICalParams(params properties.Params) error {
-	if name, found := params[properties.CanonicalNameParameterName]; found {
-		c.Entry.Name = name
+	for name, value := range params {
+		switch name {
+		case properties.CanonicalNameParameterName:
+			c.Entry.Name = value
+		case properties.RoleParameterName:
+			c.Role = Role(strings.ToUpper(value))
+		case properties.ParticipationStatusParameterName:
+			c.ParticipationStatus = ParticipationStatus(strings.ToUpper(value))
+		case properties.RsvpParameterName:
+			c.Rsvp = strings.EqualFold(value, "TRUE")
+		case properties.CalendarUserTypeParameterName:
+			c.CalendarUserType = CalendarUserType(strings.ToUpper(value))
+		case properties.DelegatedToParameterName:
+			c.DelegatedTo = properties.SplitParamValues(value)
+		case properties.DelegatedFromParameterName:
+			c.DelegatedFrom = properties.SplitParamValues(value)
+		case properties.MemberParameterName:
+			c.Member = properties.SplitParamValues(value)
+		case properties.SentByParameterName:
+			c.SentBy = value
+		case properties.DirectoryParameterName:
+			c.Directory = value
+		case properties.LanguageParameterName:
+			c.Language = value
+		case properties.EmailParameterName:
+			c.Email = value
+		case properties.ScheduleAgentParameterName:
+			c.ScheduleAgent = ScheduleAgent(strings.ToUpper(value))
+		case properties.ScheduleStatusParameterName:
+			c.ScheduleStatus = NewScheduleStatus(value)
+		case properties.ScheduleForceSendParameterName:
+			c.ScheduleForceSend = ScheduleForceSend(strings.ToUpper(value))
+		default:
+			if c.ExtraParams == nil {
+				c.ExtraParams = make(properties.Params)
+			}
+			c.ExtraParams[name] = value
+		}
 	}
 	return nil
 }
 
+// checks if an address starts with a URI scheme, such as urn: or http:
+func hasUriScheme(address string) bool {
+	i := strings.Index(address, ":")
+	if i <= 0 || strings.Contains(address[:i], "@") {
+		return false
+	}
+	for j, r := range address[:i] {
+		letter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !letter && (j == 0 || !strings.ContainsRune("0123456789+-.", r)) {
+			return false
+		}
+	}
+	return true
+}
+
 // validates the contact value for the iCalendar specification
 func (c *OrganizerContact) ValidateICalValue() error {
 	return (*Contact)(c).ValidateICalValue()
@@ -39,3 +39,73 @@ func (s *ContactSuite) TestItentity(c *C) {
 	c.Assert(after, DeepEquals, before)
 
 }
+
+func (s *ContactSuite) TestScheduleParams(c *C) {
+
+	a := NewAttendeeContact("", "foo@bar.com")
+	a.ScheduleAgent = ClientScheduleAgent
+	a.ScheduleForceSend = RequestScheduleForceSend
+	enc, err := icalendar.Marshal(a)
+	c.Assert(err, IsNil)
+	c.Assert(enc, Equals, "ATTENDEE;SCHEDULE-AGENT=CLIENT;SCHEDULE-FORCE-SEND=REQUEST:MAILTO:foo@bar.c\r\n om")
+
+	after := new(AttendeeContact)
+	err = icalendar.Unmarshal("ATTENDEE;CN=Foo;SCHEDULE-STATUS=\"1.2,3.7\":mailto:foo@bar.com", after)
+	c.Assert(err, IsNil)
+	c.Assert(after.Entry.Name, Equals, "Foo")
+	c.Assert(after.ScheduleStatus, DeepEquals, ScheduleStatus{"1.2", "3.7"})
+	c.Assert(after.ScheduleStatus.Delivered(), Equals, false)
+	c.Assert(NewScheduleStatus("2.0").Delivered(), Equals, true)
+
+}
+
+func (s *ContactSuite) TestParams(c *C) {
+
+	raw := "ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=DELEGATED;RSVP=TRUE;CUTYPE=GROUP;" +
+		"DELEGATED-TO=\"mailto:jdoe@example.com\",\"mailto:jqpublic@example.com\";" +
+		"MEMBER=\"mailto:ietf-calsch@example.org\";SENT-BY=\"mailto:sray@example.com\";" +
+		"DIR=\"ldap://example.com:6666/o=ABC%20Industries,c=US???(cn=Jim%20Dolittle)\";" +
+		"LANGUAGE=de-ch;CN=\"Doe, John\";X-NUM-GUESTS=2:mailto:jsmith@example.com"
+
+	a := new(AttendeeContact)
+	c.Assert(icalendar.Unmarshal(raw, a), IsNil)
+	c.Assert(a.Entry.Name, Equals, "Doe, John")
+	c.Assert(a.Entry.Address, Equals, "jsmith@example.com")
+	c.Assert(a.Role, Equals, Role(RequiredParticipantRole))
+	c.Assert(a.ParticipationStatus, Equals, ParticipationStatus(DelegatedParticipationStatus))
+	c.Assert(a.Rsvp, Equals, true)
+	c.Assert(a.CalendarUserType, Equals, CalendarUserType(GroupCalendarUserType))
+	c.Assert(a.DelegatedTo, DeepEquals, []string{"mailto:jdoe@example.com", "mailto:jqpublic@example.com"})
+	c.Assert(a.Member, DeepEquals, []string{"mailto:ietf-calsch@example.org"})
+	c.Assert(a.SentBy, Equals, "mailto:sray@example.com")
+	c.Assert(a.Directory, Equals, "ldap://example.com:6666/o=ABC%20Industries,c=US???(cn=Jim%20Dolittle)")
+	c.Assert(a.Language, Equals, "de-ch")
+	c.Assert(a.ExtraParams["X-NUM-GUESTS"], Equals, "2")
+
+	enc, err := icalendar.Marshal(a)
+	c.Assert(err, IsNil)
+	c.Assert(enc, Matches, "(?s).*;DELEGATED-TO=\"mailto:jdoe@example.com\"\r\n ,\"mailto:jqpublic@example.com\";.*")
+	c.Assert(enc, Matches, "(?s).*;X-NUM-GUESTS=2:MAILTO:jsmith@example.com")
+
+	after := new(AttendeeContact)
+	c.Assert(icalendar.Unmarshal(enc, after), IsNil)
+	c.Assert(after, DeepEquals, a)
+
+}
+
+func (s *ContactSuite) TestUriAddress(c *C) {
+
+	o := NewOrganizerContact("Room 1", "urn:uuid:a6fb3e6c-0b3b-4a68-a8d4-9a0e1f8f3c1a")
+	o.Email = "room1@example.com"
+	c.Assert(o.ValidateICalValue(), IsNil)
+	enc, err := icalendar.Marshal(o)
+	c.Assert(err, IsNil)
+	c.Assert(enc, Equals, "ORGANIZER;CN=\"Room 1\";EMAIL=room1@example.com:urn:uuid:a6fb3e6c-0b3b-4a68-a\r\n 8d4-9a0e1f8f3c1a")
+
+	after := new(OrganizerContact)
+	c.Assert(icalendar.Unmarshal(enc, after), IsNil)
+	c.Assert(after, DeepEquals, o)
+	c.Assert((*Contact)(after).IsEmail(), Equals, false)
+	c.Assert((*Contact)(NewAttendeeContact("", "foo@bar.com")).Uri(), Equals, "mailto:foo@bar.com")
+
+}
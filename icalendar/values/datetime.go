@@ -18,6 +18,10 @@ const UTCDateTimeFormatString = "20060102T150405Z"
 // a representation of a date and time for iCalendar
 type DateTime struct {
 	t time.Time
+	// the value is a date without a time, such as the start of an all-day event
+	date bool
+	// the value is a local time that is not bound to any timezone
+	floating bool
 }
 
 type DateTimes []*DateTime
@@ -57,6 +61,11 @@ func NewDateTime(t time.Time) *DateTime {
 	return &DateTime{t: t.Truncate(time.Second)}
 }
 
+// creates a new icalendar date representation, keeping the year, month and day of the time
+func NewDate(t time.Time) *DateTime {
+	return &DateTime{t: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), date: true}
+}
+
 // creates a new icalendar datetime array representation
 func NewDateTimes(dates ...*DateTime) DateTimes {
 	return DateTimes(dates)
@@ -80,15 +89,38 @@ func (d *DateTime) Equals(test *DateTime) bool {
 }
 
 // returns the native time for the datetime object
+// dates and floating times are returned as if they were in UTC, see In
 func (d *DateTime) NativeTime() time.Time {
 	return d.t
 }
 
+// checks if the value is a date without a time
+func (d *DateTime) IsDate() bool {
+	return d.date
+}
+
+// checks if the value is a local time that is not bound to any timezone
+func (d *DateTime) IsFloating() bool {
+	return d.floating
+}
+
+// returns the native time, interpreting dates and floating times as local times of a location
+func (d *DateTime) In(loc *time.Location) time.Time {
+	if d.date || d.floating {
+		return time.Date(d.t.Year(), d.t.Month(), d.t.Day(), d.t.Hour(), d.t.Minute(), d.t.Second(), 0, loc)
+	} else {
+		return d.t
+	}
+}
+
 // encodes the datetime value for the iCalendar specification
 func (d *DateTime) EncodeICalValue() (string, error) {
+	if d.date {
+		return d.t.Format(DateFormatString), nil
+	}
 	val := d.t.Format(DateTimeFormatString)
 	loc := d.t.Location()
-	if loc == time.UTC {
+	if loc == time.UTC && !d.floating {
 		val = fmt.Sprintf("%sZ", val)
 	}
 	return val, nil
@@ -103,6 +135,8 @@ func (d *DateTime) DecodeICalValue(value string) error {
 		layout = DateFormatString
 	}
 	var err error
+	d.date = layout == DateFormatString
+	d.floating = layout == DateTimeFormatString
 	d.t, err = time.ParseInLocation(layout, value, time.UTC)
 	if err != nil {
 		return utils.NewError(d.DecodeICalValue, "unable to parse datetime value", d, err)
@@ -114,7 +148,9 @@ func (d *DateTime) DecodeICalValue(value string) error {
 // encodes the datetime params for the iCalendar specification
 func (d *DateTime) EncodeICalParams() (params properties.Params, err error) {
 	loc := d.t.Location()
-	if loc != time.UTC {
+	if d.date {
+		params = properties.Params{properties.ValuePropertyName: "DATE"}
+	} else if loc != time.UTC {
 		params = properties.Params{properties.TimeZoneIdPropertyName: loc.String()}
 	}
 	return
@@ -124,14 +160,14 @@ func (d *DateTime) EncodeICalParams() (params properties.Params, err error) {
 func (d *DateTime) DecodeICalParams(params properties.Params) error {
 	layout := DateTimeFormatString
 	value := d.t.Format(layout)
-	if name, found := params[properties.TimeZoneIdPropertyName]; !found {
+	if name, found := params[properties.TimeZoneIdPropertyName]; !found || d.date {
 		return nil
 	} else if loc, err := time.LoadLocation(name); err != nil {
 		return utils.NewError(d.DecodeICalValue, "unable to parse timezone", d, err)
 	} else if t, err := time.ParseInLocation(layout, value, loc); err != nil {
 		return utils.NewError(d.DecodeICalValue, "unable to parse datetime value", d, err)
 	} else {
-		d.t = t
+		d.t, d.floating = t, false
 		return nil
 	}
 }
@@ -0,0 +1,102 @@
+package values
+
+import (
+	"fmt"
+	"github.com/dolanor/caldav-go/icalendar/properties"
+	"github.com/dolanor/caldav-go/utils"
+	"strings"
+)
+
+// The free/busy type describes whether the periods of a "FREEBUSY" property are free or busy, and why they are busy.
+type FreeBusyType string
+
+const (
+	FreeFreeBusyType            FreeBusyType = "FREE"             // The time interval is free for scheduling.
+	BusyFreeBusyType                         = "BUSY"             // The time interval is busy because one or more events have been scheduled. DEFAULT
+	BusyUnavailableFreeBusyType              = "BUSY-UNAVAILABLE" // The time interval is busy and that the interval can not be scheduled.
+	BusyTentativeFreeBusyType                = "BUSY-TENTATIVE"   // The time interval is busy because one or more events have been tentatively scheduled.
+)
+
+// defines one or more free or busy time intervals of a "VFREEBUSY" calendar component
+type FreeBusyTime struct {
+	Type    FreeBusyType
+	Periods []*Period
+}
+
+// creates a new icalendar free/busy time representation
+func NewFreeBusyTime(fbtype FreeBusyType, periods ...*Period) *FreeBusyTime {
+	return &FreeBusyTime{Type: fbtype, Periods: periods}
+}
+
+// checks if the intervals are busy, whether tentatively, firmly or because they cannot be scheduled
+func (f *FreeBusyTime) IsBusy() bool {
+	return f.Type != FreeFreeBusyType
+}
+
+// encodes the free/busy property name for the iCalendar specification
+func (f *FreeBusyTime) EncodeICalName() (properties.PropertyName, error) {
+	return properties.FreeBusyPropertyName, nil
+}
+
+// encodes the free/busy value for the iCalendar specification
+func (f *FreeBusyTime) EncodeICalValue() (string, error) {
+	var csv CSV
+	for i, p := range f.Periods {
+		if s, err := p.EncodeICalValue(); err != nil {
+			msg := fmt.Sprintf("unable to encode period at index %d", i)
+			return "", utils.NewError(f.EncodeICalValue, msg, f, err)
+		} else {
+			csv = append(csv, s)
+		}
+	}
+	return csv.EncodeICalValue()
+}
+
+// encodes the free/busy params for the iCalendar specification
+func (f *FreeBusyTime) EncodeICalParams() (params properties.Params, err error) {
+	if f.Type != "" && f.Type != BusyFreeBusyType {
+		params = properties.Params{properties.FreeBusyTypeParameterName: string(f.Type)}
+	}
+	return
+}
+
+// decodes the free/busy value from the iCalendar specification
+func (f *FreeBusyTime) DecodeICalValue(value string) error {
+	csv := new(CSV)
+	if err := csv.DecodeICalValue(value); err != nil {
+		return utils.NewError(f.DecodeICalValue, "unable to decode periods as CSV", f, err)
+	}
+	f.Type = BusyFreeBusyType
+	for i, value := range *csv {
+		p := new(Period)
+		if err := p.DecodeICalValue(value); err != nil {
+			msg := fmt.Sprintf("unable to decode period at index %d", i)
+			return utils.NewError(f.DecodeICalValue, msg, f, err)
+		} else {
+			f.Periods = append(f.Periods, p)
+		}
+	}
+	return nil
+}
+
+// decodes the free/busy params from the iCalendar specification
+func (f *FreeBusyTime) DecodeICalParams(params properties.Params) error {
+	if fbtype, found := params[properties.FreeBusyTypeParameterName]; found {
+		f.Type = FreeBusyType(strings.ToUpper(fbtype))
+	}
+	return nil
+}
+
+// validates the free/busy value against the iCalendar specification
+func (f *FreeBusyTime) ValidateICalValue() error {
+	if len(f.Periods) == 0 {
+		return utils.NewError(f.ValidateICalValue, "free/busy time must hold at least one period", f, nil)
+	}
+	for i, p := range f.Periods {
+		if err := p.ValidateICalValue(); err != nil {
+			msg := fmt.Sprintf("period %d failed validation", i)
+			return utils.NewError(f.ValidateICalValue, msg, f, err)
+		}
+	}
+	return nil
+}
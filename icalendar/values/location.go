@@ -76,3 +76,8 @@ func (l *Location) ValidateICalValue() error {
 
 	return nil
 }
+
+// returns the name of the location
+func (l *Location) String() string {
+	return l.value
+}
@@ -2,6 +2,14 @@ package values
 
 type Method string
 
+// the methods of the scheduling messages of iTIP, see RFC 5546 section 1.4
 const (
-	PublishMethod Method = "PUBLISH"
+	PublishMethod        Method = "PUBLISH"
+	RequestMethod        Method = "REQUEST"
+	ReplyMethod          Method = "REPLY"
+	AddMethod            Method = "ADD"
+	CancelMethod         Method = "CANCEL"
+	RefreshMethod        Method = "REFRESH"
+	CounterMethod        Method = "COUNTER"
+	DeclineCounterMethod Method = "DECLINECOUNTER"
 )
@@ -0,0 +1,15 @@
+package values
+
+// Identifies the participation status of a calendar user in a group scheduled calendar component, as carried by the
+// PARTSTAT parameter of the "ATTENDEE" property. Replies from attendees are expected to set it.
+type ParticipationStatus string
+
+const (
+	NeedsActionParticipationStatus ParticipationStatus = "NEEDS-ACTION" // Event needs action. DEFAULT
+	AcceptedParticipationStatus                        = "ACCEPTED"     // Event accepted.
+	DeclinedParticipationStatus                        = "DECLINED"     // Event declined.
+	TentativeParticipationStatus                       = "TENTATIVE"    // Event tentatively accepted.
+	DelegatedParticipationStatus                       = "DELEGATED"    // Event delegated.
+	CompletedParticipationStatus                       = "COMPLETED"    // To-do completed.
+	InProcessParticipationStatus                       = "IN-PROCESS"   // To-do in process of being completed.
+)
@@ -0,0 +1,130 @@
+package values
+
+import (
+	"fmt"
+	"github.com/dolanor/caldav-go/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+// a precise period of time, given either by its start and end or by its start and duration
+type Period struct {
+	start    *DateTime
+	end      *DateTime
+	duration *Duration
+}
+
+// creates a new iCalendar period representation with an explicit start and end
+func NewPeriod(start, end time.Time) *Period {
+	return &Period{start: NewDateTime(start), end: NewDateTime(end)}
+}
+
+// creates a new iCalendar period representation that lasts a certain duration
+func NewPeriodWithDuration(start time.Time, duration time.Duration) *Period {
+	return &Period{start: NewDateTime(start), duration: NewDuration(duration)}
+}
+
+// returns the time the period starts at
+func (p *Period) Start() time.Time {
+	return p.start.NativeTime()
+}
+
+// returns the time the period ends at, computed from the duration if the period has no explicit end
+func (p *Period) End() time.Time {
+	if p.end != nil {
+		return p.end.NativeTime()
+	} else {
+		return p.Start().Add(p.duration.NativeDuration())
+	}
+}
+
+// returns the length of the period
+func (p *Period) Duration() time.Duration {
+	return p.End().Sub(p.Start())
+}
+
+// checks if two periods share some time
+func (p *Period) Overlaps(test *Period) bool {
+	return p.Start().Before(test.End()) && test.Start().Before(p.End())
+}
+
+// encodes the period value for the iCalendar specification
+func (p *Period) EncodeICalValue() (string, error) {
+	if start, err := p.start.EncodeICalValue(); err != nil {
+		return "", utils.NewError(p.EncodeICalValue, "unable to encode period start", p, err)
+	} else if p.end != nil {
+		end, err := p.end.EncodeICalValue()
+		return fmt.Sprintf("%s/%s", start, end), err
+	} else {
+		duration, err := p.duration.EncodeICalValue()
+		return fmt.Sprintf("%s/%s", start, duration), err
+	}
+}
+
+// decodes the period value from the iCalendar specification
+func (p *Period) DecodeICalValue(value string) error {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return utils.NewError(p.DecodeICalValue, "period must hold a start and an end or duration", p, nil)
+	}
+	p.start, p.end, p.duration = new(DateTime), nil, nil
+	if err := p.start.DecodeICalValue(parts[0]); err != nil {
+		return utils.NewError(p.DecodeICalValue, "unable to decode period start", p, err)
+	} else if strings.HasPrefix(parts[1], "P") || strings.HasPrefix(parts[1], "+P") {
+		p.duration = new(Duration)
+		if err := p.duration.DecodeICalValue(parts[1]); err != nil {
+			return utils.NewError(p.DecodeICalValue, "unable to decode period duration", p, err)
+		}
+	} else {
+		p.end = new(DateTime)
+		if err := p.end.DecodeICalValue(parts[1]); err != nil {
+			return utils.NewError(p.DecodeICalValue, "unable to decode period end", p, err)
+		}
+	}
+	return nil
+}
+
+// validates the period value against the iCalendar specification
+func (p *Period) ValidateICalValue() error {
+	if p.start == nil || (p.end == nil && p.duration == nil) {
+		return utils.NewError(p.ValidateICalValue, "period must have a start and an end or duration", p, nil)
+	} else if !p.End().After(p.Start()) {
+		return utils.NewError(p.ValidateICalValue, "period must end after it starts", p, nil)
+	} else {
+		return nil
+	}
+}
+
+// encodes the period value for the iCalendar specification
+func (p *Period) String() string {
+	if s, err := p.EncodeICalValue(); err != nil {
+		panic(err)
+	} else {
+		return s
+	}
+}
+
+// combines overlapping and adjacent periods, returning the union of the periods sorted by start
+func MergePeriods(periods ...*Period) []*Period {
+	sorted := make([]*Period, 0, len(periods))
+	for _, p := range periods {
+		if p != nil && p.End().After(p.Start()) {
+			sorted = append(sorted, p)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start().Before(sorted[j].Start())
+	})
+	var merged []*Period
+	for _, p := range sorted {
+		if n := len(merged); n > 0 && !p.Start().After(merged[n-1].End()) {
+			if p.End().After(merged[n-1].End()) {
+				merged[n-1] = NewPeriod(merged[n-1].Start(), p.End())
+			}
+		} else {
+			merged = append(merged, NewPeriod(p.Start(), p.End()))
+		}
+	}
+	return merged
+}
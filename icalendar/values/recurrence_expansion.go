@@ -0,0 +1,409 @@
+package values
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the maximum number of consecutive periods of a rule that are scanned without finding an instance, so that rules
+// which never produce an instance, such as the 30th of February, do not loop forever
+const maxRecurrencePeriods = 100000
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var ordinalDayRegExp = regexp.MustCompile("^([+-]?\\d{1,2})?(MO|TU|WE|TH|FR|SA|SU)$")
+
+// returns the weekday and the optional ordinal of a weekday value, such as -1 and Friday for -1FR
+func (r RecurrenceWeekday) Ordinal() (int, time.Weekday, bool) {
+	matches := ordinalDayRegExp.FindStringSubmatch(strings.ToUpper(string(r)))
+	if matches == nil {
+		return 0, time.Sunday, false
+	}
+	ordinal, _ := strconv.Atoi(strings.TrimPrefix(matches[1], "+"))
+	return ordinal, weekdays[matches[2]], true
+}
+
+// computes the start times of the recurrence set the rule generates for a first instance starting at dtstart,
+// in the location of dtstart. the first instance is always part of the set, as required by RFC 5545.
+// only the start times before the limit are returned, since a rule without a count or end may never end
+func (r *RecurrenceRule) Expand(dtstart time.Time, limit time.Time) []time.Time {
+	return r.ExpandRange(dtstart, dtstart, limit)
+}
+
+// same as Expand, leaving out the instances that start before a time, the first instance included.
+// a rule without a count is only scanned from the period holding that time, so that frequent rules, such as
+// minutely ones, are expanded over a range far from their first instance
+func (r *RecurrenceRule) ExpandRange(dtstart time.Time, start time.Time, limit time.Time) []time.Time {
+
+	var until time.Time
+	if r.Until != nil {
+		until = r.Until.In(dtstart.Location())
+		if r.Until.IsDate() {
+			until = until.AddDate(0, 0, 1).Add(-time.Second)
+		}
+	}
+
+	interval := r.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+
+	var starts []time.Time
+	if dtstart.Before(limit) && !dtstart.Before(start) {
+		starts = append(starts, dtstart)
+	}
+	if r.Count == 1 || !r.Frequency.IsValidFrequency() {
+		return starts
+	}
+
+	// the instances of a rule with a count must be counted from the first one
+	first := 0
+	if r.Count == 0 {
+		first = r.periodsUntil(dtstart, start.In(dtstart.Location())) / interval
+	}
+
+	count := 1
+	for n, empty := first, 0; empty < maxRecurrencePeriods; n++ {
+		period, set := r.periodSet(dtstart, n*interval)
+		if !period.Before(limit) || (!until.IsZero() && period.After(until)) {
+			break
+		} else if len(set) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+		for _, t := range set {
+			if !t.After(dtstart) {
+				continue // the first instance is already part of the set
+			} else if (!until.IsZero() && t.After(until)) || !t.Before(limit) {
+				return starts
+			}
+			if !t.Before(start) {
+				starts = append(starts, t)
+			}
+			if count++; r.Count > 0 && count >= r.Count {
+				return starts
+			}
+		}
+	}
+
+	return starts
+
+}
+
+// estimates the number of whole periods of the frequency of the rule between dtstart and a later time,
+// erring on the low side so that the period holding the time is never skipped
+func (r *RecurrenceRule) periodsUntil(dtstart time.Time, t time.Time) int {
+
+	if !t.After(dtstart) {
+		return 0
+	}
+
+	// periods are counted in wall clock time, as they are built, so that daylight saving time changes do not shift them
+	wall := func(t time.Time) time.Time {
+		y, m, d := t.Date()
+		h, mi, s := t.Clock()
+		return time.Date(y, m, d, h, mi, s, 0, time.UTC)
+	}
+	elapsed := wall(t).Sub(wall(dtstart))
+	days := int(wall(t).Truncate(24*time.Hour).Sub(wall(dtstart).Truncate(24*time.Hour)).Hours() / 24)
+
+	var n int
+	switch RecurrenceFrequency(strings.ToUpper(string(r.Frequency))) {
+	case YearRecurrenceFrequency:
+		n = t.Year() - dtstart.Year()
+	case MonthRecurrenceFrequency:
+		n = (t.Year()-dtstart.Year())*12 + int(t.Month()) - int(dtstart.Month())
+	case WeekRecurrenceFrequency:
+		n = days / 7
+	case DayRecurrenceFrequency:
+		n = days
+	case HourRecurrenceFrequency:
+		n = int(elapsed / time.Hour)
+	case MinuteRecurrenceFrequency:
+		n = int(elapsed / time.Minute)
+	case SecondRecurrenceFrequency:
+		n = int(elapsed / time.Second)
+	}
+
+	// a period is kept as a margin, for the periods of weeks that start before the time
+	if n--; n < 0 {
+		return 0
+	}
+	return n
+
+}
+
+// computes the start of the nth period of the rule and the sorted instances it holds
+func (r *RecurrenceRule) periodSet(dtstart time.Time, n int) (time.Time, []time.Time) {
+
+	loc := dtstart.Location()
+	y, m, d := dtstart.Date()
+	h, mi, s := dtstart.Clock()
+
+	var period time.Time
+	var days []time.Time
+	var clocks [][3]int
+
+	switch RecurrenceFrequency(strings.ToUpper(string(r.Frequency))) {
+	case YearRecurrenceFrequency:
+		period = time.Date(y+n, time.January, 1, 0, 0, 0, 0, loc)
+		days = r.yearDays(dtstart, y+n)
+	case MonthRecurrenceFrequency:
+		period = time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, loc)
+		days = r.monthDays(dtstart, period)
+	case WeekRecurrenceFrequency:
+		offset := (int(dtstart.Weekday()) - int(r.weekStart()) + 7) % 7
+		period = time.Date(y, m, d-offset+7*n, 0, 0, 0, 0, loc)
+		days = r.weekDays(dtstart, period)
+	case DayRecurrenceFrequency:
+		period = time.Date(y, m, d+n, 0, 0, 0, 0, loc)
+		days = r.filterDays([]time.Time{period}, false, false)
+	case HourRecurrenceFrequency:
+		period = time.Date(y, m, d, h+n, 0, 0, 0, loc)
+		if r.keepsDay(period) && intsContain(r.ByHour, period.Hour()) {
+			clocks = clockProduct([]int{period.Hour()}, orDefault(r.ByMinute, mi), orDefault(r.BySecond, s))
+			days = []time.Time{period}
+		}
+	case MinuteRecurrenceFrequency:
+		period = time.Date(y, m, d, h, mi+n, 0, 0, loc)
+		if r.keepsDay(period) && intsContain(r.ByHour, period.Hour()) && intsContain(r.ByMinute, period.Minute()) {
+			clocks = clockProduct([]int{period.Hour()}, []int{period.Minute()}, orDefault(r.BySecond, s))
+			days = []time.Time{period}
+		}
+	case SecondRecurrenceFrequency:
+		period = time.Date(y, m, d, h, mi, s+n, 0, loc)
+		if r.keepsDay(period) && intsContain(r.ByHour, period.Hour()) && intsContain(r.ByMinute, period.Minute()) &&
+			intsContain(r.BySecond, period.Second()) {
+			clocks = [][3]int{{period.Hour(), period.Minute(), period.Second()}}
+			days = []time.Time{period}
+		}
+	}
+
+	if clocks == nil {
+		clocks = clockProduct(orDefault(r.ByHour, h), orDefault(r.ByMinute, mi), orDefault(r.BySecond, s))
+	}
+
+	var set []time.Time
+	for _, day := range days {
+		for _, c := range clocks {
+			set = append(set, time.Date(day.Year(), day.Month(), day.Day(), c[0], c[1], c[2], 0, loc))
+		}
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i].Before(set[j]) })
+
+	return period, r.selectPositions(set)
+
+}
+
+// returns the days of a year that match the rule
+func (r *RecurrenceRule) yearDays(dtstart time.Time, year int) []time.Time {
+	loc := dtstart.Location()
+	if len(r.ByWeekNumber) == 0 && len(r.ByYearDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		// without any day selection, the instances fall on the day of the month of the first instance
+		var days []time.Time
+		for _, month := range orDefault(r.ByMonth, int(dtstart.Month())) {
+			if day := time.Date(year, time.Month(month), dtstart.Day(), 0, 0, 0, 0, loc); day.Day() == dtstart.Day() {
+				days = append(days, day)
+			}
+		}
+		return days
+	}
+	first, last := time.Date(year, time.January, 1, 0, 0, 0, 0, loc), time.Date(year, time.December, 31, 0, 0, 0, 0, loc)
+	if len(r.ByWeekNumber) > 0 {
+		// weeks numbers may cover days of the neighbouring years
+		first, _ = weekYear(year, r.weekStart(), loc)
+		next, _ := weekYear(year+1, r.weekStart(), loc)
+		last = next.AddDate(0, 0, -1)
+	}
+	var candidates []time.Time
+	for day := first; !day.After(last); day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc) {
+		candidates = append(candidates, day)
+	}
+	return r.filterDays(candidates, len(r.ByMonth) == 0, len(r.ByMonth) > 0)
+}
+
+// returns the days of a month that match the rule
+func (r *RecurrenceRule) monthDays(dtstart time.Time, month time.Time) []time.Time {
+	if len(r.ByMonth) > 0 && !intsContain(r.ByMonth, int(month.Month())) {
+		return nil
+	}
+	var candidates []time.Time
+	for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+		if len(r.ByMonthDay) > 0 || len(r.ByDay) > 0 || day.Day() == dtstart.Day() {
+			candidates = append(candidates, day)
+		}
+	}
+	return r.filterDays(candidates, false, true)
+}
+
+// returns the days of a week that match the rule
+func (r *RecurrenceRule) weekDays(dtstart time.Time, week time.Time) []time.Time {
+	var candidates []time.Time
+	for i := 0; i < 7; i++ {
+		day := time.Date(week.Year(), week.Month(), week.Day()+i, 0, 0, 0, 0, week.Location())
+		if len(r.ByDay) > 0 || day.Weekday() == dtstart.Weekday() {
+			candidates = append(candidates, day)
+		}
+	}
+	return r.filterDays(candidates, false, false)
+}
+
+// keeps the days matching the day selections of the rule.
+// ordinal weekdays, such as 2MO, are counted within the year or within the month of each day
+func (r *RecurrenceRule) filterDays(days []time.Time, ordinalInYear, ordinalInMonth bool) []time.Time {
+	var kept []time.Time
+	for _, day := range days {
+		if len(r.ByMonth) > 0 && !intsContain(r.ByMonth, int(day.Month())) {
+			continue
+		} else if len(r.ByWeekNumber) > 0 && !r.matchesWeekNumber(day) {
+			continue
+		} else if len(r.ByYearDay) > 0 && !matchesPosition(r.ByYearDay, day.YearDay(), daysIn(day.Year())) {
+			continue
+		} else if len(r.ByMonthDay) > 0 && !matchesPosition(r.ByMonthDay, day.Day(), daysInMonth(day)) {
+			continue
+		} else if len(r.ByDay) > 0 && !r.matchesWeekday(day, ordinalInYear && len(r.ByWeekNumber) == 0, ordinalInMonth) {
+			continue
+		}
+		kept = append(kept, day)
+	}
+	return kept
+}
+
+// checks if a day, as found in a sub-daily period, is kept by the day selections of the rule
+func (r *RecurrenceRule) keepsDay(t time.Time) bool {
+	return len(r.filterDays([]time.Time{t}, false, false)) > 0
+}
+
+func (r *RecurrenceRule) matchesWeekday(day time.Time, ordinalInYear, ordinalInMonth bool) bool {
+	for _, wd := range r.ByDay {
+		ordinal, weekday, ok := wd.Ordinal()
+		if !ok || weekday != day.Weekday() {
+			continue
+		} else if ordinal == 0 || (!ordinalInYear && !ordinalInMonth) {
+			return true
+		} else if ordinalInMonth && matchesOrdinal(ordinal, day.Day(), daysInMonth(day)) {
+			return true
+		} else if ordinalInYear && matchesOrdinal(ordinal, day.YearDay(), daysIn(day.Year())) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RecurrenceRule) matchesWeekNumber(day time.Time) bool {
+	wkst, loc := r.weekStart(), day.Location()
+	start, weeks := weekYear(day.Year(), wkst, loc)
+	if day.Before(start) {
+		start, weeks = weekYear(day.Year()-1, wkst, loc)
+	} else if next, nextWeeks := weekYear(day.Year()+1, wkst, loc); !day.Before(next) {
+		start, weeks = next, nextWeeks
+	}
+	week := int(day.Sub(start).Hours()/24+0.5)/7 + 1
+	return matchesPosition(r.ByWeekNumber, week, weeks)
+}
+
+func (r *RecurrenceRule) weekStart() time.Weekday {
+	if _, weekday, ok := r.WeekStart.Ordinal(); ok {
+		return weekday
+	}
+	return time.Monday
+}
+
+// keeps the instances of a period at the positions selected by the rule
+func (r *RecurrenceRule) selectPositions(set []time.Time) []time.Time {
+	if len(r.BySetPosition) == 0 {
+		return set
+	}
+	var selected []time.Time
+	for i, t := range set {
+		if matchesPosition(r.BySetPosition, i+1, len(set)) {
+			selected = append(selected, t)
+		}
+	}
+	return selected
+}
+
+// returns the first day of the first week of a year, the week holding at least four days of the year,
+// along with the number of weeks of the year
+func weekYear(year int, wkst time.Weekday, loc *time.Location) (time.Time, int) {
+	first := func(year int) time.Time {
+		jan1 := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+		offset := (int(jan1.Weekday()) - int(wkst) + 7) % 7
+		start := jan1.AddDate(0, 0, -offset)
+		if offset > 3 {
+			start = start.AddDate(0, 0, 7)
+		}
+		return start
+	}
+	start, next := first(year), first(year+1)
+	return start, int(next.Sub(start).Hours()/24+0.5) / 7
+}
+
+// checks if a day is the nth occurrence of its weekday within a month or a year of a number of days,
+// counting from the end for negative ordinals
+func matchesOrdinal(ordinal int, day int, days int) bool {
+	nth, after := (day-1)/7+1, (days-day)/7
+	return ordinal == nth || ordinal == -(after+1)
+}
+
+// checks if a one-based position, or its negative counterpart counted from the end, is in a list
+func matchesPosition(positions []int, position int, total int) bool {
+	for _, p := range positions {
+		if p == position || (p < 0 && total+p+1 == position) {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(year int) int {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+func daysInMonth(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// checks if a value is in a list, an empty list holding any value
+func intsContain(ints []int, value int) bool {
+	if len(ints) == 0 {
+		return true
+	}
+	for _, i := range ints {
+		if i == value {
+			return true
+		}
+	}
+	return false
+}
+
+func orDefault(ints []int, value int) []int {
+	if len(ints) == 0 {
+		return []int{value}
+	}
+	return ints
+}
+
+func clockProduct(hours, minutes, seconds []int) [][3]int {
+	var clocks [][3]int
+	for _, h := range hours {
+		for _, m := range minutes {
+			for _, s := range seconds {
+				clocks = append(clocks, [3]int{h, m, s})
+			}
+		}
+	}
+	return clocks
+}
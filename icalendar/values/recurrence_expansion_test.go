@@ -0,0 +1,109 @@
+package values
+
+import (
+	. "gopkg.in/check.v1"
+	"testing"
+	"time"
+)
+
+type RecurrenceExpansionSuite struct {
+	loc *time.Location
+}
+
+var _ = Suite(new(RecurrenceExpansionSuite))
+
+func TestRecurrenceExpansion(t *testing.T) { TestingT(t) }
+
+func (s *RecurrenceExpansionSuite) SetUpSuite(c *C) {
+	var err error
+	s.loc, err = time.LoadLocation("America/New_York")
+	c.Assert(err, IsNil)
+}
+
+// expands a rule of the examples of RFC 5545 section 3.8.5.3, formatting the start times in local time
+func (s *RecurrenceExpansionSuite) expand(c *C, rule string, dtstart string, limit string) []string {
+	r := new(RecurrenceRule)
+	c.Assert(r.DecodeICalValue(rule), IsNil)
+	start, err := time.ParseInLocation(DateTimeFormatString, dtstart, s.loc)
+	c.Assert(err, IsNil)
+	end, err := time.ParseInLocation(DateFormatString, limit, s.loc)
+	c.Assert(err, IsNil)
+	var starts []string
+	for _, t := range r.Expand(start, end) {
+		starts = append(starts, t.Format("2006-01-02 15:04"))
+	}
+	return starts
+}
+
+func (s *RecurrenceExpansionSuite) TestDaily(c *C) {
+	starts := s.expand(c, "FREQ=DAILY;COUNT=10", "19970902T090000", "20000101")
+	c.Assert(starts, HasLen, 10)
+	c.Assert(starts[0], Equals, "1997-09-02 09:00")
+	c.Assert(starts[9], Equals, "1997-09-11 09:00")
+	// the local time is kept across daylight saving time changes
+	starts = s.expand(c, "FREQ=DAILY;INTERVAL=10;COUNT=6", "19971001T090000", "20000101")
+	c.Assert(starts, DeepEquals, []string{"1997-10-01 09:00", "1997-10-11 09:00", "1997-10-21 09:00",
+		"1997-10-31 09:00", "1997-11-10 09:00", "1997-11-20 09:00"})
+}
+
+func (s *RecurrenceExpansionSuite) TestWeekly(c *C) {
+	starts := s.expand(c, "FREQ=WEEKLY;UNTIL=19971007T000000Z;WKST=SU;BYDAY=TU,TH", "19970902T090000", "20000101")
+	c.Assert(starts, DeepEquals, []string{"1997-09-02 09:00", "1997-09-04 09:00", "1997-09-09 09:00",
+		"1997-09-11 09:00", "1997-09-16 09:00", "1997-09-18 09:00", "1997-09-23 09:00", "1997-09-25 09:00",
+		"1997-09-30 09:00", "1997-10-02 09:00"})
+	starts = s.expand(c, "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU", "19970805T090000", "20000101")
+	c.Assert(starts, DeepEquals, []string{"1997-08-05 09:00", "1997-08-17 09:00", "1997-08-19 09:00", "1997-08-31 09:00"})
+}
+
+func (s *RecurrenceExpansionSuite) TestMonthly(c *C) {
+	starts := s.expand(c, "FREQ=MONTHLY;COUNT=10;BYDAY=1FR", "19970905T090000", "20000101")
+	c.Assert(starts, DeepEquals, []string{"1997-09-05 09:00", "1997-10-03 09:00", "1997-11-07 09:00",
+		"1997-12-05 09:00", "1998-01-02 09:00", "1998-02-06 09:00", "1998-03-06 09:00", "1998-04-03 09:00",
+		"1998-05-01 09:00", "1998-06-05 09:00"})
+	starts = s.expand(c, "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", "19970930T090000", "19980101")
+	c.Assert(starts, DeepEquals, []string{"1997-09-30 09:00", "1997-10-31 09:00", "1997-11-28 09:00", "1997-12-31 09:00"})
+	starts = s.expand(c, "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", "19980213T090000", "20000101")
+	c.Assert(starts, DeepEquals, []string{"1998-02-13 09:00", "1998-03-13 09:00", "1998-11-13 09:00", "1999-08-13 09:00"})
+	starts = s.expand(c, "FREQ=MONTHLY;COUNT=6;BYDAY=-2MO", "19970922T090000", "20000101")
+	c.Assert(starts, DeepEquals, []string{"1997-09-22 09:00", "1997-10-20 09:00", "1997-11-17 09:00",
+		"1997-12-22 09:00", "1998-01-19 09:00", "1998-02-16 09:00"})
+}
+
+func (s *RecurrenceExpansionSuite) TestYearly(c *C) {
+	starts := s.expand(c, "FREQ=YEARLY;COUNT=4;BYMONTH=6,7", "19970610T090000", "20000101")
+	c.Assert(starts, DeepEquals, []string{"1997-06-10 09:00", "1997-07-10 09:00", "1998-06-10 09:00", "1998-07-10 09:00"})
+	starts = s.expand(c, "FREQ=YEARLY;BYDAY=20MO", "19970519T090000", "20000101")
+	c.Assert(starts, DeepEquals, []string{"1997-05-19 09:00", "1998-05-18 09:00", "1999-05-17 09:00"})
+	starts = s.expand(c, "FREQ=YEARLY;BYWEEKNO=20;BYDAY=MO", "19970512T090000", "20000101")
+	c.Assert(starts, DeepEquals, []string{"1997-05-12 09:00", "1998-05-11 09:00", "1999-05-17 09:00"})
+	starts = s.expand(c, "FREQ=YEARLY;INTERVAL=3;COUNT=4;BYYEARDAY=1,100,200", "19970101T090000", "20010101")
+	c.Assert(starts, DeepEquals, []string{"1997-01-01 09:00", "1997-04-10 09:00", "1997-07-19 09:00", "2000-01-01 09:00"})
+}
+
+func (s *RecurrenceExpansionSuite) TestHourly(c *C) {
+	starts := s.expand(c, "FREQ=HOURLY;INTERVAL=3;UNTIL=19970902T210000Z", "19970902T090000", "20000101")
+	c.Assert(starts, DeepEquals, []string{"1997-09-02 09:00", "1997-09-02 12:00", "1997-09-02 15:00"})
+	starts = s.expand(c, "FREQ=MINUTELY;INTERVAL=20;BYHOUR=9,10", "19970902T090000", "19970903")
+	c.Assert(starts, DeepEquals, []string{"1997-09-02 09:00", "1997-09-02 09:20", "1997-09-02 09:40",
+		"1997-09-02 10:00", "1997-09-02 10:20", "1997-09-02 10:40"})
+}
+
+func (s *RecurrenceExpansionSuite) TestExpandRange(c *C) {
+	r := new(RecurrenceRule)
+	c.Assert(r.DecodeICalValue("FREQ=MINUTELY;INTERVAL=15"), IsNil)
+	dtstart := time.Date(1997, 9, 2, 9, 0, 0, 0, s.loc)
+	start := time.Date(2026, 1, 5, 10, 0, 0, 0, s.loc)
+	var starts []string
+	for _, t := range r.ExpandRange(dtstart, start, start.Add(time.Hour)) {
+		starts = append(starts, t.Format("2006-01-02 15:04"))
+	}
+	c.Assert(starts, DeepEquals, []string{"2026-01-05 10:00", "2026-01-05 10:15", "2026-01-05 10:30", "2026-01-05 10:45"})
+	// rules with a count are still counted from their first instance
+	c.Assert(r.DecodeICalValue("FREQ=HOURLY;COUNT=3"), IsNil)
+	c.Assert(r.ExpandRange(dtstart, start, start.Add(time.Hour)), HasLen, 0)
+}
+
+func (s *RecurrenceExpansionSuite) TestUnmatchedRule(c *C) {
+	starts := s.expand(c, "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", "19970902T090000", "21000101")
+	c.Assert(starts, DeepEquals, []string{"1997-09-02 09:00"})
+}
@@ -38,9 +38,9 @@ func (s *RecurrenceRuleSuite) SetUpSuite(c *C) {
 }
 
 func (s *RecurrenceRuleSuite) TestEncode(c *C) {
-	fs := "RRULE:FREQ=WEEKLY;UNTIL=%s;INTERVAL=2;" +
-		"BYSECOND=3;BYMINUTE=4;BYHOUR=5,6;BYDAY=MO,TU;BYMONTHDAY=7,8;BYYEARDAY=9,10,11;" +
-		"BYWEEKNO=12;BYMONTH=3;BYSETPOS=1;WKST=SU"
+	fs := "RRULE:FREQ=WEEKLY;UNTIL=%s;INTERVAL=2;BYSECOND=3;BYMINUTE=4;B\r\n" +
+		" YHOUR=5,6;BYDAY=MO,TU;BYMONTHDAY=7,8;BYYEARDAY=9,10,11;BYWEEKNO=12;BYMONTH\r\n" +
+		" =3;BYSETPOS=1;WKST=SU"
 	expected := fmt.Sprintf(fs, s.RecurrenceRule.Until)
 	actual, err := icalendar.Marshal(s.RecurrenceRule)
 	c.Assert(err, IsNil)
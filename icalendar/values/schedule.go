@@ -0,0 +1,54 @@
+package values
+
+import (
+	"strings"
+)
+
+// Specifies whether the server or the client is responsible for delivering the scheduling messages of a calendar
+// user, as defined in RFC 6638 section 7.1. The parameter is set on the "ORGANIZER" and "ATTENDEE" properties.
+type ScheduleAgent string
+
+const (
+	ServerScheduleAgent ScheduleAgent = "SERVER" // The server handles scheduling. DEFAULT
+	ClientScheduleAgent               = "CLIENT" // The client handles scheduling.
+	NoneScheduleAgent                 = "NONE"   // No scheduling messages are to be sent.
+)
+
+// Forces the server to send a scheduling message to a calendar user, even if it would not have sent one otherwise,
+// as defined in RFC 6638 section 7.2.
+type ScheduleForceSend string
+
+const (
+	RequestScheduleForceSend ScheduleForceSend = "REQUEST" // Sends a request to an attendee.
+	ReplyScheduleForceSend                     = "REPLY"   // Sends a reply to the organizer.
+)
+
+// The status codes of the last scheduling messages delivered to a calendar user, as defined in RFC 6638 section 7.3,
+// such as "1.2" when the message was delivered or "3.7" when the calendar user address is invalid.
+type ScheduleStatus []string
+
+// decodes a comma separated list of status codes
+func NewScheduleStatus(value string) ScheduleStatus {
+	var status ScheduleStatus
+	for _, code := range strings.Split(value, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			status = append(status, code)
+		}
+	}
+	return status
+}
+
+// checks if every message was delivered or is still pending delivery, that is if all codes are of class 1 or 2
+func (s ScheduleStatus) Delivered() bool {
+	for _, code := range s {
+		if !strings.HasPrefix(code, "1.") && !strings.HasPrefix(code, "2.") {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+// encodes the status codes as a comma separated list
+func (s ScheduleStatus) String() string {
+	return strings.Join(s, ",")
+}
@@ -35,3 +35,13 @@ func (e *Error) Error() string {
 	}
 	return msg
 }
+
+// returns the message describing the error, without its cause
+func (e *Error) Message() string {
+	return e.message
+}
+
+// returns the error that caused this error, if any
+func (e *Error) Unwrap() error {
+	return e.cause
+}
@@ -1,6 +1,8 @@
 package webdav
 
 import (
+	"context"
+	"encoding/xml"
 	"fmt"
 	"github.com/dolanor/caldav-go/http"
 	"github.com/dolanor/caldav-go/utils"
@@ -36,10 +38,15 @@ func (c *Client) Do(req *Request) (*Response, error) {
 
 // checks if a resource exists given a particular path
 func (c *Client) Exists(path string) (bool, error) {
-	if req, err := c.Server().NewRequest("HEAD", path); err != nil {
-		return false, utils.NewError(c.Exists, "unable to create request", c, err)
+	return c.ExistsContext(context.Background(), path)
+}
+
+// same as Exists, using a context to cancel the request
+func (c *Client) ExistsContext(ctx context.Context, path string) (bool, error) {
+	if req, err := c.Server().NewRequestWithContext(ctx, "HEAD", path); err != nil {
+		return false, utils.NewError(c.ExistsContext, "unable to create request", c, err)
 	} else if resp, err := c.Do(req); err != nil {
-		return false, utils.NewError(c.Exists, "unable to execute request", c, err)
+		return false, utils.NewError(c.ExistsContext, "unable to execute request", c, err)
 	} else {
 		return resp.StatusCode != nhttp.StatusNotFound, nil
 	}
@@ -47,15 +54,19 @@ func (c *Client) Exists(path string) (bool, error) {
 
 // deletes a resource if it exists on a particular path
 func (c *Client) Delete(path string) error {
-	if req, err := c.Server().NewRequest("DELETE", path); err != nil {
-		return utils.NewError(c.Delete, "unable to create request", c, err)
+	return c.DeleteContext(context.Background(), path)
+}
+
+// same as Delete, using a context to cancel the request
+func (c *Client) DeleteContext(ctx context.Context, path string) error {
+	if req, err := c.Server().NewRequestWithContext(ctx, "DELETE", path); err != nil {
+		return utils.NewError(c.DeleteContext, "unable to create request", c, err)
 	} else if resp, err := c.Do(req); err != nil {
-		return utils.NewError(c.Delete, "unable to execute request", c, err)
+		return utils.NewError(c.DeleteContext, "unable to execute request", c, err)
 	} else if resp.StatusCode != nhttp.StatusNoContent && resp.StatusCode != nhttp.StatusNotFound {
-		err := new(entities.Error)
-		resp.Decode(err)
+		err := resp.DecodeError()
 		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
-		return utils.NewError(c.Delete, msg, c, err)
+		return utils.NewError(c.DeleteContext, msg, c, err)
 	} else {
 		return nil
 	}
@@ -64,10 +75,15 @@ func (c *Client) Delete(path string) error {
 // fetches a list of WebDAV features supported by the server
 // returns an error if the server does not support DAV
 func (c *Client) Features(path string) ([]string, error) {
-	if req, err := c.Server().NewRequest("OPTIONS", path); err != nil {
-		return []string{}, utils.NewError(c.Features, "unable to create request", c, err)
+	return c.FeaturesContext(context.Background(), path)
+}
+
+// same as Features, using a context to cancel the request
+func (c *Client) FeaturesContext(ctx context.Context, path string) ([]string, error) {
+	if req, err := c.Server().NewRequestWithContext(ctx, "OPTIONS", path); err != nil {
+		return []string{}, utils.NewError(c.FeaturesContext, "unable to create request", c, err)
 	} else if resp, err := c.Do(req); err != nil {
-		return []string{}, utils.NewError(c.Features, "unable to execute request", c, err)
+		return []string{}, utils.NewError(c.FeaturesContext, "unable to execute request", c, err)
 	} else {
 		return resp.Features(), nil
 	}
@@ -75,10 +91,15 @@ func (c *Client) Features(path string) ([]string, error) {
 
 // returns an error if the server does not support WebDAV
 func (c *Client) ValidateServer(path string) error {
-	if features, err := c.Features(path); err != nil {
-		return utils.NewError(c.ValidateServer, "feature detection failed", c, err)
+	return c.ValidateServerContext(context.Background(), path)
+}
+
+// same as ValidateServer, using a context to cancel the request
+func (c *Client) ValidateServerContext(ctx context.Context, path string) error {
+	if features, err := c.FeaturesContext(ctx, path); err != nil {
+		return utils.NewError(c.ValidateServerContext, "feature detection failed", c, err)
 	} else if len(features) <= 0 {
-		return utils.NewError(c.ValidateServer, "no DAV headers found", c, err)
+		return utils.NewError(c.ValidateServerContext, "no DAV headers found", c, err)
 	} else {
 		return nil
 	}
@@ -87,26 +108,155 @@ func (c *Client) ValidateServer(path string) error {
 // executes a PROPFIND request against the WebDAV server
 // returns a multistatus XML entity
 func (c *Client) Propfind(path string, depth Depth, pf *entities.Propfind) (*entities.Multistatus, error) {
+	return c.PropfindContext(context.Background(), path, depth, pf)
+}
+
+// same as Propfind, using a context to cancel the request
+func (c *Client) PropfindContext(ctx context.Context, path string, depth Depth, pf *entities.Propfind) (*entities.Multistatus, error) {
 
 	ms := new(entities.Multistatus)
 
-	if req, err := c.Server().NewRequest("PROPFIND", path, pf); err != nil {
-		return nil, utils.NewError(c.Propfind, "unable to create request", c, err)
+	if req, err := c.Server().NewRequestWithContext(ctx, "PROPFIND", path, pf); err != nil {
+		return nil, utils.NewError(c.PropfindContext, "unable to create request", c, err)
 	} else if req.Http().Native().Header.Set("Depth", string(depth)); depth == "" {
-		return nil, utils.NewError(c.Propfind, "search depth must be defined", c, nil)
+		return nil, utils.NewError(c.PropfindContext, "search depth must be defined", c, nil)
 	} else if resp, err := c.Do(req); err != nil {
-		return nil, utils.NewError(c.Propfind, "unable to execute request", c, err)
+		return nil, utils.NewError(c.PropfindContext, "unable to execute request", c, err)
 	} else if resp.StatusCode != StatusMulti {
 		msg := fmt.Sprintf("unexpected status: %s", resp.Status)
-		return nil, utils.NewError(c.Propfind, msg, c, nil)
+		return nil, utils.NewError(c.PropfindContext, msg, c, resp.DecodeError())
 	} else if err := resp.Decode(ms); err != nil {
-		return nil, utils.NewError(c.Propfind, "unable to decode response", c, err)
+		return nil, utils.NewError(c.PropfindContext, "unable to decode response", c, err)
 	}
 
 	return ms, nil
 
 }
 
+// lists the names of the properties defined on a resource, and on its members depending on the depth
+// returns the property names found for each resource, keyed by its href
+func (c *Client) PropNames(path string, depth Depth) (map[string][]xml.Name, error) {
+	return c.PropNamesContext(context.Background(), path, depth)
+}
+
+// same as PropNames, using a context to cancel the request
+func (c *Client) PropNamesContext(ctx context.Context, path string, depth Depth) (map[string][]xml.Name, error) {
+
+	ms := new(entities.NamedMultistatus)
+
+	if req, err := c.Server().NewRequestWithContext(ctx, "PROPFIND", path, entities.NewPropNameFind()); err != nil {
+		return nil, utils.NewError(c.PropNamesContext, "unable to create request", c, err)
+	} else if req.Http().Native().Header.Set("Depth", string(depth)); depth == "" {
+		return nil, utils.NewError(c.PropNamesContext, "search depth must be defined", c, nil)
+	} else if resp, err := c.Do(req); err != nil {
+		return nil, utils.NewError(c.PropNamesContext, "unable to execute request", c, err)
+	} else if resp.StatusCode != StatusMulti {
+		err := resp.DecodeError()
+		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
+		return nil, utils.NewError(c.PropNamesContext, msg, c, err)
+	} else if err := resp.Decode(ms); err != nil {
+		return nil, utils.NewError(c.PropNamesContext, "unable to decode response", c, err)
+	}
+
+	names := make(map[string][]xml.Name)
+	for _, r := range ms.Responses {
+		for _, ps := range r.PropStats {
+			if ps.Prop == nil || !ps.Status.OK() {
+				continue
+			}
+			for _, p := range ps.Prop.Props {
+				names[r.Href] = append(names[r.Href], p.XMLName)
+			}
+		}
+	}
+
+	return names, nil
+
+}
+
+// executes a PROPPATCH request against the WebDAV server, setting and removing properties in a single update.
+// set is any property entity, such as *entities.Prop, and may be nil to only remove properties.
+// returns the outcome of every property, along with a *ProppatchError if any of them were rejected
+func (c *Client) Proppatch(path string, set interface{}, remove []xml.Name) ([]*PropResult, error) {
+	return c.ProppatchContext(context.Background(), path, set, remove)
+}
+
+// same as Proppatch, using a context to cancel the request
+func (c *Client) ProppatchContext(ctx context.Context, path string, set interface{}, remove []xml.Name) ([]*PropResult, error) {
+
+	ms := new(entities.NamedMultistatus)
+	pu := entities.NewPropertyUpdate(set, remove...)
+
+	if pu.Set == nil && pu.Remove == nil {
+		return nil, utils.NewError(c.ProppatchContext, "no properties to update", c, nil)
+	} else if req, err := c.Server().NewRequestWithContext(ctx, "PROPPATCH", path, pu); err != nil {
+		return nil, utils.NewError(c.ProppatchContext, "unable to create request", c, err)
+	} else if resp, err := c.Do(req); err != nil {
+		return nil, utils.NewError(c.ProppatchContext, "unable to execute request", c, err)
+	} else if resp.StatusCode != StatusMulti {
+		err := resp.DecodeError()
+		msg := fmt.Sprintf("unexpected server response %s", resp.Status)
+		return nil, utils.NewError(c.ProppatchContext, msg, c, err)
+	} else if err := resp.Decode(ms); err != nil {
+		return nil, utils.NewError(c.ProppatchContext, "unable to decode response", c, err)
+	}
+
+	var href string
+	var results []*PropResult
+	var failed bool
+
+	for _, r := range ms.Responses {
+		href = r.Href
+		if r.Status != nil && !r.Status.OK() {
+			// the whole update was rejected, so none of the properties were updated
+			for _, name := range updatedPropNames(pu) {
+				result := &PropResult{Name: name, StatusCode: r.Status.Code}
+				results = append(results, result)
+				failed = true
+			}
+			continue
+		}
+		for _, ps := range r.PropStats {
+			if ps.Prop == nil {
+				continue
+			}
+			for _, p := range ps.Prop.Props {
+				result := &PropResult{Name: p.XMLName, StatusCode: ps.Status.Code, Description: ps.ResponseDescription, Error: ps.Error}
+				results = append(results, result)
+				failed = failed || !result.OK()
+			}
+		}
+	}
+
+	if len(results) == 0 {
+		return nil, utils.NewError(c.ProppatchContext, "no property outcome in server response", c, nil)
+	} else if failed {
+		return results, &ProppatchError{Href: href, Results: results}
+	}
+
+	return results, nil
+
+}
+
+// lists the names of the properties set and removed by a property update
+func updatedPropNames(pu *entities.PropertyUpdate) []xml.Name {
+	var names []xml.Name
+	if pu.Set != nil {
+		props := new(entities.NamedProps)
+		if data, err := xml.Marshal(pu.Set.Prop); err == nil && xml.Unmarshal(data, props) == nil {
+			for _, p := range props.Props {
+				names = append(names, p.XMLName)
+			}
+		}
+	}
+	if pu.Remove != nil && pu.Remove.Prop != nil {
+		for _, p := range pu.Remove.Prop.Props {
+			names = append(names, p.XMLName)
+		}
+	}
+	return names
+}
+
 // creates a new client for communicating with an WebDAV server
 func NewClient(server *Server, native *nhttp.Client) *Client {
 	return (*Client)(http.NewClient((*http.Server)(server), native))
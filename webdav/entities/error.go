@@ -1,18 +1,33 @@
 package entities
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"strings"
+)
 
-// a WebDAV error
+// a WebDAV error, naming the preconditions or postconditions that failed
 type Error struct {
 	XMLName     xml.Name `xml:"DAV: error"`
 	Description string   `xml:"error-description,omitempty"`
 	Message     string   `xml:"message,omitempty"`
+	// the condition elements of the error, such as DAV:lock-token-submitted
+	Conditions []*Property `xml:",any"`
+}
+
+// returns the condition element of the given name, or nil if the error does not hold one
+func (e *Error) Condition(name xml.Name) *Property {
+	return FindProperty(e.Conditions, name)
 }
 
 func (e *Error) Error() string {
 	if e.Description != "" {
 		return e.Description
-	} else {
+	} else if e.Message != "" {
 		return e.Message
 	}
+	var names []string
+	for _, c := range e.Conditions {
+		names = append(names, c.XMLName.Local)
+	}
+	return strings.Join(names, ", ")
 }
@@ -6,12 +6,28 @@ import (
 
 // a property of a resource
 type Prop struct {
-	XMLName        xml.Name      `xml:"DAV: prop"`
-	GetContentType string        `xml:"getcontenttype,omitempty"`
-	DisplayName    string        `xml:"displayname,omitempty"`
-	ResourceType   *ResourceType `xml:",omitempty"`
-	CTag           string        `xml:"http://calendarserver.org/ns/ getctag,omitempty"`
-	ETag           string        `xml:"http://calendarserver.org/ns/ getetag,omitempty"`
+	XMLName              xml.Name              `xml:"DAV: prop"`
+	GetContentType       string                `xml:"getcontenttype,omitempty"`
+	DisplayName          string                `xml:"displayname,omitempty"`
+	ResourceType         *ResourceType         `xml:",omitempty"`
+	CurrentUserPrincipal *CurrentUserPrincipal `xml:",omitempty"`
+	CTag                 string                `xml:"http://calendarserver.org/ns/ getctag,omitempty"`
+	ETag                 string                `xml:"http://calendarserver.org/ns/ getetag,omitempty"`
+	Extra                []*Property           `xml:",any"`
+}
+
+// creates a property list with empty properties of the given names, as used to request them
+func NewPropNames(names ...xml.Name) *Prop {
+	p := new(Prop)
+	for _, name := range names {
+		p.Extra = append(p.Extra, NewProperty(name))
+	}
+	return p
+}
+
+// returns a property without a dedicated field by name, or nil if it is not present
+func (p *Prop) Property(name xml.Name) *Property {
+	return FindProperty(p.Extra, name)
 }
 
 // the type of a resource
@@ -30,3 +46,9 @@ type ResourceTypeCalendar struct {
 type ResourceTypeCollection struct {
 	XMLName xml.Name `xml:"collection"`
 }
+
+// the principal resource of the currently authenticated user, as defined in RFC 5397
+type CurrentUserPrincipal struct {
+	XMLName xml.Name `xml:"current-user-principal"`
+	Href    string   `xml:"href,omitempty"`
+}
@@ -0,0 +1,162 @@
+package entities
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// a property of any name, such as a dead or custom property.
+// the value is kept as inner XML with its namespaces declared inline, so that it survives a round trip.
+type Property struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr
+	InnerXML string
+}
+
+// the raw form of a property, as written to the wire
+type rawProperty struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	InnerXML string     `xml:",innerxml"`
+}
+
+// creates an empty property, as used to request a property by name
+func NewProperty(name xml.Name) *Property {
+	return &Property{XMLName: name}
+}
+
+// creates a property holding a text value
+func NewTextProperty(name xml.Name, text string) *Property {
+	p := NewProperty(name)
+	p.SetText(text)
+	return p
+}
+
+// creates a property holding the XML encoding of a typed value
+func NewTypedProperty(name xml.Name, v interface{}) (*Property, error) {
+	p := NewProperty(name)
+	if err := p.SetValue(v); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// checks if the property holds no value
+func (p *Property) IsEmpty() bool {
+	return strings.TrimSpace(p.InnerXML) == ""
+}
+
+// replaces the value of the property with escaped text
+func (p *Property) SetText(text string) {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	p.InnerXML = buf.String()
+}
+
+// replaces the value of the property with the XML encoding of a typed value
+func (p *Property) SetValue(v interface{}) error {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	if err := enc.EncodeElement(v, xml.StartElement{Name: p.XMLName}); err != nil {
+		return err
+	} else if err := enc.Flush(); err != nil {
+		return err
+	}
+	decoded := new(Property)
+	if err := xml.Unmarshal(buf.Bytes(), decoded); err != nil {
+		return err
+	}
+	p.Attrs, p.InnerXML = decoded.Attrs, decoded.InnerXML
+	return nil
+}
+
+// returns the character data of the property, ignoring any child elements
+func (p *Property) Text() string {
+	var text []byte
+	d := xml.NewDecoder(strings.NewReader(p.InnerXML))
+	for {
+		if token, err := d.Token(); err != nil {
+			return string(text)
+		} else if data, ok := token.(xml.CharData); ok {
+			text = append(text, data...)
+		}
+	}
+}
+
+// decodes the property into a typed value, in the same way xml.Unmarshal would decode the property element
+func (p *Property) Decode(v interface{}) error {
+	if data, err := xml.Marshal(p); err != nil {
+		return err
+	} else {
+		return xml.Unmarshal(data, v)
+	}
+}
+
+func (p *Property) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	raw := rawProperty{XMLName: p.XMLName, Attrs: p.Attrs, InnerXML: p.InnerXML}
+	return e.EncodeElement(&raw, xml.StartElement{Name: p.XMLName})
+}
+
+func (p *Property) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+
+	var buf bytes.Buffer
+	var depth int
+	enc := xml.NewEncoder(&buf)
+
+	p.XMLName = start.Name
+	p.Attrs = withoutNamespaceDeclarations(start.Attr)
+
+	// re-encode the tokens of the value, which declares every namespace inline
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			t.Attr = withoutNamespaceDeclarations(t.Attr)
+			token = t
+		case xml.EndElement:
+			if depth == 0 {
+				if err := enc.Flush(); err != nil {
+					return err
+				}
+				p.InnerXML = buf.String()
+				return nil
+			}
+			depth--
+		case xml.ProcInst, xml.Directive:
+			continue
+		}
+		if err := enc.EncodeToken(xml.CopyToken(token)); err != nil {
+			return err
+		}
+	}
+
+}
+
+// returns the first property of a list with a given name
+func FindProperty(props []*Property, name xml.Name) *Property {
+	for _, p := range props {
+		if p.XMLName == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// drops xmlns attributes, since the encoder declares the namespaces it needs by itself
+func withoutNamespaceDeclarations(attrs []xml.Attr) []xml.Attr {
+	var filtered []xml.Attr
+	for _, attr := range attrs {
+		if attr.Name.Space != "xmlns" && attr.Name.Local != "xmlns" {
+			filtered = append(filtered, attr)
+		}
+	}
+	return filtered
+}
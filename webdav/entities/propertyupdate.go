@@ -0,0 +1,38 @@
+package entities
+
+import "encoding/xml"
+
+// a request to set and remove properties of a resource
+type PropertyUpdate struct {
+	XMLName xml.Name `xml:"DAV: propertyupdate"`
+	Set     *Set     `xml:",omitempty"`
+	Remove  *Remove  `xml:",omitempty"`
+}
+
+// a set of properties to apply to a resource
+type Set struct {
+	XMLName xml.Name    `xml:"DAV: set"`
+	Prop    interface{} `xml:",omitempty"`
+}
+
+// a set of properties to remove from a resource
+type Remove struct {
+	XMLName xml.Name    `xml:"DAV: remove"`
+	Prop    *NamedProps `xml:",omitempty"`
+}
+
+// creates a new property update request
+// prop is any property entity, such as *Prop, and may be nil to only remove properties
+func NewPropertyUpdate(prop interface{}, remove ...xml.Name) *PropertyUpdate {
+	pu := new(PropertyUpdate)
+	if prop != nil {
+		pu.Set = &Set{Prop: prop}
+	}
+	if len(remove) > 0 {
+		pu.Remove = &Remove{Prop: new(NamedProps)}
+		for _, name := range remove {
+			pu.Remove.Prop.Props = append(pu.Remove.Prop.Props, &NamedProp{XMLName: name})
+		}
+	}
+	return pu
+}
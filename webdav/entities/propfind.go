@@ -4,9 +4,11 @@ import "encoding/xml"
 
 // a request to find properties on an an entity or collection
 type Propfind struct {
-	XMLName xml.Name `xml:"DAV: propfind"`
-	AllProp *AllProp `xml:",omitempty"`
-	Props   []*Prop  `xml:"prop,omitempty"`
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	PropName *PropName `xml:",omitempty"`
+	AllProp  *AllProp  `xml:",omitempty"`
+	Include  *Include  `xml:",omitempty"`
+	Props    []*Prop   `xml:"prop,omitempty"`
 }
 
 // a propfind property representing all properties
@@ -14,7 +16,42 @@ type AllProp struct {
 	XMLName xml.Name `xml:"allprop"`
 }
 
+// a propfind property requesting the names of all properties, without their values
+type PropName struct {
+	XMLName xml.Name `xml:"DAV: propname"`
+}
+
+// properties to return along with all properties, such as live properties the server leaves out by default
+type Include struct {
+	XMLName xml.Name    `xml:"DAV: include"`
+	Props   []*Property `xml:",any"`
+}
+
+// creates a list of included properties from their names
+func NewInclude(names ...xml.Name) *Include {
+	include := new(Include)
+	for _, name := range names {
+		include.Props = append(include.Props, NewProperty(name))
+	}
+	return include
+}
+
 // a convenience method for searching all properties
-func NewAllPropsFind() *Propfind {
-	return &Propfind{AllProp: new(AllProp)}
+// optionally includes properties that are not returned by default
+func NewAllPropsFind(include ...xml.Name) *Propfind {
+	pf := &Propfind{AllProp: new(AllProp)}
+	if len(include) > 0 {
+		pf.Include = NewInclude(include...)
+	}
+	return pf
+}
+
+// a convenience method for listing the names of all properties
+func NewPropNameFind() *Propfind {
+	return &Propfind{PropName: new(PropName)}
+}
+
+// a convenience method for searching a set of properties by name
+func NewNamedPropFind(names ...xml.Name) *Propfind {
+	return &Propfind{Props: []*Prop{NewPropNames(names...)}}
 }
@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// a property element of which only the name is decoded
+type NamedProp struct {
+	XMLName xml.Name
+}
+
+// a list of properties, identified by name only
+type NamedProps struct {
+	XMLName xml.Name     `xml:"DAV: prop"`
+	Props   []*NamedProp `xml:",any"`
+}
+
+// the status of a group of properties, identified by name only
+type NamedPropStat struct {
+	XMLName             xml.Name    `xml:"DAV: propstat"`
+	Prop                *NamedProps `xml:",omitempty"`
+	Status              Status      `xml:"DAV: status"`
+	Error               *Error      `xml:"DAV: error,omitempty"`
+	ResponseDescription string      `xml:"DAV: responsedescription,omitempty"`
+}
+
+// describes the properties and the status they failed with
+func (p *NamedPropStat) String() string {
+	var names []string
+	if p.Prop != nil {
+		for _, prop := range p.Prop.Props {
+			names = append(names, prop.XMLName.Local)
+		}
+	}
+	msg := fmt.Sprintf("%s: %s", strings.Join(names, ", "), p.Status)
+	if p.ResponseDescription != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, p.ResponseDescription)
+	}
+	return msg
+}
+
+// a multistatus response entity, in which properties are identified by name only
+type NamedResponse struct {
+	XMLName   xml.Name         `xml:"DAV: response"`
+	Href      string           `xml:"DAV: href"`
+	Status    *Status          `xml:"DAV: status,omitempty"`
+	PropStats []*NamedPropStat `xml:"DAV: propstat,omitempty"`
+}
+
+// a multistatus response, in which properties are identified by name only
+type NamedMultistatus struct {
+	XMLName   xml.Name         `xml:"DAV: multistatus"`
+	Responses []*NamedResponse `xml:"DAV: response,omitempty"`
+}
@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// an HTTP status line as found in multistatus responses, such as "HTTP/1.1 404 Not Found"
+type Status struct {
+	Line string
+	Code int
+}
+
+// creates a status line for a status code
+func NewStatus(code int) Status {
+	return Status{Line: fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code)), Code: code}
+}
+
+// checks if the status reports a success
+func (s Status) OK() bool {
+	return s.Code >= 200 && s.Code < 300
+}
+
+// checks if the status reports a missing resource or property
+func (s Status) NotFound() bool {
+	return s.Code == http.StatusNotFound
+}
+
+// checks if the status reports a resource or property the user is not allowed to access
+func (s Status) Forbidden() bool {
+	return s.Code == http.StatusForbidden
+}
+
+// checks if the status reports a failure caused by another failure in the same request
+func (s Status) FailedDependency() bool {
+	return s.Code == http.StatusFailedDependency
+}
+
+func (s Status) String() string {
+	if s.Line == "" {
+		return "missing status"
+	}
+	return s.Line
+}
+
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.Line), nil
+}
+
+// decodes a status line, keeping the raw line with a zero code when it cannot be parsed,
+// so that a single odd status does not fail the whole multistatus response
+func (s *Status) UnmarshalText(text []byte) error {
+	s.Line, s.Code = strings.TrimSpace(string(text)), 0
+	if parts := strings.Fields(s.Line); len(parts) >= 2 {
+		if code, err := strconv.Atoi(parts[1]); err == nil {
+			s.Code = code
+		}
+	}
+	return nil
+}
+
+// the URL a resource has moved to, along with a redirection status
+type Location struct {
+	Href string `xml:"DAV: href"`
+}
@@ -0,0 +1,132 @@
+package webdav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/dolanor/caldav-go/http"
+	"github.com/dolanor/caldav-go/webdav/entities"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// sentinel errors matched by errors.Is against the status of a failed response, see the http package
+var (
+	ErrUnauthorized       = http.ErrUnauthorized
+	ErrForbidden          = http.ErrForbidden
+	ErrNotFound           = http.ErrNotFound
+	ErrConflict           = http.ErrConflict
+	ErrPreconditionFailed = http.ErrPreconditionFailed
+)
+
+// the maximum number of bytes of an error response read while decoding it
+const maxErrorBodyLength = 64 * 1024
+
+// an unexpected response whose DAV:error body names the preconditions or postconditions that failed
+type DAVError struct {
+	// the response that carried the error
+	HTTP *http.HTTPError
+	// the decoded DAV:error body
+	Body *entities.Error
+}
+
+// a failed precondition or postcondition of a DAV:error body
+type Condition struct {
+	Name xml.Name
+	// the resources the condition refers to, such as the resource already
+	// holding the UID of a CALDAV:no-uid-conflict
+	Hrefs []string
+	// the character data of the condition element, if any
+	Text string
+}
+
+// returns the conditions named by the error, in the order of the response
+func (e *DAVError) Conditions() []*Condition {
+	conditions := make([]*Condition, 0, len(e.Body.Conditions))
+	for _, p := range e.Body.Conditions {
+		conditions = append(conditions, newCondition(p))
+	}
+	return conditions
+}
+
+// returns the condition of the given name, or nil if the error does not name it
+func (e *DAVError) Condition(name xml.Name) *Condition {
+	if p := e.Body.Condition(name); p == nil {
+		return nil
+	} else {
+		return newCondition(p)
+	}
+}
+
+// checks if the error names a condition
+func (e *DAVError) Has(name xml.Name) bool {
+	return e.Body.Condition(name) != nil
+}
+
+func (e *DAVError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected server response %s: %s", e.HTTP.Method, e.HTTP.URL, e.HTTP.Status, e.Body.Error())
+}
+
+// gives access to the HTTP error, so that errors.Is matches the sentinels of the http package
+func (e *DAVError) Unwrap() error {
+	return e.HTTP
+}
+
+func newCondition(p *entities.Property) *Condition {
+	c := &Condition{Name: p.XMLName, Text: strings.TrimSpace(p.Text())}
+	refs := new(struct {
+		Hrefs []string `xml:"DAV: href"`
+	})
+	if err := p.Decode(refs); err == nil {
+		c.Hrefs = refs.Hrefs
+	}
+	return c
+}
+
+// creates the error describing an unexpected response from its body.
+// returns a *DAVError if the body is a DAV:error element, or an *http.HTTPError otherwise
+func NewResponseError(resp *Response, body []byte) error {
+	httpErr := http.NewHTTPError(resp.Http().Native(), body)
+	davErr := new(entities.Error)
+	if len(body) == 0 || xml.Unmarshal(body, davErr) != nil {
+		return httpErr
+	}
+	return &DAVError{HTTP: httpErr, Body: davErr}
+}
+
+// reads the body of an unexpected response into an error, see NewResponseError
+func (r *Response) DecodeError() error {
+	var body []byte
+	if r.Body != nil {
+		body, _ = ioutil.ReadAll(io.LimitReader(r.Body, maxErrorBodyLength))
+	}
+	return NewResponseError(r, body)
+}
+
+// collects the DAV:error bodies found in an error chain: the body of a *DAVError,
+// those of the failed resources of a *MultistatusError and those of the properties of a *ProppatchError
+func ErrorBodies(err error) []*entities.Error {
+	var bodies []*entities.Error
+	var davErr *DAVError
+	var msErr *MultistatusError
+	var ppErr *ProppatchError
+	if errors.As(err, &davErr) {
+		bodies = append(bodies, davErr.Body)
+	}
+	if errors.As(err, &msErr) {
+		for _, f := range msErr.Failures {
+			if f.Error != nil {
+				bodies = append(bodies, f.Error)
+			}
+		}
+	}
+	if errors.As(err, &ppErr) {
+		for _, r := range ppErr.Results {
+			if r.Error != nil {
+				bodies = append(bodies, r.Error)
+			}
+		}
+	}
+	return bodies
+}
@@ -0,0 +1,109 @@
+package webdav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/dolanor/caldav-go/utils"
+	"github.com/dolanor/caldav-go/webdav/entities"
+	"io"
+	"strings"
+)
+
+// a resource of a multistatus response that the server failed to process
+type ResponseFailure struct {
+	Href        string
+	Status      entities.Status
+	Description string
+	Error       *entities.Error
+}
+
+func (f *ResponseFailure) String() string {
+	msg := fmt.Sprintf("%s: %s", f.Href, f.Status)
+	if f.Description != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, f.Description)
+	} else if f.Error != nil && f.Error.Error() != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, f.Error)
+	}
+	return msg
+}
+
+// an error reporting the resources of a multistatus response that failed,
+// while the remaining resources of the response may have succeeded
+type MultistatusError struct {
+	Failures []*ResponseFailure
+}
+
+func (e *MultistatusError) Error() string {
+	var failed []string
+	for _, f := range e.Failures {
+		failed = append(failed, f.String())
+	}
+	return fmt.Sprintf("%d resources failed: %s", len(e.Failures), strings.Join(failed, "; "))
+}
+
+// returned by a callback to stop iterating over a multistatus response early, without reporting an error
+var ErrStop = errors.New("stop iteration")
+
+// decodes the responses of a multistatus body one at a time, so that large bodies are never held in memory at once
+type MultistatusDecoder struct {
+	decoder *xml.Decoder
+	started bool
+	done    bool
+	// the description of the multistatus as a whole, available once all responses have been decoded
+	ResponseDescription string
+}
+
+// creates a decoder reading a multistatus body from a reader
+func NewMultistatusDecoder(r io.Reader) *MultistatusDecoder {
+	return &MultistatusDecoder{decoder: xml.NewDecoder(r)}
+}
+
+// decodes the next DAV:response element into the provided entity, such as *entities.Response
+// returns io.EOF once every response has been decoded
+func (d *MultistatusDecoder) Next(into interface{}) error {
+
+	if d.done {
+		return io.EOF
+	}
+
+	for {
+		token, err := d.decoder.Token()
+		if err == io.EOF && d.started {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return utils.NewError(d.Next, "unable to decode multistatus", d, err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if !d.started {
+				if t.Name != multistatusName {
+					msg := fmt.Sprintf("unexpected element %s %s", t.Name.Space, t.Name.Local)
+					return utils.NewError(d.Next, msg, d, nil)
+				}
+				d.started = true
+			} else if t.Name == responseName {
+				if err := d.decoder.DecodeElement(into, &t); err != nil {
+					return utils.NewError(d.Next, "unable to decode response", d, err)
+				}
+				return nil
+			} else if t.Name == responseDescriptionName {
+				if err := d.decoder.DecodeElement(&d.ResponseDescription, &t); err != nil {
+					return utils.NewError(d.Next, "unable to decode response description", d, err)
+				}
+			} else if err := d.decoder.Skip(); err != nil {
+				return utils.NewError(d.Next, "unable to decode multistatus", d, err)
+			}
+		case xml.EndElement:
+			d.done = true
+			return io.EOF
+		}
+	}
+
+}
+
+var (
+	multistatusName         = xml.Name{Space: "DAV:", Local: "multistatus"}
+	responseName            = xml.Name{Space: "DAV:", Local: "response"}
+	responseDescriptionName = xml.Name{Space: "DAV:", Local: "responsedescription"}
+)
@@ -0,0 +1,64 @@
+package webdav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/dolanor/caldav-go/webdav/entities"
+	"net/http"
+	"strings"
+)
+
+// the outcome of setting or removing a single property
+type PropResult struct {
+	Name        xml.Name
+	StatusCode  int
+	Description string
+	// the conditions the property failed, if the server named any
+	Error *entities.Error
+}
+
+// checks if the property was updated
+func (r *PropResult) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// checks if the property was not updated only because another property in the same request failed
+func (r *PropResult) FailedDependency() bool {
+	return r.StatusCode == http.StatusFailedDependency
+}
+
+func (r *PropResult) String() string {
+	msg := fmt.Sprintf("%s %d %s", r.Name.Local, r.StatusCode, http.StatusText(r.StatusCode))
+	if r.Description != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, r.Description)
+	}
+	return msg
+}
+
+// an error reporting the properties that a server refused to update.
+// property updates are atomic, so when any property fails none of them are applied.
+type ProppatchError struct {
+	Href    string
+	Results []*PropResult
+}
+
+// returns the properties that caused the update to fail, leaving out failed dependencies
+func (e *ProppatchError) Rejected() []*PropResult {
+	var rejected []*PropResult
+	for _, r := range e.Results {
+		if !r.OK() && !r.FailedDependency() {
+			rejected = append(rejected, r)
+		}
+	}
+	return rejected
+}
+
+func (e *ProppatchError) Error() string {
+	var failed []string
+	for _, r := range e.Results {
+		if !r.OK() {
+			failed = append(failed, r.String())
+		}
+	}
+	return fmt.Sprintf("properties of %s were not updated: %s", e.Href, strings.Join(failed, ", "))
+}
@@ -2,6 +2,7 @@ package webdav
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"github.com/dolanor/caldav-go/http"
 	"github.com/dolanor/caldav-go/utils"
@@ -23,10 +24,15 @@ func (r *Request) Http() *http.Request {
 
 // creates a new WebDAV request object
 func NewRequest(method string, urlstr string, xmldata ...interface{}) (*Request, error) {
-	if buffer, length, err := xmlToReadCloser(xmldata); err != nil {
-		return nil, utils.NewError(NewRequest, "unable to encode xml data", xmldata, err)
-	} else if r, err := http.NewRequest(method, urlstr, buffer); err != nil {
-		return nil, utils.NewError(NewRequest, "unable to create request", urlstr, err)
+	return NewRequestWithContext(context.Background(), method, urlstr, xmldata...)
+}
+
+// creates a new WebDAV request object, bound to a context that may cancel it
+func NewRequestWithContext(ctx context.Context, method string, urlstr string, xmldata ...interface{}) (*Request, error) {
+	if buffer, length, err := xmlToReadCloser(xmldata...); err != nil {
+		return nil, utils.NewError(NewRequestWithContext, "unable to encode xml data", xmldata, err)
+	} else if r, err := http.NewRequestWithContext(ctx, method, urlstr, buffer); err != nil {
+		return nil, utils.NewError(NewRequestWithContext, "unable to create request", urlstr, err)
 	} else {
 		if buffer != nil {
 			// set the content type to XML if we have a body
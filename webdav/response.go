@@ -48,6 +48,11 @@ func (r *Response) Decode(into interface{}) error {
 	}
 }
 
+// returns a decoder that reads the responses of a multistatus body one at a time
+func (r *Response) Multistatus() *MultistatusDecoder {
+	return NewMultistatusDecoder(r.Body)
+}
+
 // creates a new WebDAV response object
 func NewResponse(response *http.Response) *Response {
 	return (*Response)(response)
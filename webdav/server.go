@@ -1,6 +1,7 @@
 package webdav
 
 import (
+	"context"
 	"github.com/dolanor/caldav-go/http"
 	"github.com/dolanor/caldav-go/utils"
 )
@@ -26,3 +27,8 @@ func (s *Server) Http() *http.Server {
 func (s *Server) NewRequest(method string, path string, xmldata ...interface{}) (*Request, error) {
 	return NewRequest(method, s.Http().AbsUrlStr(path), xmldata...)
 }
+
+// creates a new WebDAV request object, bound to a context that may cancel it
+func (s *Server) NewRequestWithContext(ctx context.Context, method string, path string, xmldata ...interface{}) (*Request, error) {
+	return NewRequestWithContext(ctx, method, s.Http().AbsUrlStr(path), xmldata...)
+}